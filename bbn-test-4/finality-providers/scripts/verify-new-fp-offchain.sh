@@ -46,7 +46,8 @@ for filePathRegistryFP in ${FP_CHANGED_FILES}; do
 
   commission=$(cat "$filePathRegistryFP" | jq -r '.commission')
   echo "fp commission:" $commission
-  if ! [[ "$commission" =~ ^0\.[0-9]+$ ]]; then
+  # same as sdk.Dec, at most 18 decimal places within 0 and 1
+  if ! [[ "$commission" =~ ^(0(\.[0-9]{1,18})?|1(\.0{1,18})?)$ ]]; then
     echo $commission "is not valid commision decimal, use 0.1 for 10%"
     exit 1
   fi
@@ -67,8 +68,18 @@ for filePathRegistryFP in ${FP_CHANGED_FILES}; do
   signature=$(cat "$signatureFilePath" | xargs)
   echo "fp signature:" $signature
 
-  echo "eotsd verify signature"
-  $EOTSD_BIN verify-schnorr-sig "$filePathRegistryFP" --btc-pk $btcPk --signature $signature
+  case "$signature" in
+    canonical-v1:*|bip322-simple:*)
+      # these schemes sign the canonical entry for the network, which only
+      # fpregistry can rebuild
+      echo "fpregistry verify signature"
+      go run ./parameters/cmd/fpregistry validate "$filePathRegistryFP"
+      ;;
+    *)
+      echo "eotsd verify signature"
+      $EOTSD_BIN verify-schnorr-sig "$filePathRegistryFP" --btc-pk $btcPk --signature $signature
+      ;;
+  esac
   echo
 
   signedTx=$(cat "$filePathRegistryFP" | jq -r '.deposit.signed_tx')
@@ -0,0 +1,163 @@
+// Package bip322 signs and verifies BIP322 "simple" message signatures: the
+// witness of a virtual transaction spending an output locked to the address
+// of the signer, which wallets produce without exposing the signing key.
+package bip322
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const messageTag = "BIP0322-signed-message"
+
+// maxWitnessItemSize bounds the size of a witness item of a signature, larger
+// than any standard signature, script or control block
+const maxWitnessItemSize = 10000
+
+// MessageHash returns the tagged hash of the message committed by the
+// virtual to_spend transaction
+func MessageHash(message []byte) chainhash.Hash {
+	return *chainhash.TaggedHash([]byte(messageTag), message)
+}
+
+// BuildToSpendTx builds the virtual transaction whose only output, locked to
+// the given script, is spent by the signature
+func BuildToSpendTx(message, pkScript []byte) (*wire.MsgTx, error) {
+	hash := MessageHash(message)
+	sigScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(hash[:]).
+		Script()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx(0)
+	txIn := wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex), sigScript, nil)
+	txIn.Sequence = 0
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, pkScript))
+	return tx, nil
+}
+
+// BuildToSignTx builds the unsigned virtual transaction spending the output
+// of toSpend, to which the signature is the witness
+func BuildToSignTx(toSpend *wire.MsgTx) *wire.MsgTx {
+	toSpendHash := toSpend.TxHash()
+	tx := wire.NewMsgTx(0)
+	txIn := wire.NewTxIn(wire.NewOutPoint(&toSpendHash, 0), nil, nil)
+	txIn.Sequence = 0
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+	return tx
+}
+
+// ParseSimpleSignature decodes a base64 encoded simple signature, i.e. a
+// consensus encoded witness stack
+func ParseSimpleSignature(sig string) (wire.TxWitness, error) {
+	data, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 signature: %w", err)
+	}
+
+	r := bytes.NewReader(data)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid witness: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("invalid witness: no items")
+	}
+	if count > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid witness: too many items")
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, maxWitnessItemSize, "witness item")
+		if err != nil {
+			return nil, fmt.Errorf("invalid witness: %w", err)
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("invalid witness: %d trailing bytes", r.Len())
+	}
+
+	return witness, nil
+}
+
+// FormatSimpleSignature encodes the witness as a base64 simple signature
+func FormatSimpleSignature(witness wire.TxWitness) string {
+	// writing to a buffer does not fail
+	var buf bytes.Buffer
+	_ = wire.WriteVarInt(&buf, 0, uint64(len(witness)))
+	for _, item := range witness {
+		_ = wire.WriteVarBytes(&buf, 0, item)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// VerifySimple checks that the witness is a valid simple signature of the
+// message by the owner of the output script, by executing the script of the
+// virtual to_spend output against the to_sign transaction
+func VerifySimple(message, pkScript []byte, witness wire.TxWitness) error {
+	toSpend, err := BuildToSpendTx(message, pkScript)
+	if err != nil {
+		return err
+	}
+	toSign := BuildToSignTx(toSpend)
+	toSign.TxIn[0].Witness = witness
+
+	prevOut := toSpend.TxOut[0]
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+
+	vm, err := txscript.NewEngine(prevOut.PkScript, toSign, 0, txscript.StandardVerifyFlags,
+		nil, sigHashes, prevOut.Value, fetcher)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := vm.Execute(); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
+}
+
+// TaprootPkScript returns the script of the BIP86 taproot output of the key,
+// without script path, as derived by wallets from their key
+func TaprootPkScript(internalKey *btcec.PublicKey) ([]byte, error) {
+	return txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(internalKey))
+}
+
+// SignSimpleTaproot signs the message with the key of a BIP86 taproot output
+// through its key path and returns the witness of the simple signature
+func SignSimpleTaproot(message []byte, privKey *btcec.PrivateKey) (wire.TxWitness, error) {
+	pkScript, err := TaprootPkScript(privKey.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	toSpend, err := BuildToSpendTx(message, pkScript)
+	if err != nil {
+		return nil, err
+	}
+	toSign := BuildToSignTx(toSpend)
+
+	prevOut := toSpend.TxOut[0]
+	fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+	sigHashes := txscript.NewTxSigHashes(toSign, fetcher)
+
+	sig, err := txscript.RawTxInTaprootSignature(toSign, sigHashes, 0, prevOut.Value, prevOut.PkScript,
+		nil, txscript.SigHashDefault, privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return wire.TxWitness{sig}, nil
+}
@@ -0,0 +1,152 @@
+package bip322_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/bip322"
+)
+
+// test vectors of BIP322
+const (
+	vectorWif            = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+	vectorSegwitAddress  = "bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l"
+	vectorTaprootAddress = "bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3"
+)
+
+func TestMessageHash(t *testing.T) {
+	for msg, hash := range map[string]string{
+		"":            "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1",
+		"Hello World": "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a",
+	} {
+		h := bip322.MessageHash([]byte(msg))
+		assert.Equal(t, hash, hex.EncodeToString(h[:]), msg)
+	}
+}
+
+func TestVirtualTxs(t *testing.T) {
+	pkScript := addressScript(t, vectorSegwitAddress)
+	for msg, hashes := range map[string][2]string{
+		"": {
+			"c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7",
+			"1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6",
+		},
+		"Hello World": {
+			"b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b",
+			"88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf",
+		},
+	} {
+		toSpend, err := bip322.BuildToSpendTx([]byte(msg), pkScript)
+		require.NoError(t, err)
+		assert.Equal(t, hashes[0], toSpend.TxHash().String(), msg)
+		assert.Equal(t, hashes[1], bip322.BuildToSignTx(toSpend).TxHash().String(), msg)
+	}
+}
+
+func TestVerifySimpleVectors(t *testing.T) {
+	segwitScript := addressScript(t, vectorSegwitAddress)
+	taprootScript := addressScript(t, vectorTaprootAddress)
+
+	tests := []struct {
+		name     string
+		pkScript []byte
+		msg      string
+		sig      string
+	}{
+		{
+			"p2wpkh empty message",
+			segwitScript,
+			"",
+			"AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		},
+		{
+			"p2wpkh",
+			segwitScript,
+			"Hello World",
+			"AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+		},
+		{
+			"p2tr",
+			taprootScript,
+			"Hello World",
+			"AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==",
+		},
+	}
+
+	for _, tc := range tests {
+		witness, err := bip322.ParseSimpleSignature(tc.sig)
+		require.NoError(t, err, tc.name)
+		require.NoError(t, bip322.VerifySimple([]byte(tc.msg), tc.pkScript, witness), tc.name)
+
+		assert.Equal(t, tc.sig, bip322.FormatSimpleSignature(witness), tc.name)
+
+		// another message or address does not verify
+		assert.Error(t, bip322.VerifySimple([]byte(tc.msg+"!"), tc.pkScript, witness), tc.name)
+		otherScript := segwitScript
+		if string(tc.pkScript) == string(segwitScript) {
+			otherScript = taprootScript
+		}
+		assert.Error(t, bip322.VerifySimple([]byte(tc.msg), otherScript, witness), tc.name)
+	}
+}
+
+func TestTaprootPkScript(t *testing.T) {
+	wif, err := btcutil.DecodeWIF(vectorWif)
+	require.NoError(t, err)
+	pkScript, err := bip322.TaprootPkScript(wif.PrivKey.PubKey())
+	require.NoError(t, err)
+	assert.Equal(t, addressScript(t, vectorTaprootAddress), pkScript)
+}
+
+func TestSignSimpleTaproot(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	pkScript, err := bip322.TaprootPkScript(privKey.PubKey())
+	require.NoError(t, err)
+
+	msg := []byte("babylon")
+	witness, err := bip322.SignSimpleTaproot(msg, privKey)
+	require.NoError(t, err)
+	require.NoError(t, bip322.VerifySimple(msg, pkScript, witness))
+
+	other, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	witness, err = bip322.SignSimpleTaproot(msg, other)
+	require.NoError(t, err)
+	assert.Error(t, bip322.VerifySimple(msg, pkScript, witness))
+}
+
+func TestParseSimpleSignature(t *testing.T) {
+	_, err := bip322.ParseSimpleSignature("not base64!")
+	assert.Contains(t, err.Error(), "invalid base64 signature")
+
+	// no witness items
+	_, err = bip322.ParseSimpleSignature("AA==")
+	assert.Equal(t, "invalid witness: no items", err.Error())
+
+	// one item of 2 bytes with only 1 byte
+	_, err = bip322.ParseSimpleSignature("AQIB")
+	assert.Contains(t, err.Error(), "invalid witness")
+
+	// trailing bytes after an item of 1 byte
+	_, err = bip322.ParseSimpleSignature("AQEBAQ==")
+	assert.Equal(t, "invalid witness: 1 trailing bytes", err.Error())
+
+	assert.Equal(t, "AQEB", bip322.FormatSimpleSignature(wire.TxWitness{{0x01}}))
+}
+
+func addressScript(t *testing.T, address string) []byte {
+	addr, err := btcutil.DecodeAddress(address, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+	return pkScript
+}
@@ -0,0 +1,41 @@
+// Package btcbackend abstracts the Bitcoin node or indexer used to look up
+// transactions, so that on-chain verifications do not depend on a single
+// block explorer and can be tested without network access.
+package btcbackend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var ErrTxNotFound = errors.New("transaction not found")
+
+// TxInfo is a transaction as seen by the backend. BlockHash and BlockHeight
+// are only set for confirmed transactions.
+type TxInfo struct {
+	Tx          *wire.MsgTx
+	Confirmed   bool
+	BlockHash   *chainhash.Hash
+	BlockHeight uint64
+}
+
+// Confirmations returns the number of confirmations of the transaction given
+// the height of the tip of the chain. The block including the transaction
+// counts as the first confirmation.
+func (i *TxInfo) Confirmations(tipHeight uint64) uint64 {
+	if !i.Confirmed || tipHeight < i.BlockHeight {
+		return 0
+	}
+	return tipHeight - i.BlockHeight + 1
+}
+
+type ChainBackend interface {
+	// GetTransaction returns the transaction with the given hash, or
+	// ErrTxNotFound if the backend does not know it
+	GetTransaction(ctx context.Context, txHash *chainhash.Hash) (*TxInfo, error)
+	// GetTipHeight returns the height of the best block
+	GetTipHeight(ctx context.Context) (uint64, error)
+}
@@ -0,0 +1,141 @@
+package btcbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// BitcoindBackend queries a bitcoind node through its JSON-RPC interface. The
+// node should run with -txindex to look up transactions which are not in
+// its wallet nor mempool.
+type BitcoindBackend struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+	nextID   atomic.Uint64
+}
+
+var _ ChainBackend = (*BitcoindBackend)(nil)
+
+func NewBitcoindBackend(url, user, password string, client *http.Client) *BitcoindBackend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &BitcoindBackend{
+		url:      url,
+		user:     user,
+		password: password,
+		client:   client,
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage   `json:"result"`
+	Error  *btcjson.RPCError `json:"error"`
+}
+
+func (b *BitcoindBackend) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	reqBody, err := json.Marshal(rpcRequest{
+		JSONRPC: "1.0",
+		ID:      b.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.user != "" || b.password != "" {
+		req.SetBasicAuth(b.user, b.password)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	// bitcoind answers RPC errors with a non 200 status and an error body
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(body, &rpcResp); err != nil {
+		return fmt.Errorf("bitcoind %s returned %s: %s", method, resp.Status, bytes.TrimSpace(body))
+	}
+	if rpcResp.Error != nil {
+		if rpcResp.Error.Code == btcjson.ErrRPCNoTxInfo {
+			return ErrTxNotFound
+		}
+		return fmt.Errorf("bitcoind %s: %w", method, rpcResp.Error)
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+func (b *BitcoindBackend) GetTransaction(ctx context.Context, txHash *chainhash.Hash) (*TxInfo, error) {
+	var rawTx btcjson.TxRawResult
+	if err := b.call(ctx, "getrawtransaction", &rawTx, txHash.String(), true); err != nil {
+		return nil, err
+	}
+
+	tx, err := decodeTxHex(rawTx.Hex)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &TxInfo{Tx: tx}
+	if rawTx.BlockHash == "" || rawTx.Confirmations == 0 {
+		return info, nil
+	}
+
+	blockHash, err := chainhash.NewHashFromStr(rawTx.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitcoind block hash: %w", err)
+	}
+
+	var header btcjson.GetBlockHeaderVerboseResult
+	if err := b.call(ctx, "getblockheader", &header, rawTx.BlockHash, true); err != nil {
+		return nil, err
+	}
+	// a block out of the best chain is reported with negative confirmations
+	if header.Confirmations < 0 {
+		return info, nil
+	}
+
+	info.Confirmed = true
+	info.BlockHash = blockHash
+	info.BlockHeight = uint64(header.Height)
+	return info, nil
+}
+
+func (b *BitcoindBackend) GetTipHeight(ctx context.Context) (uint64, error) {
+	var height uint64
+	if err := b.call(ctx, "getblockcount", &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
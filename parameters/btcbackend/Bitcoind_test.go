@@ -0,0 +1,81 @@
+package btcbackend_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+)
+
+type testRPCRequest struct {
+	ID     uint64        `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+func TestBitcoindBackend(t *testing.T) {
+	tx := newTestTx()
+	txHash := tx.TxHash()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req testRPCRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result string
+		switch req.Method {
+		case "getrawtransaction":
+			if req.Params[0] != txHash.String() {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"result":null,"error":{"code":-5,"message":"No such mempool or blockchain transaction"},"id":1}`))
+				return
+			}
+			require.Equal(t, true, req.Params[1])
+			result = `{"hex":"` + txHex(t, tx) + `","txid":"` + txHash.String() + `","blockhash":"` + testBlockHash + `","confirmations":3}`
+		case "getblockheader":
+			require.Equal(t, testBlockHash, req.Params[0])
+			result = `{"hash":"` + testBlockHash + `","confirmations":3,"height":200000}`
+		case "getblockcount":
+			result = "200002"
+		default:
+			t.Fatalf("unexpected method %s", req.Method)
+		}
+		_, _ = w.Write([]byte(`{"result":` + result + `,"error":null,"id":1}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	backend := btcbackend.NewBitcoindBackend(server.URL, "user", "pass", server.Client())
+
+	info, err := backend.GetTransaction(ctx, &txHash)
+	require.NoError(t, err)
+	assert.Equal(t, txHash, info.Tx.TxHash())
+	assert.True(t, info.Confirmed)
+	assert.Equal(t, uint64(200000), info.BlockHeight)
+	assert.Equal(t, testBlockHash, info.BlockHash.String())
+
+	tip, err := backend.GetTipHeight(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200002), tip)
+	assert.Equal(t, uint64(3), info.Confirmations(tip))
+
+	unknown := chainhash.HashH([]byte("unknown"))
+	_, err = backend.GetTransaction(ctx, &unknown)
+	assert.ErrorIs(t, err, btcbackend.ErrTxNotFound)
+
+	unauthorized := btcbackend.NewBitcoindBackend(server.URL, "user", "wrong", server.Client())
+	_, err = unauthorized.GetTipHeight(ctx)
+	assert.Equal(t, "bitcoind getblockcount returned 401 Unauthorized: ", err.Error())
+}
@@ -0,0 +1,132 @@
+package btcbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// maximum size of a response read from the backend
+const maxResponseSize = 4 * 1024 * 1024
+
+// EsploraBackend queries an Esplora REST API, e.g. https://mempool.space/signet/api
+type EsploraBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+var _ ChainBackend = (*EsploraBackend)(nil)
+
+func NewEsploraBackend(baseURL string, client *http.Client) *EsploraBackend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &EsploraBackend{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+type esploraTxStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+}
+
+func (e *EsploraBackend) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrTxNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("esplora GET %s returned %s: %s", path, resp.Status, bytes.TrimSpace(body))
+	}
+
+	return bytes.TrimSpace(body), nil
+}
+
+func (e *EsploraBackend) GetTransaction(ctx context.Context, txHash *chainhash.Hash) (*TxInfo, error) {
+	txHex, err := e.get(ctx, "/tx/"+txHash.String()+"/hex")
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := decodeTxHex(string(txHex))
+	if err != nil {
+		return nil, err
+	}
+
+	statusData, err := e.get(ctx, "/tx/"+txHash.String()+"/status")
+	if err != nil {
+		return nil, err
+	}
+
+	var status esploraTxStatus
+	if err := json.Unmarshal(statusData, &status); err != nil {
+		return nil, fmt.Errorf("invalid esplora tx status: %w", err)
+	}
+
+	info := &TxInfo{Tx: tx, Confirmed: status.Confirmed}
+	if status.Confirmed {
+		blockHash, err := chainhash.NewHashFromStr(status.BlockHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid esplora block hash: %w", err)
+		}
+		info.BlockHash = blockHash
+		info.BlockHeight = status.BlockHeight
+	}
+
+	return info, nil
+}
+
+func (e *EsploraBackend) GetTipHeight(ctx context.Context) (uint64, error) {
+	data, err := e.get(ctx, "/blocks/tip/height")
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid esplora tip height: %w", err)
+	}
+
+	return height, nil
+}
+
+func decodeTxHex(txHex string) (*wire.MsgTx, error) {
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hex: %w", err)
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	return &tx, nil
+}
@@ -0,0 +1,96 @@
+package btcbackend_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+)
+
+const testBlockHash = "000000b0e0e0a6cdad5e2a5d2b41f96cd4e1a0c5a33f5d1bc2a2b47d7f1dbe4f"
+
+func newTestTx() *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	prevHash := chainhash.HashH([]byte("prev"))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil, wire.TxWitness{[]byte{0x01, 0x02}}))
+	tx.AddTxOut(wire.NewTxOut(10000000, []byte{0x51, 0x20}))
+	return tx
+}
+
+func txHex(t *testing.T, tx *wire.MsgTx) string {
+	var buf bytes.Buffer
+	require.NoError(t, tx.Serialize(&buf))
+	return hex.EncodeToString(buf.Bytes())
+}
+
+func TestEsploraBackend(t *testing.T) {
+	tx := newTestTx()
+	txHash := tx.TxHash()
+	unconfirmed := newTestTx()
+	unconfirmed.LockTime = 1
+	unconfirmedHash := unconfirmed.TxHash()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tx/"+txHash.String()+"/hex", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(txHex(t, tx)))
+	})
+	mux.HandleFunc("/api/tx/"+txHash.String()+"/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"confirmed":true,"block_height":200000,"block_hash":"` + testBlockHash + `","block_time":1718000000}`))
+	})
+	mux.HandleFunc("/api/tx/"+unconfirmedHash.String()+"/hex", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(txHex(t, unconfirmed)))
+	})
+	mux.HandleFunc("/api/tx/"+unconfirmedHash.String()+"/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"confirmed":false}`))
+	})
+	mux.HandleFunc("/api/blocks/tip/height", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("200009\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	backend := btcbackend.NewEsploraBackend(server.URL+"/api/", server.Client())
+
+	info, err := backend.GetTransaction(ctx, &txHash)
+	require.NoError(t, err)
+	assert.Equal(t, txHash, info.Tx.TxHash())
+	assert.True(t, info.Confirmed)
+	assert.Equal(t, uint64(200000), info.BlockHeight)
+	assert.Equal(t, testBlockHash, info.BlockHash.String())
+
+	tip, err := backend.GetTipHeight(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200009), tip)
+	assert.Equal(t, uint64(10), info.Confirmations(tip))
+
+	info, err = backend.GetTransaction(ctx, &unconfirmedHash)
+	require.NoError(t, err)
+	assert.False(t, info.Confirmed)
+	assert.Nil(t, info.BlockHash)
+	assert.Equal(t, uint64(0), info.Confirmations(tip))
+
+	unknown := chainhash.HashH([]byte("unknown"))
+	_, err = backend.GetTransaction(ctx, &unknown)
+	assert.ErrorIs(t, err, btcbackend.ErrTxNotFound)
+}
+
+func TestFailEsploraBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	backend := btcbackend.NewEsploraBackend(server.URL, server.Client())
+	_, err := backend.GetTipHeight(context.Background())
+	assert.Equal(t, "esplora GET /blocks/tip/height returned 429 Too Many Requests: rate limited", err.Error())
+}
@@ -0,0 +1,68 @@
+package btcbackend
+
+import (
+	"context"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// FakeBackend is an in-memory backend for tests
+type FakeBackend struct {
+	mu        sync.Mutex
+	txs       map[chainhash.Hash]*TxInfo
+	tipHeight uint64
+}
+
+var _ ChainBackend = (*FakeBackend)(nil)
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		txs: make(map[chainhash.Hash]*TxInfo),
+	}
+}
+
+// AddTransaction stores a transaction confirmed at the given height, or
+// in the mempool if the height is 0
+func (f *FakeBackend) AddTransaction(tx *wire.MsgTx, height uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info := &TxInfo{Tx: tx}
+	if height > 0 {
+		// the fake has no blocks, derive a stable block hash from the height
+		blockHash := chainhash.DoubleHashH([]byte{byte(height), byte(height >> 8), byte(height >> 16), byte(height >> 24)})
+		info.Confirmed = true
+		info.BlockHash = &blockHash
+		info.BlockHeight = height
+		if height > f.tipHeight {
+			f.tipHeight = height
+		}
+	}
+	f.txs[tx.TxHash()] = info
+}
+
+func (f *FakeBackend) SetTipHeight(height uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tipHeight = height
+}
+
+func (f *FakeBackend) GetTransaction(_ context.Context, txHash *chainhash.Hash) (*TxInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, ok := f.txs[*txHash]
+	if !ok {
+		return nil, ErrTxNotFound
+	}
+	infoCopy := *info
+	return &infoCopy, nil
+}
+
+func (f *FakeBackend) GetTipHeight(_ context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tipHeight, nil
+}
@@ -0,0 +1,275 @@
+package btcstaking
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// StakingTxPolicy is what a staking transaction is inspected against, either
+// the deposit policy of a registry or a version of the global params
+type StakingTxPolicy struct {
+	Name           string
+	Tag            []byte
+	CovenantKeys   []*btcec.PublicKey
+	CovenantQuorum uint32
+	MinStakingTime uint16
+	MaxStakingTime uint16
+	// MinStakingAmount and MaxStakingAmount are not checked if zero
+	MinStakingAmount btcutil.Amount
+	MaxStakingAmount btcutil.Amount
+	// FpKey is the expected finality provider key, if known
+	FpKey *btcec.PublicKey
+}
+
+// Check is a field of a staking transaction checked against a policy
+type Check struct {
+	Field    string
+	Value    string
+	Expected string
+	Pass     bool
+}
+
+// InspectedInput is an input of an inspected transaction
+type InspectedInput struct {
+	PreviousOutPoint wire.OutPoint
+	Sequence         uint32
+	WitnessItems     int
+}
+
+// InspectedOutput is an output of an inspected transaction
+type InspectedOutput struct {
+	Index    int
+	Value    btcutil.Amount
+	PkScript []byte
+	Class    txscript.ScriptClass
+	// Address is empty for scripts without address, e.g. OP_RETURN
+	Address    string
+	IsStaking  bool
+	IsOpReturn bool
+}
+
+// Inspection is the decoded form of a staking transaction with the checks of
+// its fields against a policy
+type Inspection struct {
+	TxHash   string
+	Version  int32
+	LockTime uint32
+	Inputs   []*InspectedInput
+	Outputs  []*InspectedOutput
+	// OpReturnData is nil if no output is a version 0 staking OP_RETURN
+	OpReturnData *V0OpReturnData
+	Checks       []*Check
+}
+
+// Pass returns true if every check passed
+func (i *Inspection) Pass() bool {
+	for _, c := range i.Checks {
+		if !c.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// TagString returns the tag as ASCII if it is printable, as hex otherwise
+func TagString(tag []byte) string {
+	for _, b := range tag {
+		if b < 0x20 || b > 0x7e {
+			return hex.EncodeToString(tag)
+		}
+	}
+	return fmt.Sprintf("%s (%x)", tag, tag)
+}
+
+func (i *Inspection) check(field, value, expected string, pass bool) {
+	i.Checks = append(i.Checks, &Check{Field: field, Value: value, Expected: expected, Pass: pass})
+}
+
+// findOpReturn returns the index and data of the staking OP_RETURN output,
+// preferring one with the tag of the policy, the number of outputs with the
+// tag, and the error of the first OP_RETURN output which could not be parsed
+func findOpReturn(tx *wire.MsgTx, tag []byte) (int, *V0OpReturnData, int, error) {
+	idx := -1
+	var data *V0OpReturnData
+	var parseErr error
+	tagged := 0
+	for i, out := range tx.TxOut {
+		d, err := ParseV0OpReturnScript(out.PkScript)
+		if err != nil {
+			if parseErr == nil && len(out.PkScript) > 0 && out.PkScript[0] == txscript.OP_RETURN {
+				parseErr = fmt.Errorf("output %d: %w", i, err)
+			}
+			continue
+		}
+		if bytes.Equal(d.Tag, tag) {
+			tagged++
+			if tagged == 1 {
+				idx, data = i, d
+			}
+			continue
+		}
+		if data == nil {
+			idx, data = i, d
+		}
+	}
+	return idx, data, tagged, parseErr
+}
+
+// taggedOpReturnVersion returns the version byte of the first OP_RETURN
+// output pushing data which starts with the tag
+func taggedOpReturnVersion(tx *wire.MsgTx, tag []byte) (byte, bool) {
+	for _, out := range tx.TxOut {
+		if len(out.PkScript) == 0 || out.PkScript[0] != txscript.OP_RETURN {
+			continue
+		}
+		pushes, err := txscript.PushedData(out.PkScript[1:])
+		if err != nil || len(pushes) != 1 || len(pushes[0]) <= TagLen {
+			continue
+		}
+		if bytes.Equal(pushes[0][:TagLen], tag) {
+			return pushes[0][TagLen], true
+		}
+	}
+	return 0, false
+}
+
+// InspectStakingTx decodes the transaction, finds its staking OP_RETURN and
+// staking outputs, and checks each field against the policy. It does not fail
+// on invalid transactions, failed checks are reported instead.
+func InspectStakingTx(tx *wire.MsgTx, policy *StakingTxPolicy, net *chaincfg.Params) *Inspection {
+	inspection := &Inspection{
+		TxHash:   tx.TxHash().String(),
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+	}
+	for _, in := range tx.TxIn {
+		inspection.Inputs = append(inspection.Inputs, &InspectedInput{
+			PreviousOutPoint: in.PreviousOutPoint,
+			Sequence:         in.Sequence,
+			WitnessItems:     len(in.Witness),
+		})
+	}
+	for i, out := range tx.TxOut {
+		class, addrs, _, _ := txscript.ExtractPkScriptAddrs(out.PkScript, net)
+		inspected := &InspectedOutput{
+			Index:      i,
+			Value:      btcutil.Amount(out.Value),
+			PkScript:   out.PkScript,
+			Class:      class,
+			IsOpReturn: class == txscript.NullDataTy,
+		}
+		if len(addrs) == 1 {
+			inspected.Address = addrs[0].EncodeAddress()
+		}
+		inspection.Outputs = append(inspection.Outputs, inspected)
+	}
+
+	opReturnIdx, data, tagged, parseErr := findOpReturn(tx, policy.Tag)
+	inspection.OpReturnData = data
+	opReturnValue := "none"
+	switch {
+	case opReturnIdx >= 0:
+		opReturnValue = "output " + strconv.Itoa(opReturnIdx)
+	case parseErr != nil:
+		opReturnValue = parseErr.Error()
+	}
+	inspection.check("op_return", opReturnValue, "one staking OP_RETURN output", tagged == 1)
+	if data == nil {
+		// only version 0 OP_RETURN outputs are parsed, one of another
+		// version with the tag of the policy is reported as such
+		if version, ok := taggedOpReturnVersion(tx, policy.Tag); ok && version != v0OpReturnVersion {
+			inspection.check("version", strconv.Itoa(int(version)), "0", false)
+		}
+		return inspection
+	}
+
+	inspection.check("tag", TagString(data.Tag), TagString(policy.Tag), bytes.Equal(data.Tag, policy.Tag))
+
+	// the staker key should not be one of the other keys of the scripts. The
+	// keys are compared x-only, as the OP_RETURN keys always have an even y
+	stakerXOnly := schnorr.SerializePubKey(data.StakerKey)
+	stakerPk := hex.EncodeToString(stakerXOnly)
+	stakerPass := !bytes.Equal(stakerXOnly, schnorr.SerializePubKey(data.FpKey))
+	for _, k := range policy.CovenantKeys {
+		if bytes.Equal(stakerXOnly, schnorr.SerializePubKey(k)) {
+			stakerPass = false
+		}
+	}
+	inspection.check("staker_pk", stakerPk, "not the fp_pk or a covenant key", stakerPass)
+
+	fpPk := hex.EncodeToString(schnorr.SerializePubKey(data.FpKey))
+	if policy.FpKey != nil {
+		expected := hex.EncodeToString(schnorr.SerializePubKey(policy.FpKey))
+		inspection.check("fp_pk", fpPk, expected, fpPk == expected)
+	} else {
+		inspection.check("fp_pk", fpPk, "valid key", true)
+	}
+
+	stakingTime := data.StakingTime
+	expectedTime := fmt.Sprintf("%d to %d", policy.MinStakingTime, policy.MaxStakingTime)
+	if policy.MinStakingTime == policy.MaxStakingTime {
+		expectedTime = strconv.Itoa(int(policy.MinStakingTime))
+	}
+	inspection.check("staking_time", strconv.Itoa(int(stakingTime)), expectedTime,
+		stakingTime >= policy.MinStakingTime && stakingTime <= policy.MaxStakingTime)
+
+	// the staking output is rebuilt from the OP_RETURN data and the covenant
+	// committee of the policy
+	expected, err := BuildStakingInfo(data.StakerKey, []*btcec.PublicKey{data.FpKey},
+		policy.CovenantKeys, policy.CovenantQuorum, stakingTime, 0)
+	if err != nil {
+		inspection.check("staking_output", "none", err.Error(), false)
+		return inspection
+	}
+	stakingIdx := -1
+	matches := 0
+	for _, out := range inspection.Outputs {
+		if bytes.Equal(out.PkScript, expected.StakingOutput.PkScript) {
+			out.IsStaking = true
+			matches++
+			if stakingIdx < 0 {
+				stakingIdx = out.Index
+			}
+		}
+	}
+	stakingValue := "none"
+	if stakingIdx >= 0 {
+		stakingValue = "output " + strconv.Itoa(stakingIdx)
+	}
+	inspection.check("staking_output", stakingValue,
+		"one taproot output of the staking scripts with the covenant committee", matches == 1)
+	if stakingIdx < 0 {
+		return inspection
+	}
+
+	amount := inspection.Outputs[stakingIdx].Value
+	pass := (policy.MinStakingAmount == 0 || amount >= policy.MinStakingAmount) &&
+		(policy.MaxStakingAmount == 0 || amount <= policy.MaxStakingAmount)
+	inspection.check("staking_amount", amount.String(), amountRange(policy.MinStakingAmount, policy.MaxStakingAmount), pass)
+
+	return inspection
+}
+
+func amountRange(min, max btcutil.Amount) string {
+	switch {
+	case min == 0 && max == 0:
+		return "any"
+	case max == 0:
+		return "at least " + min.String()
+	case min == 0:
+		return "at most " + max.String()
+	case min == max:
+		return min.String()
+	default:
+		return min.String() + " to " + max.String()
+	}
+}
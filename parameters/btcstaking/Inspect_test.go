@@ -0,0 +1,198 @@
+package btcstaking_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+func (s *testStaking) policy() *btcstaking.StakingTxPolicy {
+	return &btcstaking.StakingTxPolicy{
+		Name:             "test policy",
+		Tag:              testTag,
+		CovenantKeys:     s.covenantKeys,
+		CovenantQuorum:   s.covenantQuorum,
+		MinStakingTime:   s.stakingTime,
+		MaxStakingTime:   s.stakingTime,
+		MinStakingAmount: s.amount,
+		FpKey:            s.fpKey,
+	}
+}
+
+func failedChecks(i *btcstaking.Inspection) []string {
+	var failed []string
+	for _, c := range i.Checks {
+		if !c.Pass {
+			failed = append(failed, c.Field)
+		}
+	}
+	return failed
+}
+
+func TestInspectStakingTx(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := genTestStaking(t, r)
+
+	inspection := btcstaking.InspectStakingTx(s.stakingTx(t), s.policy(), &chaincfg.SigNetParams)
+	require.True(t, inspection.Pass())
+	require.Len(t, inspection.Inputs, 1)
+	require.Len(t, inspection.Outputs, 3)
+	require.True(t, inspection.Outputs[1].IsStaking)
+	require.NotEmpty(t, inspection.Outputs[1].Address)
+	require.True(t, inspection.Outputs[2].IsOpReturn)
+	require.Equal(t, s.stakingTime, inspection.OpReturnData.StakingTime)
+
+	var fields []string
+	for _, c := range inspection.Checks {
+		fields = append(fields, c.Field)
+	}
+	require.Equal(t, []string{"op_return", "tag", "staker_pk", "fp_pk",
+		"staking_time", "staking_output", "staking_amount"}, fields)
+}
+
+func TestFailInspectStakingTx(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	s := genTestStaking(t, r)
+	other := genTestStaking(t, r)
+
+	testCases := []struct {
+		name   string
+		tx     func() *wire.MsgTx
+		policy func(p *btcstaking.StakingTxPolicy)
+		failed []string
+	}{
+		{
+			name: "another covenant committee",
+			policy: func(p *btcstaking.StakingTxPolicy) {
+				p.CovenantKeys = other.covenantKeys
+				p.CovenantQuorum = other.covenantQuorum
+			},
+			failed: []string{"staking_output"},
+		},
+		{
+			name:   "another tag",
+			policy: func(p *btcstaking.StakingTxPolicy) { p.Tag = []byte("bbt4") },
+			failed: []string{"op_return", "tag"},
+		},
+		{
+			name:   "another finality provider",
+			policy: func(p *btcstaking.StakingTxPolicy) { p.FpKey = other.fpKey },
+			failed: []string{"fp_pk"},
+		},
+		{
+			name: "staking time out of range",
+			policy: func(p *btcstaking.StakingTxPolicy) {
+				p.MinStakingTime = s.stakingTime + 1
+				p.MaxStakingTime = s.stakingTime + 1
+			},
+			failed: []string{"staking_time"},
+		},
+		{
+			name:   "staking amount out of range",
+			policy: func(p *btcstaking.StakingTxPolicy) { p.MaxStakingAmount = s.amount - 1; p.MinStakingAmount = 0 },
+			failed: []string{"staking_amount"},
+		},
+		{
+			name: "two staking outputs",
+			tx: func() *wire.MsgTx {
+				tx := s.stakingTx(t)
+				tx.AddTxOut(tx.TxOut[1])
+				return tx
+			},
+			failed: []string{"staking_output"},
+		},
+		{
+			name: "staker key of the finality provider",
+			tx: func() *wire.MsgTx {
+				same := *s
+				same.stakerKey = s.fpKey
+				return same.stakingTx(t)
+			},
+			failed: []string{"staker_pk"},
+		},
+		{
+			name: "staker key of the covenant committee",
+			tx: func() *wire.MsgTx {
+				same := *s
+				same.stakerKey = s.covenantKeys[0]
+				return same.stakingTx(t)
+			},
+			failed: []string{"staker_pk"},
+		},
+		{
+			name: "staker key of a covenant key with an odd y",
+			tx: func() *wire.MsgTx {
+				return withOddCovenantKey(s).stakingTx(t)
+			},
+			policy: func(p *btcstaking.StakingTxPolicy) {
+				p.CovenantKeys = withOddCovenantKey(s).covenantKeys
+			},
+			failed: []string{"staker_pk"},
+		},
+		{
+			name: "another OP_RETURN version",
+			tx: func() *wire.MsgTx {
+				tx := s.stakingTx(t)
+				script := append([]byte{}, tx.TxOut[2].PkScript...)
+				// OP_RETURN, push length, tag, version
+				script[2+btcstaking.TagLen] = 1
+				tx.TxOut[2].PkScript = script
+				return tx
+			},
+			failed: []string{"op_return", "version"},
+		},
+		{
+			name: "invalid OP_RETURN",
+			tx: func() *wire.MsgTx {
+				tx := s.stakingTx(t)
+				tx.TxOut[2].PkScript = tx.TxOut[2].PkScript[:len(tx.TxOut[2].PkScript)-1]
+				return tx
+			},
+			failed: []string{"op_return"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := s.stakingTx(t)
+			if tc.tx != nil {
+				tx = tc.tx()
+			}
+			policy := s.policy()
+			if tc.policy != nil {
+				tc.policy(policy)
+			}
+
+			inspection := btcstaking.InspectStakingTx(tx, policy, &chaincfg.SigNetParams)
+			require.False(t, inspection.Pass())
+			require.Equal(t, tc.failed, failedChecks(inspection))
+		})
+	}
+}
+
+func TestTagString(t *testing.T) {
+	require.Equal(t, "bbt4 (62627434)", btcstaking.TagString([]byte("bbt4")))
+	require.Equal(t, "01020304", btcstaking.TagString(testTag))
+}
+
+// withOddCovenantKey returns a copy of the staking with a fixed covenant key
+// of odd y as its first covenant key and as its staker key
+func withOddCovenantKey(s *testStaking) *testStaking {
+	privKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x11}, 32))
+	if privKey.PubKey().SerializeCompressed()[0] != 0x03 {
+		privKey.Key.Negate()
+	}
+	key := privKey.PubKey()
+
+	odd := *s
+	odd.covenantKeys = append([]*btcec.PublicKey{key}, s.covenantKeys[1:]...)
+	odd.stakerKey = key
+	return &odd
+}
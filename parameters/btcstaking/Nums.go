@@ -0,0 +1,57 @@
+// Package btcstaking rebuilds and parses the Bitcoin staking transactions
+// used by Babylon, without depending on the Babylon node.
+package btcstaking
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// NumsKeyHex is the x-only encoding of the BIP341 NUMS point H
+const NumsKeyHex = "50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0"
+
+// numsKey is derived once, DeriveNumsKey cannot fail
+var numsKey = mustDeriveNumsKey()
+
+// DeriveNumsKey derives the "nothing up my sleeve" point H of BIP341, i.e.
+// lift_x(sha256(G)) where G is the uncompressed encoding of the secp256k1
+// generator. No one knows the discrete logarithm of H, so nothing can be
+// signed with it.
+func DeriveNumsKey() (*btcec.PublicKey, error) {
+	var gx, gy btcec.FieldVal
+	gx.SetByteSlice(btcec.S256().Gx.Bytes())
+	gy.SetByteSlice(btcec.S256().Gy.Bytes())
+	g := btcec.NewPublicKey(&gx, &gy)
+
+	hash := sha256.Sum256(g.SerializeUncompressed())
+	key, err := schnorr.ParsePubKey(hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("sha256 of the generator is not the x coordinate of a point: %w", err)
+	}
+
+	return key, nil
+}
+
+func mustDeriveNumsKey() *btcec.PublicKey {
+	key, err := DeriveNumsKey()
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// NumsKey returns the BIP341 NUMS point H
+func NumsKey() *btcec.PublicKey {
+	return numsKey
+}
+
+// IsNumsKey returns whether the key is the BIP341 NUMS point. Only the x
+// coordinate is compared, as a key with the same x coordinate and an odd y
+// coordinate has no known discrete logarithm either.
+func IsNumsKey(key *btcec.PublicKey) bool {
+	return bytes.Equal(schnorr.SerializePubKey(key), schnorr.SerializePubKey(numsKey))
+}
@@ -0,0 +1,39 @@
+package btcstaking_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+func TestDeriveNumsKey(t *testing.T) {
+	key, err := btcstaking.DeriveNumsKey()
+	require.NoError(t, err)
+	require.Equal(t, btcstaking.NumsKeyHex, hex.EncodeToString(schnorr.SerializePubKey(key)))
+	require.True(t, btcstaking.IsNumsKey(key))
+	require.True(t, btcstaking.IsNumsKey(btcstaking.NumsKey()))
+}
+
+func TestIsNumsKey(t *testing.T) {
+	// both parities of the NUMS point, as found in covenant sets
+	for _, prefix := range []string{"02", "03"} {
+		keyBytes, err := hex.DecodeString(prefix + btcstaking.NumsKeyHex)
+		require.NoError(t, err)
+		key, err := btcec.ParsePubKey(keyBytes)
+		require.NoError(t, err)
+		require.True(t, btcstaking.IsNumsKey(key))
+	}
+
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	require.False(t, btcstaking.IsNumsKey(privKey.PubKey()))
+
+	// the generator itself has a known discrete logarithm
+	one := new(btcec.ModNScalar).SetInt(1)
+	require.False(t, btcstaking.IsNumsKey(btcec.PrivKeyFromScalar(one).PubKey()))
+}
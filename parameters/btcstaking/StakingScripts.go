@@ -0,0 +1,183 @@
+package btcstaking
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// StakingScripts are the three spending paths of a staking output
+type StakingScripts struct {
+	// TimeLockScript is spendable by the staker alone once the staking time
+	// expired
+	TimeLockScript []byte
+	// UnbondingScript needs the staker and a quorum of the covenant committee
+	UnbondingScript []byte
+	// SlashingScript needs the staker, the finality provider and a quorum of
+	// the covenant committee
+	SlashingScript []byte
+}
+
+// StakingInfo is a staking output together with its taproot script tree
+type StakingInfo struct {
+	StakingOutput *wire.TxOut
+	Scripts       *StakingScripts
+	// InternalKey is the taproot internal key of the output, the NUMS point
+	// so that the output can only be spent through its scripts
+	InternalKey *btcec.PublicKey
+	ScriptTree  *txscript.IndexedTapScriptTree
+}
+
+// sortKeys sorts the keys in descending order of their x-only encoding, in
+// the same way the Babylon staking scripts do
+func sortKeys(keys []*btcec.PublicKey) []*btcec.PublicKey {
+	sorted := make([]*btcec.PublicKey, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bytes.Compare(schnorr.SerializePubKey(sorted[i]), schnorr.SerializePubKey(sorted[j])) == 1
+	})
+	return sorted
+}
+
+func buildSingleKeySigScript(key *btcec.PublicKey, withVerify bool) ([]byte, error) {
+	builder := txscript.NewScriptBuilder()
+	builder.AddData(schnorr.SerializePubKey(key))
+	if withVerify {
+		builder.AddOp(txscript.OP_CHECKSIGVERIFY)
+	} else {
+		builder.AddOp(txscript.OP_CHECKSIG)
+	}
+	return builder.Script()
+}
+
+// buildMultiSigScript builds a threshold of keys script with OP_CHECKSIGADD,
+// or a single key script if there is only one key
+func buildMultiSigScript(keys []*btcec.PublicKey, threshold uint32, withVerify bool) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys for the multisig script")
+	}
+	if threshold == 0 || int(threshold) > len(keys) {
+		return nil, fmt.Errorf("invalid threshold %d for %d keys", threshold, len(keys))
+	}
+	if len(keys) == 1 {
+		return buildSingleKeySigScript(keys[0], withVerify)
+	}
+
+	sorted := sortKeys(keys)
+	for i := 1; i < len(sorted); i++ {
+		if bytes.Equal(schnorr.SerializePubKey(sorted[i-1]), schnorr.SerializePubKey(sorted[i])) {
+			return nil, fmt.Errorf("duplicate key %x in the multisig script", schnorr.SerializePubKey(sorted[i]))
+		}
+	}
+
+	builder := txscript.NewScriptBuilder()
+	builder.AddData(schnorr.SerializePubKey(sorted[0]))
+	builder.AddOp(txscript.OP_CHECKSIG)
+	for _, key := range sorted[1:] {
+		builder.AddData(schnorr.SerializePubKey(key))
+		builder.AddOp(txscript.OP_CHECKSIGADD)
+	}
+	builder.AddInt64(int64(threshold))
+	if withVerify {
+		builder.AddOp(txscript.OP_NUMEQUALVERIFY)
+	} else {
+		builder.AddOp(txscript.OP_NUMEQUAL)
+	}
+	return builder.Script()
+}
+
+func buildTimeLockScript(key *btcec.PublicKey, lockTime uint16) ([]byte, error) {
+	builder := txscript.NewScriptBuilder()
+	builder.AddData(schnorr.SerializePubKey(key))
+	builder.AddOp(txscript.OP_CHECKSIGVERIFY)
+	builder.AddInt64(int64(lockTime))
+	builder.AddOp(txscript.OP_CHECKSEQUENCEVERIFY)
+	return builder.Script()
+}
+
+// BuildStakingScripts builds the spending scripts of a staking output
+func BuildStakingScripts(
+	stakerKey *btcec.PublicKey,
+	fpKeys []*btcec.PublicKey,
+	covenantKeys []*btcec.PublicKey,
+	covenantQuorum uint32,
+	stakingTime uint16,
+) (*StakingScripts, error) {
+	if stakingTime == 0 {
+		return nil, fmt.Errorf("staking time should be positive")
+	}
+
+	timeLockScript, err := buildTimeLockScript(stakerKey, stakingTime)
+	if err != nil {
+		return nil, err
+	}
+
+	stakerSigScript, err := buildSingleKeySigScript(stakerKey, true)
+	if err != nil {
+		return nil, err
+	}
+	covenantScript, err := buildMultiSigScript(covenantKeys, covenantQuorum, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid covenant committee: %w", err)
+	}
+	fpScript, err := buildMultiSigScript(fpKeys, 1, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid finality providers: %w", err)
+	}
+
+	return &StakingScripts{
+		TimeLockScript:  timeLockScript,
+		UnbondingScript: concat(stakerSigScript, covenantScript),
+		SlashingScript:  concat(stakerSigScript, fpScript, covenantScript),
+	}, nil
+}
+
+func concat(scripts ...[]byte) []byte {
+	var out []byte
+	for _, s := range scripts {
+		out = append(out, s...)
+	}
+	return out
+}
+
+// BuildStakingInfo builds the taproot staking output committing to the
+// time lock, unbonding and slashing scripts, in this order
+func BuildStakingInfo(
+	stakerKey *btcec.PublicKey,
+	fpKeys []*btcec.PublicKey,
+	covenantKeys []*btcec.PublicKey,
+	covenantQuorum uint32,
+	stakingTime uint16,
+	stakingAmount btcutil.Amount,
+) (*StakingInfo, error) {
+	scripts, err := BuildStakingScripts(stakerKey, fpKeys, covenantKeys, covenantQuorum, stakingTime)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := txscript.AssembleTaprootScriptTree(
+		txscript.NewBaseTapLeaf(scripts.TimeLockScript),
+		txscript.NewBaseTapLeaf(scripts.UnbondingScript),
+		txscript.NewBaseTapLeaf(scripts.SlashingScript),
+	)
+	rootHash := tree.RootNode.TapHash()
+	outputKey := txscript.ComputeTaprootOutputKey(NumsKey(), rootHash[:])
+
+	pkScript, err := txscript.PayToTaprootScript(outputKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StakingInfo{
+		StakingOutput: wire.NewTxOut(int64(stakingAmount), pkScript),
+		Scripts:       scripts,
+		InternalKey:   NumsKey(),
+		ScriptTree:    tree,
+	}, nil
+}
@@ -0,0 +1,195 @@
+package btcstaking
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	// TagLen is the length in bytes of the tag identifying staking
+	// transactions of a network
+	TagLen = 4
+
+	// V0OpReturnDataSize is the size of the data of a version 0 staking
+	// OP_RETURN output: tag, version, staker key, finality provider key and
+	// staking time
+	V0OpReturnDataSize = TagLen + 1 + schnorr.PubKeyBytesLen*2 + 2
+
+	v0OpReturnVersion = byte(0)
+)
+
+// V0OpReturnData is the data committed by the OP_RETURN output of a version
+// 0 staking transaction
+type V0OpReturnData struct {
+	Tag         []byte
+	Version     byte
+	StakerKey   *btcec.PublicKey
+	FpKey       *btcec.PublicKey
+	StakingTime uint16
+}
+
+// ParseV0OpReturnScript parses the data of a staking OP_RETURN output script
+func ParseV0OpReturnScript(pkScript []byte) (*V0OpReturnData, error) {
+	if len(pkScript) == 0 || pkScript[0] != txscript.OP_RETURN {
+		return nil, fmt.Errorf("script is not an OP_RETURN script")
+	}
+
+	pushes, err := txscript.PushedData(pkScript[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid OP_RETURN script: %w", err)
+	}
+	if len(pushes) != 1 {
+		return nil, fmt.Errorf("OP_RETURN script should push exactly one data, got %d", len(pushes))
+	}
+
+	return ParseV0OpReturnData(pushes[0])
+}
+
+// ParseV0OpReturnData parses the data pushed by a staking OP_RETURN output
+func ParseV0OpReturnData(data []byte) (*V0OpReturnData, error) {
+	if len(data) != V0OpReturnDataSize {
+		return nil, fmt.Errorf("invalid OP_RETURN data size, expected %d, got %d", V0OpReturnDataSize, len(data))
+	}
+
+	tag := data[:TagLen]
+	version := data[TagLen]
+	if version != v0OpReturnVersion {
+		return nil, fmt.Errorf("unsupported OP_RETURN version %d", version)
+	}
+
+	offset := TagLen + 1
+	stakerKey, err := schnorr.ParsePubKey(data[offset : offset+schnorr.PubKeyBytesLen])
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker key: %w", err)
+	}
+	offset += schnorr.PubKeyBytesLen
+
+	fpKey, err := schnorr.ParsePubKey(data[offset : offset+schnorr.PubKeyBytesLen])
+	if err != nil {
+		return nil, fmt.Errorf("invalid finality provider key: %w", err)
+	}
+	offset += schnorr.PubKeyBytesLen
+
+	stakingTime := binary.BigEndian.Uint16(data[offset:])
+	if stakingTime == 0 {
+		return nil, fmt.Errorf("staking time should be positive")
+	}
+
+	return &V0OpReturnData{
+		Tag:         append([]byte{}, tag...),
+		Version:     version,
+		StakerKey:   stakerKey,
+		FpKey:       fpKey,
+		StakingTime: stakingTime,
+	}, nil
+}
+
+// BuildV0OpReturnScript builds the OP_RETURN output script of a version 0
+// staking transaction
+func BuildV0OpReturnScript(tag []byte, stakerKey, fpKey *btcec.PublicKey, stakingTime uint16) ([]byte, error) {
+	if len(tag) != TagLen {
+		return nil, fmt.Errorf("invalid tag length, expected %d, got %d", TagLen, len(tag))
+	}
+
+	data := make([]byte, 0, V0OpReturnDataSize)
+	data = append(data, tag...)
+	data = append(data, v0OpReturnVersion)
+	data = append(data, schnorr.SerializePubKey(stakerKey)...)
+	data = append(data, schnorr.SerializePubKey(fpKey)...)
+	data = binary.BigEndian.AppendUint16(data, stakingTime)
+
+	return txscript.NullDataScript(data)
+}
+
+// ParsedV0StakingTx is a version 0 staking transaction with its staking and
+// OP_RETURN outputs identified
+type ParsedV0StakingTx struct {
+	StakingOutput     *wire.TxOut
+	StakingOutputIdx  int
+	OpReturnOutput    *wire.TxOut
+	OpReturnOutputIdx int
+	OpReturnData      *V0OpReturnData
+	StakingInfo       *StakingInfo
+}
+
+// ParseV0StakingTx finds the OP_RETURN output with the given tag, rebuilds
+// the staking output from its data and the covenant committee, and checks
+// that the transaction has exactly one such output
+func ParseV0StakingTx(
+	tx *wire.MsgTx,
+	tag []byte,
+	covenantKeys []*btcec.PublicKey,
+	covenantQuorum uint32,
+) (*ParsedV0StakingTx, error) {
+	if len(tag) != TagLen {
+		return nil, fmt.Errorf("invalid tag length, expected %d, got %d", TagLen, len(tag))
+	}
+
+	opReturnIdx := -1
+	var opReturnData *V0OpReturnData
+	for i, out := range tx.TxOut {
+		data, err := ParseV0OpReturnScript(out.PkScript)
+		if err != nil || !bytes.Equal(data.Tag, tag) {
+			continue
+		}
+		if opReturnIdx >= 0 {
+			return nil, fmt.Errorf("tx has more than one OP_RETURN output with tag %x", tag)
+		}
+		opReturnIdx = i
+		opReturnData = data
+	}
+	if opReturnIdx < 0 {
+		return nil, fmt.Errorf("tx does not have a valid OP_RETURN output with tag %x", tag)
+	}
+
+	// the amount is not committed by the script, the output is found first
+	expected, err := BuildStakingInfo(
+		opReturnData.StakerKey,
+		[]*btcec.PublicKey{opReturnData.FpKey},
+		covenantKeys,
+		covenantQuorum,
+		opReturnData.StakingTime,
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	stakingIdx := -1
+	for i, out := range tx.TxOut {
+		if !bytes.Equal(out.PkScript, expected.StakingOutput.PkScript) {
+			continue
+		}
+		if stakingIdx >= 0 {
+			return nil, fmt.Errorf("tx has more than one staking output")
+		}
+		stakingIdx = i
+	}
+	if stakingIdx < 0 {
+		return nil, fmt.Errorf("tx does not have a staking output matching the OP_RETURN data and the covenant committee")
+	}
+
+	stakingOutput := tx.TxOut[stakingIdx]
+	expected.StakingOutput.Value = stakingOutput.Value
+
+	return &ParsedV0StakingTx{
+		StakingOutput:     stakingOutput,
+		StakingOutputIdx:  stakingIdx,
+		OpReturnOutput:    tx.TxOut[opReturnIdx],
+		OpReturnOutputIdx: opReturnIdx,
+		OpReturnData:      opReturnData,
+		StakingInfo:       expected,
+	}, nil
+}
+
+// StakingAmount returns the value of the staking output
+func (p *ParsedV0StakingTx) StakingAmount() btcutil.Amount {
+	return btcutil.Amount(p.StakingOutput.Value)
+}
@@ -0,0 +1,182 @@
+package btcstaking_test
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+var testTag = []byte{0x01, 0x02, 0x03, 0x04}
+
+func addRandomSeedsToFuzzer(f *testing.F, num uint) {
+	// Seed based on the current time
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	var idx uint
+	for idx = 0; idx < num; idx++ {
+		f.Add(r.Int63())
+	}
+}
+
+type testStaking struct {
+	stakerKey      *btcec.PublicKey
+	fpKey          *btcec.PublicKey
+	covenantKeys   []*btcec.PublicKey
+	covenantQuorum uint32
+	stakingTime    uint16
+	amount         btcutil.Amount
+}
+
+func genTestStaking(t *testing.T, r *rand.Rand) *testStaking {
+	numCovenants := 1 + r.Intn(9)
+	covenantKeys := make([]*btcec.PublicKey, numCovenants)
+	for i := range covenantKeys {
+		covenantKeys[i] = genRandomKey(t)
+	}
+
+	return &testStaking{
+		stakerKey:      genRandomKey(t),
+		fpKey:          genRandomKey(t),
+		covenantKeys:   covenantKeys,
+		covenantQuorum: uint32(1 + r.Intn(numCovenants)),
+		stakingTime:    uint16(1 + r.Intn(65535)),
+		amount:         btcutil.Amount(1 + r.Int63n(btcutil.MaxSatoshi)),
+	}
+}
+
+// stakingTx returns a transaction paying to a change output, the staking
+// output and the OP_RETURN output
+func (s *testStaking) stakingTx(t *testing.T) *wire.MsgTx {
+	info, err := btcstaking.BuildStakingInfo(s.stakerKey, []*btcec.PublicKey{s.fpKey},
+		s.covenantKeys, s.covenantQuorum, s.stakingTime, s.amount)
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	tx.AddTxOut(info.StakingOutput)
+	tx.AddTxOut(wire.NewTxOut(0, opReturnScript(t, testTag, s.stakerKey, s.fpKey, s.stakingTime)))
+	return tx
+}
+
+func FuzzParseV0StakingTx(f *testing.F) {
+	addRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		s := genTestStaking(t, r)
+
+		// the order of the covenant keys does not matter
+		shuffled := append([]*btcec.PublicKey{}, s.covenantKeys...)
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		parsed, err := btcstaking.ParseV0StakingTx(s.stakingTx(t), testTag, shuffled, s.covenantQuorum)
+		require.NoError(t, err)
+		require.Equal(t, 1, parsed.StakingOutputIdx)
+		require.Equal(t, 2, parsed.OpReturnOutputIdx)
+		require.Equal(t, s.amount, parsed.StakingAmount())
+		require.Equal(t, s.stakingTime, parsed.OpReturnData.StakingTime)
+		require.Equal(t, schnorr.SerializePubKey(s.stakerKey), schnorr.SerializePubKey(parsed.OpReturnData.StakerKey))
+		require.Equal(t, schnorr.SerializePubKey(s.fpKey), schnorr.SerializePubKey(parsed.OpReturnData.FpKey))
+		require.True(t, btcstaking.IsNumsKey(parsed.StakingInfo.InternalKey))
+	})
+}
+
+func TestFailParseV0StakingTx(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := genTestStaking(t, r)
+
+	// another covenant committee
+	other := genTestStaking(t, r)
+	_, err := btcstaking.ParseV0StakingTx(s.stakingTx(t), testTag, other.covenantKeys, other.covenantQuorum)
+	require.EqualError(t, err, "tx does not have a staking output matching the OP_RETURN data and the covenant committee")
+
+	// another tag
+	_, err = btcstaking.ParseV0StakingTx(s.stakingTx(t), []byte{0x04, 0x03, 0x02, 0x01}, s.covenantKeys, s.covenantQuorum)
+	require.EqualError(t, err, "tx does not have a valid OP_RETURN output with tag 04030201")
+
+	_, err = btcstaking.ParseV0StakingTx(s.stakingTx(t), []byte{0x01}, s.covenantKeys, s.covenantQuorum)
+	require.EqualError(t, err, "invalid tag length, expected 4, got 1")
+
+	// two OP_RETURN outputs
+	tx := s.stakingTx(t)
+	tx.AddTxOut(tx.TxOut[2])
+	_, err = btcstaking.ParseV0StakingTx(tx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.EqualError(t, err, "tx has more than one OP_RETURN output with tag 01020304")
+
+	// two staking outputs
+	tx = s.stakingTx(t)
+	tx.AddTxOut(tx.TxOut[1])
+	_, err = btcstaking.ParseV0StakingTx(tx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.EqualError(t, err, "tx has more than one staking output")
+
+	// the OP_RETURN data does not match the staking output
+	tx = s.stakingTx(t)
+	tx.TxOut[2].PkScript = opReturnScript(t, testTag, s.stakerKey, s.fpKey, s.stakingTime+1)
+	_, err = btcstaking.ParseV0StakingTx(tx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.EqualError(t, err, "tx does not have a staking output matching the OP_RETURN data and the covenant committee")
+}
+
+func TestFailParseV0OpReturnData(t *testing.T) {
+	key := genRandomKey(t)
+	data := opReturnScript(t, testTag, key, key, 10)[2:]
+
+	_, err := btcstaking.ParseV0OpReturnData(data[1:])
+	require.EqualError(t, err, "invalid OP_RETURN data size, expected 71, got 70")
+
+	invalid := append([]byte{}, data...)
+	invalid[btcstaking.TagLen] = 1
+	_, err = btcstaking.ParseV0OpReturnData(invalid)
+	require.EqualError(t, err, "unsupported OP_RETURN version 1")
+
+	invalid = append([]byte{}, data...)
+	binary.BigEndian.PutUint16(invalid[len(invalid)-2:], 0)
+	_, err = btcstaking.ParseV0OpReturnData(invalid)
+	require.EqualError(t, err, "staking time should be positive")
+
+	_, err = btcstaking.ParseV0OpReturnScript([]byte{txscript.OP_TRUE})
+	require.EqualError(t, err, "script is not an OP_RETURN script")
+}
+
+func TestFailBuildStakingInfo(t *testing.T) {
+	key := genRandomKey(t)
+	_, err := btcstaking.BuildStakingInfo(key, []*btcec.PublicKey{key}, nil, 1, 10, 1000)
+	require.EqualError(t, err, "invalid covenant committee: no keys for the multisig script")
+
+	covenantKeys := []*btcec.PublicKey{genRandomKey(t), genRandomKey(t)}
+	_, err = btcstaking.BuildStakingInfo(key, []*btcec.PublicKey{key}, covenantKeys, 3, 10, 1000)
+	require.EqualError(t, err, "invalid covenant committee: invalid threshold 3 for 2 keys")
+
+	_, err = btcstaking.BuildStakingInfo(key, []*btcec.PublicKey{key}, []*btcec.PublicKey{covenantKeys[0], covenantKeys[0]}, 1, 10, 1000)
+	require.Contains(t, err.Error(), "invalid covenant committee: duplicate key")
+
+	_, err = btcstaking.BuildStakingInfo(key, []*btcec.PublicKey{key}, covenantKeys, 1, 0, 1000)
+	require.EqualError(t, err, "staking time should be positive")
+}
+
+func genRandomKey(t *testing.T) *btcec.PublicKey {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	return privKey.PubKey()
+}
+
+func opReturnScript(t *testing.T, tag []byte, stakerKey, fpKey *btcec.PublicKey, stakingTime uint16) []byte {
+	data := append([]byte{}, tag...)
+	data = append(data, 0)
+	data = append(data, schnorr.SerializePubKey(stakerKey)...)
+	data = append(data, schnorr.SerializePubKey(fpKey)...)
+	data = binary.BigEndian.AppendUint16(data, stakingTime)
+
+	script, err := txscript.NullDataScript(data)
+	require.NoError(t, err)
+	return script
+}
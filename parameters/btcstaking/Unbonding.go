@@ -0,0 +1,85 @@
+package btcstaking
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// CheckUnbondingTx checks that the unbonding transaction only spends the
+// staking output, and has a single output of the staking amount minus the
+// unbonding fee. The unbonding output scripts are checked by the covenant
+// signers.
+func CheckUnbondingTx(
+	stakingTx *wire.MsgTx,
+	parsed *ParsedV0StakingTx,
+	unbondingTx *wire.MsgTx,
+	unbondingFee btcutil.Amount,
+) error {
+	if len(unbondingTx.TxIn) != 1 {
+		return fmt.Errorf("unbonding tx should have exactly one input, got %d", len(unbondingTx.TxIn))
+	}
+	if len(unbondingTx.TxOut) != 1 {
+		return fmt.Errorf("unbonding tx should have exactly one output, got %d", len(unbondingTx.TxOut))
+	}
+
+	stakingHash := stakingTx.TxHash()
+	stakingOutPoint := wire.NewOutPoint(&stakingHash, uint32(parsed.StakingOutputIdx))
+	if unbondingTx.TxIn[0].PreviousOutPoint != *stakingOutPoint {
+		return fmt.Errorf("unbonding tx spends %s, not the staking output %s", unbondingTx.TxIn[0].PreviousOutPoint, stakingOutPoint)
+	}
+
+	expected := parsed.StakingAmount() - unbondingFee
+	if value := btcutil.Amount(unbondingTx.TxOut[0].Value); value != expected {
+		return fmt.Errorf("unbonding output has value %s, it should be the staking amount minus the unbonding fee %s", value, expected)
+	}
+
+	return nil
+}
+
+// UnbondingSigHash returns the taproot signature hash of the unbonding
+// transaction spending the staking output through the unbonding script, which
+// the staker and the covenant committee sign
+func UnbondingSigHash(parsed *ParsedV0StakingTx, unbondingTx *wire.MsgTx) ([]byte, error) {
+	if len(unbondingTx.TxIn) != 1 {
+		return nil, fmt.Errorf("unbonding tx should have exactly one input, got %d", len(unbondingTx.TxIn))
+	}
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(parsed.StakingOutput.PkScript, parsed.StakingOutput.Value)
+	sigHashes := txscript.NewTxSigHashes(unbondingTx, fetcher)
+	leaf := txscript.NewBaseTapLeaf(parsed.StakingInfo.Scripts.UnbondingScript)
+
+	return txscript.CalcTapscriptSignaturehash(sigHashes, txscript.SigHashDefault, unbondingTx, 0, fetcher, leaf)
+}
+
+// SignUnbondingTx signs the unbonding transaction as the staker or as a
+// member of the covenant committee
+func SignUnbondingTx(parsed *ParsedV0StakingTx, unbondingTx *wire.MsgTx, privKey *btcec.PrivateKey) (*schnorr.Signature, error) {
+	sigHash, err := UnbondingSigHash(parsed, unbondingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	// schnorr.Sign negates the given key in place if its public key has an
+	// odd y coordinate, a copy keeps the compressed key of the caller
+	keyCopy := *privKey
+	return schnorr.Sign(&keyCopy, sigHash)
+}
+
+// VerifyUnbondingSig checks the signature of the unbonding transaction by the
+// given key
+func VerifyUnbondingSig(parsed *ParsedV0StakingTx, unbondingTx *wire.MsgTx, pk *btcec.PublicKey, sig *schnorr.Signature) error {
+	sigHash, err := UnbondingSigHash(parsed, unbondingTx)
+	if err != nil {
+		return err
+	}
+
+	if !sig.Verify(sigHash, pk) {
+		return fmt.Errorf("signature of the unbonding tx is not valid for key %x", schnorr.SerializePubKey(pk))
+	}
+	return nil
+}
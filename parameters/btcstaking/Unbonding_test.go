@@ -0,0 +1,86 @@
+package btcstaking_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+func TestUnbondingSignatures(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	s := genTestStaking(t, r)
+	stakingTx := s.stakingTx(t)
+	parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.NoError(t, err)
+
+	fee := parsed.StakingAmount() / 10
+	stakingHash := stakingTx.TxHash()
+	unbondingTx := wire.NewMsgTx(2)
+	unbondingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&stakingHash, 1), nil, nil))
+	unbondingTx.AddTxOut(wire.NewTxOut(int64(parsed.StakingAmount()-fee), []byte{txscript.OP_TRUE}))
+	require.NoError(t, btcstaking.CheckUnbondingTx(stakingTx, parsed, unbondingTx, fee))
+
+	key, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	sig, err := btcstaking.SignUnbondingTx(parsed, unbondingTx, key)
+	require.NoError(t, err)
+	require.NoError(t, btcstaking.VerifyUnbondingSig(parsed, unbondingTx, key.PubKey(), sig))
+	require.Error(t, btcstaking.VerifyUnbondingSig(parsed, unbondingTx, genRandomKey(t), sig))
+
+	// the signature commits to the outputs
+	modified := unbondingTx.Copy()
+	modified.TxOut[0].Value--
+	require.Error(t, btcstaking.VerifyUnbondingSig(parsed, modified, key.PubKey(), sig))
+	require.EqualError(t, btcstaking.CheckUnbondingTx(stakingTx, parsed, modified, fee),
+		"unbonding output has value "+(parsed.StakingAmount()-fee-1).String()+
+			", it should be the staking amount minus the unbonding fee "+(parsed.StakingAmount()-fee).String())
+
+	modified = unbondingTx.Copy()
+	modified.TxIn[0].PreviousOutPoint = *wire.NewOutPoint(&chainhash.Hash{0x02}, 1)
+	require.EqualError(t, btcstaking.CheckUnbondingTx(stakingTx, parsed, modified, fee),
+		"unbonding tx spends "+modified.TxIn[0].PreviousOutPoint.String()+", not the staking output "+stakingHash.String()+":1")
+
+	modified = unbondingTx.Copy()
+	modified.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_TRUE}))
+	require.EqualError(t, btcstaking.CheckUnbondingTx(stakingTx, parsed, modified, fee),
+		"unbonding tx should have exactly one output, got 2")
+}
+
+func TestBuildV0OpReturnScript(t *testing.T) {
+	key := genRandomKey(t)
+	script, err := btcstaking.BuildV0OpReturnScript(testTag, key, key, 10)
+	require.NoError(t, err)
+	require.Equal(t, opReturnScript(t, testTag, key, key, 10), script)
+
+	_, err = btcstaking.BuildV0OpReturnScript([]byte{0x01}, key, key, 10)
+	require.EqualError(t, err, "invalid tag length, expected 4, got 1")
+}
+
+func TestSignUnbondingTxKeepsKey(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	s := genTestStaking(t, r)
+	stakingTx := s.stakingTx(t)
+	parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.NoError(t, err)
+	stakingHash := stakingTx.TxHash()
+	unbondingTx := wire.NewMsgTx(2)
+	unbondingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&stakingHash, 1), nil, nil))
+	unbondingTx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	// keys with an odd y coordinate are the ones schnorr.Sign negates
+	for i := 0; i < 10; i++ {
+		key, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+		compressed := key.PubKey().SerializeCompressed()
+		_, err = btcstaking.SignUnbondingTx(parsed, unbondingTx, key)
+		require.NoError(t, err)
+		require.Equal(t, compressed, key.PubKey().SerializeCompressed())
+	}
+}
@@ -0,0 +1,127 @@
+package btcstaking
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ControlBlock returns the serialized control block spending the output
+// through the given script, which should be one of its scripts
+func (s *StakingInfo) ControlBlock(script []byte) ([]byte, error) {
+	leafHash := txscript.NewBaseTapLeaf(script).TapHash()
+	idx, ok := s.ScriptTree.LeafProofIndex[leafHash]
+	if !ok {
+		return nil, fmt.Errorf("script is not a leaf of the staking output")
+	}
+
+	controlBlock := s.ScriptTree.LeafMerkleProofs[idx].ToControlBlock(s.InternalKey)
+	return controlBlock.ToBytes()
+}
+
+// timeLockWithdrawalWeight returns the weight of the withdrawal tx once
+// signed, using a placeholder for the staker signature
+func timeLockWithdrawalWeight(tx *wire.MsgTx, script, controlBlock []byte) int64 {
+	signed := tx.Copy()
+	signed.TxIn[0].Witness = wire.TxWitness{
+		make([]byte, schnorr.SignatureSize),
+		script,
+		controlBlock,
+	}
+	return blockchain.GetTransactionWeight(btcutil.NewTx(signed))
+}
+
+// BuildTimeLockWithdrawalTx builds the unsigned transaction spending the
+// staking output through the time lock path to the given script. The fee is
+// computed from the fee rate in satoshis per virtual byte and the size of the
+// transaction once signed by the staker, and should be at least 1 satoshi
+// per virtual byte. The transaction can only be included StakingTime blocks
+// after the staking transaction.
+func BuildTimeLockWithdrawalTx(
+	stakingTx *wire.MsgTx,
+	parsed *ParsedV0StakingTx,
+	destination []byte,
+	feeRate btcutil.Amount,
+) (*wire.MsgTx, error) {
+	// a fee rate below 1 would leave an output worth at least the input
+	if feeRate < 1 {
+		return nil, fmt.Errorf("fee rate of %d satoshis per virtual byte is below 1", int64(feeRate))
+	}
+
+	script := parsed.StakingInfo.Scripts.TimeLockScript
+	controlBlock, err := parsed.StakingInfo.ControlBlock(script)
+	if err != nil {
+		return nil, err
+	}
+
+	stakingTxHash := stakingTx.TxHash()
+	tx := wire.NewMsgTx(2)
+	txIn := wire.NewTxIn(wire.NewOutPoint(&stakingTxHash, uint32(parsed.StakingOutputIdx)), nil, nil)
+	// the relative time lock of the script is enforced through the sequence
+	txIn.Sequence = uint32(parsed.OpReturnData.StakingTime)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(parsed.StakingOutput.Value, destination))
+
+	vsize := (timeLockWithdrawalWeight(tx, script, controlBlock) + blockchain.WitnessScaleFactor - 1) /
+		blockchain.WitnessScaleFactor
+	fee := feeRate * btcutil.Amount(vsize)
+	tx.TxOut[0].Value -= int64(fee)
+
+	if mempool.IsDust(tx.TxOut[0], mempool.DefaultMinRelayTxFee) {
+		return nil, fmt.Errorf("withdrawal output of %d satoshis after a fee of %d satoshis is dust",
+			tx.TxOut[0].Value, fee)
+	}
+
+	return tx, nil
+}
+
+// BuildTimeLockWithdrawalPsbt builds the withdrawal transaction as a PSBT
+// with the staking output, the time lock script and its control block, so
+// that a wallet holding the staker key can sign and finalize it
+func BuildTimeLockWithdrawalPsbt(
+	stakingTx *wire.MsgTx,
+	parsed *ParsedV0StakingTx,
+	destination []byte,
+	feeRate btcutil.Amount,
+) (*psbt.Packet, error) {
+	tx, err := BuildTimeLockWithdrawalTx(stakingTx, parsed, destination, feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	script := parsed.StakingInfo.Scripts.TimeLockScript
+	controlBlock, err := parsed.StakingInfo.ControlBlock(script)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &packet.Inputs[0]
+	input.WitnessUtxo = parsed.StakingOutput
+	input.SighashType = txscript.SigHashDefault
+	input.TaprootInternalKey = schnorr.SerializePubKey(parsed.StakingInfo.InternalKey)
+	input.TaprootLeafScript = []*psbt.TaprootTapLeafScript{{
+		ControlBlock: controlBlock,
+		Script:       script,
+		LeafVersion:  txscript.BaseLeafVersion,
+	}}
+	// the staker key is the only key of the time lock script, its origin is
+	// unknown to the registry
+	leafHash := txscript.NewBaseTapLeaf(script).TapHash()
+	input.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{{
+		XOnlyPubKey: schnorr.SerializePubKey(parsed.OpReturnData.StakerKey),
+		LeafHashes:  [][]byte{leafHash[:]},
+	}}
+
+	return packet, nil
+}
@@ -0,0 +1,168 @@
+package btcstaking_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+var withdrawalDestination = []byte{txscript.OP_1, txscript.OP_DATA_32,
+	1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+	17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32}
+
+// signTimeLockWithdrawal signs the withdrawal with the staker key and returns
+// the weight of the signed tx
+func signTimeLockWithdrawal(
+	t *testing.T,
+	tx *wire.MsgTx,
+	parsed *btcstaking.ParsedV0StakingTx,
+	stakerKey *btcec.PrivateKey,
+) int64 {
+	script := parsed.StakingInfo.Scripts.TimeLockScript
+	fetcher := txscript.NewCannedPrevOutputFetcher(parsed.StakingOutput.PkScript, parsed.StakingOutput.Value)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	sig, err := txscript.RawTxInTapscriptSignature(tx, sigHashes, 0, parsed.StakingOutput.Value,
+		parsed.StakingOutput.PkScript, txscript.NewBaseTapLeaf(script), txscript.SigHashDefault, stakerKey)
+	require.NoError(t, err)
+
+	controlBlock, err := parsed.StakingInfo.ControlBlock(script)
+	require.NoError(t, err)
+	tx.TxIn[0].Witness = wire.TxWitness{sig, script, controlBlock}
+
+	engine, err := txscript.NewEngine(parsed.StakingOutput.PkScript, tx, 0, txscript.StandardVerifyFlags,
+		nil, sigHashes, parsed.StakingOutput.Value, fetcher)
+	require.NoError(t, err)
+	require.NoError(t, engine.Execute())
+
+	return blockchain.GetTransactionWeight(btcutil.NewTx(tx))
+}
+
+func FuzzTimeLockWithdrawal(f *testing.F) {
+	addRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		s := genTestStaking(t, r)
+		stakerKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+		s.stakerKey = stakerKey.PubKey()
+		s.amount = btcutil.Amount(100000 + r.Int63n(btcutil.SatoshiPerBitcoin))
+
+		stakingTx := s.stakingTx(t)
+		parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+		require.NoError(t, err)
+
+		feeRate := btcutil.Amount(1 + r.Int63n(100))
+		tx, err := btcstaking.BuildTimeLockWithdrawalTx(stakingTx, parsed, withdrawalDestination, feeRate)
+		require.NoError(t, err)
+		require.Equal(t, stakingTx.TxHash(), tx.TxIn[0].PreviousOutPoint.Hash)
+		require.Equal(t, uint32(1), tx.TxIn[0].PreviousOutPoint.Index)
+		require.Equal(t, uint32(s.stakingTime), tx.TxIn[0].Sequence)
+		require.Equal(t, withdrawalDestination, tx.TxOut[0].PkScript)
+
+		// the fee pays for the size of the signed tx
+		weight := signTimeLockWithdrawal(t, tx, parsed, stakerKey)
+		vsize := (weight + 3) / 4
+		require.Equal(t, int64(s.amount)-int64(feeRate)*vsize, tx.TxOut[0].Value)
+	})
+}
+
+func TestFailTimeLockWithdrawalBeforeStakingTime(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := genTestStaking(t, r)
+	stakerKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	s.stakerKey = stakerKey.PubKey()
+	s.amount = 100000
+
+	stakingTx := s.stakingTx(t)
+	parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.NoError(t, err)
+	tx, err := btcstaking.BuildTimeLockWithdrawalTx(stakingTx, parsed, withdrawalDestination, 1)
+	require.NoError(t, err)
+
+	tx.TxIn[0].Sequence--
+	script := parsed.StakingInfo.Scripts.TimeLockScript
+	fetcher := txscript.NewCannedPrevOutputFetcher(parsed.StakingOutput.PkScript, parsed.StakingOutput.Value)
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	sig, err := txscript.RawTxInTapscriptSignature(tx, sigHashes, 0, parsed.StakingOutput.Value,
+		parsed.StakingOutput.PkScript, txscript.NewBaseTapLeaf(script), txscript.SigHashDefault, stakerKey)
+	require.NoError(t, err)
+	controlBlock, err := parsed.StakingInfo.ControlBlock(script)
+	require.NoError(t, err)
+	tx.TxIn[0].Witness = wire.TxWitness{sig, script, controlBlock}
+
+	engine, err := txscript.NewEngine(parsed.StakingOutput.PkScript, tx, 0, txscript.StandardVerifyFlags,
+		nil, sigHashes, parsed.StakingOutput.Value, fetcher)
+	require.NoError(t, err)
+	require.Error(t, engine.Execute())
+}
+
+func TestFailDustWithdrawal(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	s := genTestStaking(t, r)
+	s.amount = 1000
+
+	stakingTx := s.stakingTx(t)
+	parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.NoError(t, err)
+
+	_, err = btcstaking.BuildTimeLockWithdrawalTx(stakingTx, parsed, withdrawalDestination, 10)
+	require.Contains(t, err.Error(), "is dust")
+}
+
+func TestFailWithdrawalFeeRate(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	s := genTestStaking(t, r)
+	s.amount = 100000
+
+	stakingTx := s.stakingTx(t)
+	parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.NoError(t, err)
+
+	_, err = btcstaking.BuildTimeLockWithdrawalTx(stakingTx, parsed, withdrawalDestination, 0)
+	require.EqualError(t, err, "fee rate of 0 satoshis per virtual byte is below 1")
+	_, err = btcstaking.BuildTimeLockWithdrawalPsbt(stakingTx, parsed, withdrawalDestination, -5)
+	require.EqualError(t, err, "fee rate of -5 satoshis per virtual byte is below 1")
+}
+
+func TestTimeLockWithdrawalPsbt(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	s := genTestStaking(t, r)
+	s.amount = 100000
+
+	stakingTx := s.stakingTx(t)
+	parsed, err := btcstaking.ParseV0StakingTx(stakingTx, testTag, s.covenantKeys, s.covenantQuorum)
+	require.NoError(t, err)
+
+	packet, err := btcstaking.BuildTimeLockWithdrawalPsbt(stakingTx, parsed, withdrawalDestination, 5)
+	require.NoError(t, err)
+	tx, err := btcstaking.BuildTimeLockWithdrawalTx(stakingTx, parsed, withdrawalDestination, 5)
+	require.NoError(t, err)
+	require.Equal(t, tx.TxHash(), packet.UnsignedTx.TxHash())
+
+	input := packet.Inputs[0]
+	require.Equal(t, parsed.StakingOutput, input.WitnessUtxo)
+	require.Equal(t, btcstaking.NumsKeyHex, hexEncode(input.TaprootInternalKey))
+	require.Len(t, input.TaprootLeafScript, 1)
+	require.Equal(t, parsed.StakingInfo.Scripts.TimeLockScript, input.TaprootLeafScript[0].Script)
+	require.Len(t, input.TaprootBip32Derivation, 1)
+	require.Equal(t, schnorr.SerializePubKey(s.stakerKey), input.TaprootBip32Derivation[0].XOnlyPubKey)
+
+	encoded, err := packet.B64Encode()
+	require.NoError(t, err)
+	require.NotEmpty(t, encoded)
+}
+
+func hexEncode(b []byte) string {
+	return fmt.Sprintf("%x", b)
+}
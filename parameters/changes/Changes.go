@@ -0,0 +1,223 @@
+// Package changes detects which finality provider registry files changed
+// between two revisions of a local git repository, and checks those changes
+// against the registry policy. No network access is needed.
+package changes
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Modified ChangeKind = "modified"
+	Renamed  ChangeKind = "renamed"
+	Deleted  ChangeKind = "deleted"
+)
+
+type FileKind string
+
+const (
+	EntryFile     FileKind = "entry"
+	SignatureFile FileKind = "signature"
+	// UpdateFile is an update record of an entry or its signature
+	UpdateFile FileKind = "update"
+)
+
+const (
+	entryExt     = ".json"
+	signatureExt = ".sig"
+)
+
+// Change is a registry or signature file changed between two revisions.
+// Paths are relative to the root of the repository. OldPath is only set for
+// renamed and deleted files, Path is empty for deleted files.
+type Change struct {
+	Kind    ChangeKind
+	File    FileKind
+	Path    string
+	OldPath string
+}
+
+// Nickname returns the nickname of the finality provider the changed file
+// belongs to, after the change if the file was renamed
+func (c *Change) Nickname() string {
+	p := c.Path
+	if p == "" {
+		p = c.OldPath
+	}
+	// update records are stored under a directory named after the entry
+	if c.File == UpdateFile {
+		return path.Base(path.Dir(p))
+	}
+	return strings.TrimSuffix(path.Base(p), path.Ext(p))
+}
+
+func (c *Change) String() string {
+	if c.Kind == Renamed {
+		return fmt.Sprintf("%s %s %s -> %s", c.Kind, c.File, c.OldPath, c.Path)
+	}
+	if c.Kind == Deleted {
+		return fmt.Sprintf("%s %s %s", c.Kind, c.File, c.OldPath)
+	}
+	return fmt.Sprintf("%s %s %s", c.Kind, c.File, c.Path)
+}
+
+func runGit(repoDir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", repoDir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// fileKind returns the kind of a registry file from its path, or false if
+// the path is not a registry entry, a signature nor an update record
+func fileKind(p string) (FileKind, bool) {
+	dir := path.Base(path.Dir(p))
+	ext := path.Ext(p)
+	switch {
+	case dir == registry.RegistryDirName && ext == entryExt:
+		return EntryFile, true
+	case dir == registry.SigsDirName && ext == signatureExt:
+		return SignatureFile, true
+	case path.Base(path.Dir(path.Dir(p))) == registry.UpdatesDirName && (ext == entryExt || ext == signatureExt):
+		return UpdateFile, true
+	default:
+		return "", false
+	}
+}
+
+// parseNameStatus parses the output of git diff --name-status -z
+func parseNameStatus(out []byte) ([]*Change, error) {
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return nil, nil
+	}
+
+	var changes []*Change
+	for i := 0; i < len(fields); {
+		status := fields[i]
+		if status == "" {
+			return nil, fmt.Errorf("unexpected empty status in git diff output")
+		}
+
+		numPaths := 1
+		if status[0] == 'R' || status[0] == 'C' {
+			numPaths = 2
+		}
+		if i+numPaths >= len(fields) {
+			return nil, fmt.Errorf("truncated git diff output for status %s", status)
+		}
+		paths := fields[i+1 : i+1+numPaths]
+		i += 1 + numPaths
+
+		var c Change
+		switch status[0] {
+		case 'A', 'C':
+			c = Change{Kind: Added, Path: paths[numPaths-1]}
+		case 'M', 'T':
+			c = Change{Kind: Modified, Path: paths[0]}
+		case 'D':
+			c = Change{Kind: Deleted, OldPath: paths[0]}
+		case 'R':
+			c = Change{Kind: Renamed, OldPath: paths[0], Path: paths[1]}
+		default:
+			return nil, fmt.Errorf("unsupported git diff status %s", status)
+		}
+
+		// a file moved in, out or across the registry directories is seen as
+		// a deletion followed by an addition
+		if c.Kind == Renamed {
+			oldKind, oldOk := fileKind(c.OldPath)
+			newKind, newOk := fileKind(c.Path)
+			if !oldOk || !newOk || oldKind != newKind {
+				if oldOk {
+					changes = append(changes, &Change{Kind: Deleted, File: oldKind, OldPath: c.OldPath})
+				}
+				if newOk {
+					changes = append(changes, &Change{Kind: Added, File: newKind, Path: c.Path})
+				}
+				continue
+			}
+		}
+
+		p := c.Path
+		if c.Kind == Deleted {
+			p = c.OldPath
+		}
+		kind, ok := fileKind(p)
+		if !ok {
+			continue
+		}
+		c.File = kind
+		changes = append(changes, &c)
+	}
+
+	return changes, nil
+}
+
+// DetectChanges lists the registry, signature and update files added,
+// modified, renamed or deleted between the base and head revisions of the repository
+// at repoDir. Both revisions should be available locally.
+func DetectChanges(repoDir, base, head string) ([]*Change, error) {
+	out, err := runGit(repoDir, "diff", "--name-status", "-z", "--no-color", "--find-renames",
+		base, head, "--",
+		":(glob)**/"+registry.RegistryDirName+"/*"+entryExt,
+		":(glob)**/"+registry.SigsDirName+"/*"+signatureExt,
+		":(glob)**/"+registry.UpdatesDirName+"/*/*"+entryExt,
+		":(glob)**/"+registry.UpdatesDirName+"/*/*"+signatureExt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNameStatus(out)
+}
+
+// ReadFileAt returns the content of the file at the given revision
+func ReadFileAt(repoDir, rev, filePath string) ([]byte, error) {
+	return runGit(repoDir, "show", rev+":"+filePath)
+}
+
+// EntriesToVerify returns the paths, at head, of the registry entries which
+// should be verified again after the changes: entries added, modified or
+// renamed and entries whose signature or update records changed
+func EntriesToVerify(changes []*Change) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	for _, c := range changes {
+		if c.Kind == Deleted {
+			continue
+		}
+		switch c.File {
+		case EntryFile:
+			add(c.Path)
+		case SignatureFile:
+			fpDir := path.Dir(path.Dir(c.Path))
+			add(path.Join(fpDir, registry.RegistryDirName, c.Nickname()+entryExt))
+		case UpdateFile:
+			fpDir := path.Dir(path.Dir(path.Dir(c.Path)))
+			add(path.Join(fpDir, registry.RegistryDirName, c.Nickname()+entryExt))
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,140 @@
+package changes_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/changes"
+)
+
+const (
+	fpDir        = "bbn-test-4/finality-providers"
+	registryDir  = fpDir + "/registry"
+	sigsDir      = fpDir + "/sigs"
+	testBtcPk    = "030bd6622049385a958057774d4a95af246d17cd69146bda2021a12a422f37d3"
+	otherBtcPk   = "a89e7caf57360bc8b791df72abc3fb6d2ddc0e06e171c9f17c4ea1299e677565"
+	testRegEntry = `{"description": {"moniker": "%s"}, "btc_pk": "%s", "commission": "0.05"}`
+)
+
+// testRepo is a throw away git repository
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@test.io", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func (r *testRepo) write(p, content string) {
+	full := filepath.Join(r.dir, p)
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(full), 0o755))
+	require.NoError(r.t, os.WriteFile(full, []byte(content), 0o644))
+}
+
+func (r *testRepo) commit() string {
+	r.git("add", "-A")
+	r.git("commit", "-q", "--allow-empty", "-m", "commit")
+	return r.git("rev-parse", "HEAD")
+}
+
+func entryContent(moniker, btcPk string) string {
+	return strings.Replace(strings.Replace(testRegEntry, "%s", moniker, 1), "%s", btcPk, 1)
+}
+
+func TestDetectChanges(t *testing.T) {
+	r := newTestRepo(t)
+	r.write(registryDir+"/alice.json", entryContent("alice moniker", testBtcPk))
+	r.write(sigsDir+"/alice.sig", "aa")
+	r.write(registryDir+"/bob.json", entryContent("bob moniker", otherBtcPk))
+	r.write(sigsDir+"/bob.sig", "bb")
+	r.write(registryDir+"/carol.json", entryContent("a long enough moniker to be detected as renamed", testBtcPk))
+	r.write(sigsDir+"/carol.sig", "cc")
+	r.write("README.md", "readme")
+	base := r.commit()
+
+	r.write(registryDir+"/alice.json", entryContent("alice new moniker", testBtcPk))
+	r.git("rm", "-q", registryDir+"/bob.json", sigsDir+"/bob.sig")
+	r.git("mv", registryDir+"/carol.json", registryDir+"/caroline.json")
+	r.write(sigsDir+"/carol.sig", "cc2")
+	r.write(registryDir+"/dave.json", entryContent("dave", otherBtcPk))
+	r.write(sigsDir+"/dave.sig", "dd")
+	r.write("README.md", "changed readme")
+	r.write(fpDir+"/scripts/notes.json", "{}")
+	head := r.commit()
+
+	detected, err := changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+
+	var summary []string
+	for _, c := range detected {
+		summary = append(summary, c.String())
+	}
+	assert.ElementsMatch(t, []string{
+		"modified entry " + registryDir + "/alice.json",
+		"deleted entry " + registryDir + "/bob.json",
+		"deleted signature " + sigsDir + "/bob.sig",
+		"renamed entry " + registryDir + "/carol.json -> " + registryDir + "/caroline.json",
+		"modified signature " + sigsDir + "/carol.sig",
+		"added entry " + registryDir + "/dave.json",
+		"added signature " + sigsDir + "/dave.sig",
+	}, summary)
+
+	assert.ElementsMatch(t, []string{
+		registryDir + "/alice.json",
+		registryDir + "/caroline.json",
+		registryDir + "/carol.json",
+		registryDir + "/dave.json",
+	}, changes.EntriesToVerify(detected))
+
+	// no changes between the same revisions
+	detected, err = changes.DetectChanges(r.dir, head, head)
+	require.NoError(t, err)
+	assert.Empty(t, detected)
+}
+
+func TestDetectChangesMovedOutOfRegistry(t *testing.T) {
+	r := newTestRepo(t)
+	content := entryContent("a long enough moniker to be detected as renamed", testBtcPk)
+	r.write(registryDir+"/alice.json", content)
+	r.write(sigsDir+"/bob.sig", "bb")
+	base := r.commit()
+
+	r.git("mv", registryDir+"/alice.json", sigsDir+"/alice.sig")
+	head := r.commit()
+
+	detected, err := changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+	require.Len(t, detected, 2)
+	assert.Equal(t, changes.Deleted, detected[0].Kind)
+	assert.Equal(t, changes.EntryFile, detected[0].File)
+	assert.Equal(t, changes.Added, detected[1].Kind)
+	assert.Equal(t, changes.SignatureFile, detected[1].File)
+	assert.Equal(t, "alice", detected[1].Nickname())
+}
+
+func TestFailDetectChanges(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("README.md", "readme")
+	head := r.commit()
+
+	_, err := changes.DetectChanges(r.dir, "unknown-revision", head)
+	assert.Contains(t, err.Error(), "git diff")
+}
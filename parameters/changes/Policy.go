@@ -0,0 +1,92 @@
+package changes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+// Policy defines which changes to the registry are accepted. The zero value is
+// the policy of the registry: entries and signatures cannot be deleted, the
+// BTC public key of an entry cannot be changed, and update records are
+// append-only.
+type Policy struct {
+	AllowDeletions      bool
+	AllowBtcPkChanges   bool
+	AllowUpdateRewrites bool
+}
+
+// Violation is a change rejected by the policy
+type Violation struct {
+	Change *Change
+	Reason string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Change, v.Reason)
+}
+
+func (p *Policy) checkBtcPk(repoDir, base, head string, c *Change) (*Violation, error) {
+	oldPath := c.OldPath
+	if oldPath == "" {
+		oldPath = c.Path
+	}
+
+	oldData, err := ReadFileAt(repoDir, base, oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := ReadFileAt(repoDir, head, c.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries which cannot be decoded are reported by the entry validation
+	oldFp, err := registry.NewFinalityProviderFromBytes(oldData)
+	if err != nil {
+		return nil, nil
+	}
+	newFp, err := registry.NewFinalityProviderFromBytes(newData)
+	if err != nil {
+		return nil, nil
+	}
+
+	if !strings.EqualFold(oldFp.BtcPk, newFp.BtcPk) {
+		return &Violation{
+			Change: c,
+			Reason: fmt.Sprintf("btc_pk changed from %s to %s", oldFp.BtcPk, newFp.BtcPk),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// Check returns every change between base and head rejected by the policy
+func (p *Policy) Check(repoDir, base, head string, changes []*Change) ([]*Violation, error) {
+	var violations []*Violation
+	for _, c := range changes {
+		switch {
+		case c.Kind == Deleted && !p.AllowDeletions:
+			violations = append(violations, &Violation{
+				Change: c,
+				Reason: fmt.Sprintf("%s files cannot be deleted", c.File),
+			})
+		case c.File == UpdateFile && (c.Kind == Modified || c.Kind == Renamed) && !p.AllowUpdateRewrites:
+			violations = append(violations, &Violation{
+				Change: c,
+				Reason: "update records cannot be changed, add a record with a larger sequence",
+			})
+		case c.File == EntryFile && (c.Kind == Modified || c.Kind == Renamed) && !p.AllowBtcPkChanges:
+			v, err := p.checkBtcPk(repoDir, base, head, c)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,98 @@
+package changes_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/changes"
+)
+
+func TestPolicy(t *testing.T) {
+	r := newTestRepo(t)
+	r.write(registryDir+"/alice.json", entryContent("alice", testBtcPk))
+	r.write(sigsDir+"/alice.sig", "aa")
+	r.write(registryDir+"/bob.json", entryContent("bob", otherBtcPk))
+	r.write(sigsDir+"/bob.sig", "bb")
+	r.write(registryDir+"/carol.json", entryContent("carol", testBtcPk))
+	base := r.commit()
+
+	// alice only changes her moniker, bob changes his key and carol is removed
+	r.write(registryDir+"/alice.json", entryContent("alice new", testBtcPk))
+	r.write(registryDir+"/bob.json", entryContent("bob", testBtcPk))
+	r.git("rm", "-q", registryDir+"/carol.json")
+	head := r.commit()
+
+	detected, err := changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+	require.Len(t, detected, 3)
+
+	var policy changes.Policy
+	violations, err := policy.Check(r.dir, base, head, detected)
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, "modified entry "+registryDir+"/bob.json: btc_pk changed from "+otherBtcPk+" to "+testBtcPk, violations[0].Error())
+	assert.Equal(t, "deleted entry "+registryDir+"/carol.json: entry files cannot be deleted", violations[1].Error())
+
+	permissive := changes.Policy{AllowDeletions: true, AllowBtcPkChanges: true}
+	violations, err = permissive.Check(r.dir, base, head, detected)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestPolicyRenamedEntry(t *testing.T) {
+	r := newTestRepo(t)
+	content := entryContent("a long enough moniker to be detected as renamed", testBtcPk)
+	r.write(registryDir+"/alice.json", content)
+	base := r.commit()
+
+	r.git("mv", registryDir+"/alice.json", registryDir+"/alice2.json")
+	head := r.commit()
+
+	detected, err := changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+	require.Len(t, detected, 1)
+	require.Equal(t, changes.Renamed, detected[0].Kind)
+
+	// renaming an entry keeping its key is accepted
+	var policy changes.Policy
+	violations, err := policy.Check(r.dir, base, head, detected)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestPolicyUpdateRecords(t *testing.T) {
+	updatesDir := fpDir + "/updates/alice"
+	r := newTestRepo(t)
+	r.write(registryDir+"/alice.json", entryContent("alice", testBtcPk))
+	r.write(updatesDir+"/1.json", `{"type": "update", "sequence": 1}`)
+	r.write(updatesDir+"/1.sig", "aa")
+	base := r.commit()
+
+	r.write(updatesDir+"/1.json", `{"type": "update", "sequence": 1, "commission": "0.1"}`)
+	r.write(updatesDir+"/2.json", `{"type": "tombstone", "sequence": 2}`)
+	r.write(updatesDir+"/2.sig", "bb")
+	head := r.commit()
+
+	detected, err := changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+	require.Len(t, detected, 3)
+	for _, c := range detected {
+		assert.Equal(t, changes.UpdateFile, c.File)
+		assert.Equal(t, "alice", c.Nickname())
+	}
+	assert.Equal(t, []string{registryDir + "/alice.json"}, changes.EntriesToVerify(detected))
+
+	var policy changes.Policy
+	violations, err := policy.Check(r.dir, base, head, detected)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "modified update "+updatesDir+"/1.json: update records cannot be changed, add a record with a larger sequence",
+		violations[0].Error())
+
+	permissive := changes.Policy{AllowUpdateRewrites: true}
+	violations, err = permissive.Check(r.dir, base, head, detected)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
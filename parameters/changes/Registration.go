@@ -0,0 +1,88 @@
+package changes
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func fileExistsAt(repoDir, rev, filePath string) bool {
+	_, err := runGit(repoDir, "cat-file", "-e", rev+":"+filePath)
+	return err == nil
+}
+
+// countEntriesAt returns the number of registry entries of the finality
+// providers directory at the revision
+func countEntriesAt(repoDir, rev, fpDir string) (int, error) {
+	out, err := runGit(repoDir, "ls-tree", "--name-only", "-z", rev+":"+path.Join(fpDir, registry.RegistryDirName))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, name := range strings.Split(string(out), "\x00") {
+		if path.Ext(name) == entryExt {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CheckRegistrations checks the entries added between base and head against
+// the registration policy of their network. The policy is read at base, so
+// that a pull request cannot change the policy it is checked against, and
+// networks without policy accept every entry. Modified entries and update
+// records are not registrations.
+func CheckRegistrations(
+	repoDir, base, head string,
+	changes []*Change,
+	clock *registry.RegistrationClock,
+) ([]*Violation, error) {
+	policies := make(map[string]*registry.RegistrationPolicy)
+	counts := make(map[string]int)
+
+	var violations []*Violation
+	for _, c := range changes {
+		if c.Kind != Added || c.File != EntryFile {
+			continue
+		}
+
+		fpDir := path.Dir(path.Dir(c.Path))
+		policy, ok := policies[fpDir]
+		if !ok {
+			policyPath := path.Join(fpDir, registry.RegistrationPolicyFileName)
+			if fileExistsAt(repoDir, base, policyPath) {
+				data, err := ReadFileAt(repoDir, base, policyPath)
+				if err != nil {
+					return nil, err
+				}
+				policy, err = registry.NewRegistrationPolicyFromBytes(data)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", policyPath, err)
+				}
+			}
+			policies[fpDir] = policy
+
+			count, err := countEntriesAt(repoDir, head, fpDir)
+			if err != nil {
+				return nil, err
+			}
+			counts[fpDir] = count
+		}
+		if policy == nil {
+			continue
+		}
+
+		if err := policy.CheckOpen(clock); err != nil {
+			violations = append(violations, &Violation{Change: c, Reason: err.Error()})
+			continue
+		}
+		if err := policy.CheckQuota(counts[fpDir]); err != nil {
+			violations = append(violations, &Violation{Change: c, Reason: err.Error()})
+		}
+	}
+
+	return violations, nil
+}
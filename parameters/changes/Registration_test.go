@@ -0,0 +1,54 @@
+package changes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/changes"
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func TestCheckRegistrations(t *testing.T) {
+	r := newTestRepo(t)
+	r.write(fpDir+"/registration.json", `{"close_time": "2024-06-25T00:00:00-12:00", "max_providers": 2}`)
+	r.write(registryDir+"/alice.json", entryContent("alice", testBtcPk))
+	r.write("other-net/finality-providers/registry/zoe.json", entryContent("zoe", testBtcPk))
+	base := r.commit()
+
+	r.write(registryDir+"/alice.json", entryContent("alice new", testBtcPk))
+	r.write(registryDir+"/bob.json", entryContent("bob", otherBtcPk))
+	r.write(fpDir+"/updates/alice/1.json", `{"type": "update", "sequence": 1}`)
+	// networks without policy are not restricted
+	r.write("other-net/finality-providers/registry/yann.json", entryContent("yann", otherBtcPk))
+	head := r.commit()
+
+	detected, err := changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+
+	open := &registry.RegistrationClock{Time: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)}
+	violations, err := changes.CheckRegistrations(r.dir, base, head, detected, open)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	closed := &registry.RegistrationClock{Time: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}
+	violations, err = changes.CheckRegistrations(r.dir, base, head, detected, closed)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "added entry "+registryDir+"/bob.json: registration closed at 2024-06-25T00:00:00-12:00", violations[0].Error())
+
+	// the quota is checked against the registry at head
+	r.write(registryDir+"/carol.json", entryContent("carol", otherBtcPk))
+	// the policy is read at base, the pull request cannot lift it
+	r.write(fpDir+"/registration.json", `{}`)
+	head = r.commit()
+	detected, err = changes.DetectChanges(r.dir, base, head)
+	require.NoError(t, err)
+	violations, err = changes.CheckRegistrations(r.dir, base, head, detected, open)
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, "added entry "+registryDir+"/bob.json: registration quota of 2 finality providers exceeded with 3 entries", violations[0].Error())
+	assert.Equal(t, "added entry "+registryDir+"/carol.json: registration quota of 2 finality providers exceeded with 3 entries", violations[1].Error())
+}
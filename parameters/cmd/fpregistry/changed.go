@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/babylonchain/networks/parameters/changes"
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func runChanged(args []string) error {
+	fs := flag.NewFlagSet("changed", flag.ExitOnError)
+	repo := fs.String("repo", ".", "path of the local git repository")
+	base := fs.String("base", "", "base revision, e.g. the target branch of the pull request")
+	head := fs.String("head", "HEAD", "head revision")
+	allowDeletions := fs.Bool("allow-deletions", false, "accept deleted entries and signatures")
+	allowBtcPkChanges := fs.Bool("allow-btc-pk-changes", false, "accept changes of the btc_pk of an entry")
+	allowUpdateRewrites := fs.Bool("allow-update-rewrites", false, "accept changes of merged update records")
+	now := fs.String("now", "", "RFC3339 time to check new registrations at, the time of the CI job")
+	btcHeight := fs.Uint64("btc-height", 0, "BTC height to check new registrations at, for policies bounded by heights")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// commit times are set by whoever commits, so the time of the check is
+	// given by the job running it
+	if *base == "" || *now == "" {
+		return fmt.Errorf("--base and --now are required")
+	}
+
+	t, err := time.Parse(time.RFC3339, *now)
+	if err != nil {
+		return fmt.Errorf("invalid --now: %w", err)
+	}
+	clock := &registry.RegistrationClock{Time: t, BtcHeight: *btcHeight}
+
+	detected, err := changes.DetectChanges(*repo, *base, *head)
+	if err != nil {
+		return err
+	}
+	for _, c := range detected {
+		fmt.Fprintln(os.Stderr, c)
+	}
+
+	policy := changes.Policy{
+		AllowDeletions:      *allowDeletions,
+		AllowBtcPkChanges:   *allowBtcPkChanges,
+		AllowUpdateRewrites: *allowUpdateRewrites,
+	}
+	violations, err := policy.Check(*repo, *base, *head, detected)
+	if err != nil {
+		return err
+	}
+	registrationViolations, err := changes.CheckRegistrations(*repo, *base, *head, detected, clock)
+	if err != nil {
+		return err
+	}
+	violations = append(violations, registrationViolations...)
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d changes are rejected by the registry policy", len(violations))
+	}
+
+	// the entries to verify are printed one per line, ready to be iterated
+	for _, p := range changes.EntriesToVerify(detected) {
+		fmt.Println(p)
+	}
+	return nil
+}
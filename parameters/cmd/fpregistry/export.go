@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "bbn-test-4/finality-providers", "finality providers directory to export")
+	format := fs.String("format", "csv", "output format, one of csv, json or markdown")
+	fields := fs.String("fields", "", "comma separated list of fields to export, all fields if empty")
+	sortBy := fs.String("sort-by", string(registry.FieldNickname), "field to sort the providers by")
+	desc := fs.Bool("desc", false, "sort in descending order")
+	out := fs.String("out", "", "output file, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := registry.ExportOptions{
+		SortBy:     registry.ExportField(*sortBy),
+		Descending: *desc,
+	}
+	if *fields != "" {
+		parsed, err := registry.ParseExportFields(*fields)
+		if err != nil {
+			return err
+		}
+		opts.Fields = parsed
+	}
+
+	var export func(io.Writer, []*registry.Entry, registry.ExportOptions) error
+	switch *format {
+	case "csv":
+		export = registry.ExportCSV
+	case "json":
+		export = registry.ExportJSON
+	case "markdown", "md":
+		export = registry.ExportMarkdown
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	entries, err := registry.LoadCurrentRegistry(*fpDir)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return export(w, entries, opts)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/parser"
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+func runInspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	entryFile := fs.String("entry", "", "registry file whose deposit is inspected, also checking its finality provider key")
+	txFile := fs.String("tx-file", "", "file holding the hex transaction, instead of the argument")
+	paramsFile := fs.String("params", "", "global params file to check a staking tx against instead of the deposit policy")
+	height := fs.Uint64("height", 0, "btc height of the staking tx, selecting the version of the global params")
+	version := fs.Int64("version", -1, "version of the global params, instead of --height")
+	network := fs.String("network", chaincfg.SigNetParams.Name, "bitcoin network of the addresses")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fpregistry inspect [flags] [<tx hex>]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	net, err := spv.NetParamsByName(*network)
+	if err != nil {
+		return err
+	}
+
+	tx, entry, err := readInspectedTx(*entryFile, *txFile, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	var policy *btcstaking.StakingTxPolicy
+	if *paramsFile != "" {
+		params, err := parser.NewParsedGlobalParamsFromFile(*paramsFile)
+		if err != nil {
+			return err
+		}
+		versioned, err := selectVersion(params, *height, *version)
+		if err != nil {
+			return err
+		}
+		policy = versioned.StakingTxPolicy()
+	} else {
+		policy, err = registry.DepositPolicy(nil)
+		if err != nil {
+			return err
+		}
+	}
+	if entry != nil {
+		// only the key is parsed, the deposit of an entry failing validation
+		// for other reasons can still be inspected
+		pkBytes, err := hex.DecodeString(entry.FinalityProvider.BtcPk)
+		if err != nil {
+			return fmt.Errorf("'%s': invalid btc_pk: %w", entry.Nickname, err)
+		}
+		policy.FpKey, err = schnorr.ParsePubKey(pkBytes)
+		if err != nil {
+			return fmt.Errorf("'%s': invalid btc_pk: %w", entry.Nickname, err)
+		}
+	}
+
+	inspection := btcstaking.InspectStakingTx(tx, policy, net)
+	printInspection(inspection, policy)
+
+	if !inspection.Pass() {
+		return fmt.Errorf("tx %s does not match the %s", inspection.TxHash, policy.Name)
+	}
+	return nil
+}
+
+func readInspectedTx(entryFile, txFile string, args []string) (*wire.MsgTx, *registry.Entry, error) {
+	if entryFile != "" {
+		entry, err := registry.NewEntryFromFile(entryFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		deposit, err := registry.ParseDeposit(&entry.FinalityProvider.Deposit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("'%s': %w", entry.Nickname, err)
+		}
+		return deposit.Tx, entry, nil
+	}
+
+	var txHex string
+	switch {
+	case txFile != "":
+		data, err := os.ReadFile(txFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		txHex = string(data)
+	case len(args) == 1:
+		txHex = args[0]
+	default:
+		return nil, nil, fmt.Errorf("expected a tx hex, --tx-file or --entry")
+	}
+
+	txBytes, err := hex.DecodeString(strings.TrimSpace(txHex))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tx hex: %w", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, nil, fmt.Errorf("invalid tx: %w", err)
+	}
+	return &tx, nil, nil
+}
+
+func selectVersion(params *parser.ParsedGlobalParams, height uint64, version int64) (*parser.ParsedVersionedGlobalParams, error) {
+	if version >= 0 {
+		return params.SelectVersion(version)
+	}
+	if height == 0 {
+		return nil, fmt.Errorf("--params needs --height or --version")
+	}
+
+	versioned := params.GetVersionedGlobalParamsByHeight(height)
+	if versioned == nil {
+		return nil, fmt.Errorf("no global params are active at height %d", height)
+	}
+	return versioned, nil
+}
+
+func printInspection(i *btcstaking.Inspection, policy *btcstaking.StakingTxPolicy) {
+	fmt.Printf("tx %s version %d locktime %d\n", i.TxHash, i.Version, i.LockTime)
+
+	fmt.Printf("inputs:\n")
+	for idx, in := range i.Inputs {
+		fmt.Printf("  %d: %s sequence %#x, %d witness items\n", idx, in.PreviousOutPoint, in.Sequence, in.WitnessItems)
+	}
+
+	fmt.Printf("outputs:\n")
+	for _, out := range i.Outputs {
+		var notes []string
+		if out.Address != "" {
+			notes = append(notes, out.Address)
+		}
+		switch {
+		case out.IsStaking:
+			notes = append(notes, "taproot staking output")
+		case out.IsOpReturn:
+			notes = append(notes, "OP_RETURN "+hex.EncodeToString(out.PkScript))
+		}
+		fmt.Printf("  %d: %s %s %s\n", out.Index, out.Value, out.Class, strings.Join(notes, ", "))
+	}
+
+	if d := i.OpReturnData; d != nil {
+		fmt.Printf("OP_RETURN data:\n")
+		fmt.Printf("  tag:          %s\n", btcstaking.TagString(d.Tag))
+		fmt.Printf("  version:      %d\n", d.Version)
+		fmt.Printf("  staker pk:    %x\n", schnorr.SerializePubKey(d.StakerKey))
+		fmt.Printf("  fp pk:        %x\n", schnorr.SerializePubKey(d.FpKey))
+		fmt.Printf("  staking time: %d blocks\n", d.StakingTime)
+	}
+
+	fmt.Printf("checks against the %s:\n", policy.Name)
+	for _, c := range i.Checks {
+		if c.Pass {
+			fmt.Printf("  ✅ %s: %s\n", c.Field, c.Value)
+		} else {
+			fmt.Printf("  ❌ %s: %s, expected %s\n", c.Field, c.Value, c.Expected)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// fpregistry is a set of tools to verify and work with the finality
+// provider information registry.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "validate", usage: "validate registry entries", run: runValidate},
+	{name: "sign", usage: "write and sign a registry entry", run: runSign},
+	{name: "update", usage: "write and sign an update or tombstone record of an entry", run: runUpdate},
+	{name: "message", usage: "print the message to sign with a wallet and its address", run: runMessage},
+	{name: "changed", usage: "list the registry entries changed between two revisions", run: runChanged},
+	{name: "verify-onchain", usage: "verify the deposits of registry entries on chain", run: runVerifyOnChain},
+	{name: "verify-spv", usage: "verify the deposits of registry entries with their spv proof", run: runVerifySpv},
+	{name: "inspect", usage: "decode a deposit or staking tx and check it against its policy", run: runInspect},
+	{name: "unlocks", usage: "list the deposits by time remaining before they unlock", run: runUnlocks},
+	{name: "withdraw", usage: "build the unsigned withdrawal tx of a deposit", run: runWithdraw},
+	{name: "export", usage: "export the registry to csv, json or markdown", run: runExport},
+	{name: "snapshot", usage: "build the merkle snapshot of the registry", run: runSnapshot},
+	{name: "prove", usage: "emit the inclusion proof of a finality provider", run: runProve},
+	{name: "verify-proof", usage: "verify an entry against a registry root", run: runVerifyProof},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: fpregistry <command> [flags]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+func runMessage(args []string) error {
+	fs := flag.NewFlagSet("message", flag.ExitOnError)
+	network := fs.String("network", chaincfg.SigNetParams.Name, "bitcoin network of the address, e.g. signet or mainnet")
+	scheme := fs.String("scheme", string(registry.Bip322SimpleScheme), "signature scheme of the message")
+	out := fs.String("out", "", "file to write the exact message to, printed if not set")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fpregistry message [flags] <registry file>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one registry file")
+	}
+
+	params, err := spv.NetParamsByName(*network)
+	if err != nil {
+		return err
+	}
+
+	entry, err := registry.NewEntryFromFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// registry files are stored under <fp-dir>/registry
+	fpNetwork, err := registry.NetworkFromFpDir(filepath.Dir(filepath.Dir(entry.Path)))
+	if err != nil {
+		return err
+	}
+	message, err := registry.SignedData(registry.SignatureScheme(*scheme), fpNetwork, entry)
+	if err != nil {
+		return err
+	}
+
+	pkBytes, err := hex.DecodeString(entry.FinalityProvider.BtcPk)
+	if err != nil {
+		return fmt.Errorf("invalid btc_pk: %w", err)
+	}
+	btcPk, err := schnorr.ParsePubKey(pkBytes)
+	if err != nil {
+		return fmt.Errorf("invalid btc_pk: %w", err)
+	}
+	address, err := btcutil.NewAddressTaproot(
+		schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(btcPk)), params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("finality provider: '%s'\n", entry.Nickname)
+	fmt.Printf("taproot address:   %s\n", address.EncodeAddress())
+	fmt.Printf("signature file:    %s:<signature>\n", *scheme)
+	if *out != "" {
+		if err := os.WriteFile(*out, message, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("message written to %s\n", *out)
+		return nil
+	}
+	fmt.Printf("message:\n%s\n", message)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+type backendFlags struct {
+	backend    *string
+	esploraURL *string
+	rpcURL     *string
+	rpcUser    *string
+	rpcPass    *string
+	timeout    *time.Duration
+}
+
+func addBackendFlags(fs *flag.FlagSet) *backendFlags {
+	return &backendFlags{
+		backend:    fs.String("backend", "esplora", "bitcoin backend, one of esplora or bitcoind"),
+		esploraURL: fs.String("esplora-url", "https://mempool.space/signet/api", "esplora REST API url"),
+		rpcURL:     fs.String("rpc-url", "http://127.0.0.1:38332", "bitcoind JSON-RPC url"),
+		rpcUser:    fs.String("rpc-user", "", "bitcoind JSON-RPC user"),
+		rpcPass:    fs.String("rpc-pass", "", "bitcoind JSON-RPC password"),
+		timeout:    fs.Duration("timeout", 30*time.Second, "timeout of each request to the backend"),
+	}
+}
+
+func (f *backendFlags) newBackend() (btcbackend.ChainBackend, error) {
+	client := &http.Client{Timeout: *f.timeout}
+	switch *f.backend {
+	case "esplora":
+		return btcbackend.NewEsploraBackend(*f.esploraURL, client), nil
+	case "bitcoind":
+		return btcbackend.NewBitcoindBackend(*f.rpcURL, *f.rpcUser, *f.rpcPass, client), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q", *f.backend)
+	}
+}
+
+func runVerifyOnChain(args []string) error {
+	fs := flag.NewFlagSet("verify-onchain", flag.ExitOnError)
+	bf := addBackendFlags(fs)
+	minConfirmations := fs.Uint64("min-confirmations", registry.DefaultDepositConfirmations, "minimum confirmations of the deposit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	backend, err := bf.newBackend()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, f := range fs.Args() {
+		entry, err := registry.NewEntryFromFile(f)
+		if err != nil {
+			return err
+		}
+
+		deposit, err := registry.ParseDeposit(&entry.FinalityProvider.Deposit)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", entry.Nickname, err)
+		}
+
+		if err := registry.VerifyDepositOnChain(ctx, backend, deposit, *minConfirmations); err != nil {
+			return fmt.Errorf("'%s': %w", entry.Nickname, err)
+		}
+		fmt.Printf("✅ '%s' is a valid fp onchain-registration\n", entry.Nickname)
+	}
+
+	return nil
+}
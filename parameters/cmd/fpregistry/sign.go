@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "bbn-test-4/finality-providers", "finality providers directory")
+	nickname := fs.String("nickname", "", "nickname of the finality provider, i.e. the name of its registry file")
+	moniker := fs.String("moniker", "", "moniker of the finality provider")
+	identity := fs.String("identity", "", "optional keybase identity")
+	website := fs.String("website", "", "optional website")
+	securityContact := fs.String("security-contact", "", "security contact email")
+	details := fs.String("details", "", "optional details")
+	btcPk := fs.String("btc-pk", "", "hex BTC public key of the finality provider, derived from --key-file if not set")
+	commission := fs.String("commission", "", "commission as a decimal, e.g. 0.1 for 10%")
+	depositTx := fs.String("deposit-tx", "", "hex signed deposit transaction")
+	keyFile := fs.String("key-file", "", "file holding the hex or WIF private key of the finality provider")
+	scheme := fs.String("scheme", string(registry.RawScheme),
+		fmt.Sprintf("signature scheme: %s signs the file bytes, %s signs the canonical entry for the network", registry.RawScheme, registry.CanonicalV1Scheme))
+	force := fs.Bool("force", false, "overwrite an existing entry")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fpregistry sign [flags] [-- <signer command...>]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	signer, signerPk, err := newSigner(*keyFile, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *btcPk == "" {
+		*btcPk = signerPk
+	} else if signerPk != "" && !strings.EqualFold(*btcPk, signerPk) {
+		return fmt.Errorf("--btc-pk %s is not the public key %s of --key-file", *btcPk, signerPk)
+	}
+
+	txBytes, err := hex.DecodeString(*depositTx)
+	if err != nil {
+		return fmt.Errorf("invalid --deposit-tx: %w", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return fmt.Errorf("invalid --deposit-tx: %w", err)
+	}
+
+	fp := &registry.FinalityProvider{
+		Description: registry.Description{
+			Moniker:         *moniker,
+			Identity:        *identity,
+			Website:         *website,
+			SecurityContact: *securityContact,
+			Details:         *details,
+		},
+		BtcPk:      *btcPk,
+		Commission: *commission,
+		Deposit: registry.Deposit{
+			TxHash:   tx.TxHash().String(),
+			SignedTx: *depositTx,
+		},
+	}
+
+	entryPath := filepath.Join(*fpDir, registry.RegistryDirName, *nickname+".json")
+	if _, err := os.Stat(entryPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", entryPath)
+	}
+
+	entry, err := registry.WriteSignedEntry(*fpDir, *nickname, fp, registry.SignatureScheme(*scheme), signer)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ '%s' written to %s and signed in %s (%s)\n",
+		entry.Nickname, entry.Path, registry.SignaturePath(*fpDir, entry.Nickname), *scheme)
+	return nil
+}
+
+// newSigner returns the signer of the --key-file flag or of the signer command
+// given as arguments after --, with its hex public key if it is known. The
+// arguments of the command are passed as is, without splitting or quoting.
+func newSigner(keyFile string, signerCmd []string) (registry.Signer, string, error) {
+	switch {
+	case keyFile != "" && len(signerCmd) > 0:
+		return nil, "", fmt.Errorf("only one of --key-file and a signer command can be set")
+	case keyFile != "":
+		keySigner, err := registry.NewKeySignerFromFile(keyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return keySigner, hex.EncodeToString(schnorr.SerializePubKey(keySigner.PublicKey())), nil
+	case len(signerCmd) > 0:
+		return registry.NewCommandSigner(signerCmd[0], signerCmd[1:]...), "", nil
+	default:
+		return nil, "", fmt.Errorf("either --key-file or a signer command after -- should be set")
+	}
+}
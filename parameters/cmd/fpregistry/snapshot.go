@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/snapshot"
+)
+
+func writeJSON(filePath string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if filePath == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
+
+func readJSON(filePath string, v interface{}) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func loadSnapshot(fpDir string) (*snapshot.Snapshot, error) {
+	entries, err := registry.LoadCurrentRegistry(fpDir)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.NewSnapshot(entries)
+}
+
+func runSnapshot(args []string) error {
+	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "bbn-test-4/finality-providers", "finality providers directory")
+	write := fs.Bool("write", false, "publish the snapshot in the finality providers directory instead of printing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := loadSnapshot(*fpDir)
+	if err != nil {
+		return err
+	}
+
+	out := ""
+	if *write {
+		out = filepath.Join(*fpDir, snapshot.SnapshotFileName)
+	}
+	return writeJSON(out, s.Info())
+}
+
+func runProve(args []string) error {
+	fs := flag.NewFlagSet("prove", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "bbn-test-4/finality-providers", "finality providers directory")
+	btcPk := fs.String("btc-pk", "", "BTC public key of the finality provider to prove")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	pk, err := hex.DecodeString(*btcPk)
+	if err != nil {
+		return fmt.Errorf("invalid --btc-pk: %w", err)
+	}
+
+	s, err := loadSnapshot(*fpDir)
+	if err != nil {
+		return err
+	}
+
+	proof, err := s.Prove(pk)
+	if err != nil {
+		return err
+	}
+	return writeJSON("", proof)
+}
+
+func runVerifyProof(args []string) error {
+	fs := flag.NewFlagSet("verify-proof", flag.ExitOnError)
+	root := fs.String("root", "", "known registry root as hex")
+	entryPath := fs.String("entry", "", "registry entry to verify")
+	proofPath := fs.String("proof", "", "inclusion proof of the entry")
+	fpDir := fs.String("fp-dir", "", "finality providers directory whose update records are applied to the entry, none by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rootBytes, err := hex.DecodeString(*root)
+	if err != nil {
+		return fmt.Errorf("invalid --root: %w", err)
+	}
+
+	entry, err := registry.NewEntryFromFile(*entryPath)
+	if err != nil {
+		return err
+	}
+	fp := entry.FinalityProvider
+	if *fpDir != "" {
+		resolved, err := registry.ResolveEntry(*fpDir, entry)
+		if err != nil {
+			return err
+		}
+		if resolved.Retired {
+			return fmt.Errorf("'%s' is retired, it is not committed by snapshots", entry.Nickname)
+		}
+		fp = resolved.State
+	}
+
+	var proof snapshot.Proof
+	if err := readJSON(*proofPath, &proof); err != nil {
+		return err
+	}
+
+	if err := snapshot.VerifyProof(rootBytes, fp, &proof); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ '%s' is committed by root %s\n", fp.Description.Moniker, *root)
+	return nil
+}
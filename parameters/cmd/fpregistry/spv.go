@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func runVerifySpv(args []string) error {
+	fs := flag.NewFlagSet("verify-spv", flag.ExitOnError)
+	minConfirmations := fs.Uint64("min-confirmations", registry.DefaultDepositConfirmations, "minimum confirmations of the deposit")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fpregistry verify-spv [flags] <registry files...>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	for _, f := range fs.Args() {
+		entry, err := registry.NewEntryFromFile(f)
+		if err != nil {
+			return err
+		}
+
+		// registry files are stored under <fp-dir>/registry
+		fpDir := filepath.Dir(filepath.Dir(entry.Path))
+		proof, err := registry.LoadSpvProof(fpDir, entry)
+		if err != nil {
+			return err
+		}
+		if proof == nil {
+			return fmt.Errorf("'%s' has no spv proof of its deposit", entry.Nickname)
+		}
+
+		deposit, err := registry.ParseDeposit(&entry.FinalityProvider.Deposit)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", entry.Nickname, err)
+		}
+
+		// the anchor and the bitcoin network are the ones of the descriptor of
+		// the network of the entry
+		n, err := registry.LoadFpNetwork(fpDir)
+		if err != nil {
+			return err
+		}
+		verified, err := registry.VerifyDepositSpv(deposit, proof, n.BtcAnchor, n.BtcNetwork, *minConfirmations)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", entry.Nickname, err)
+		}
+		fmt.Printf("✅ '%s' deposit is in block %s at height %d with %d confirmations\n",
+			entry.Nickname, verified.BlockHash, verified.BlockHeight, verified.Confirmations)
+	}
+
+	return nil
+}
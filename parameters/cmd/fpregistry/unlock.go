@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/networks"
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+// blockInterval is the expected time between two blocks, used to estimate
+// the time remaining before a deposit unlocks
+const blockInterval = 10 * time.Minute
+
+// confirmationHeight returns the height of the block including the deposit of
+// the entry, and false if the deposit is not confirmed. Offline, the height is
+// counted from the anchor of the network its spv proof builds on, as the
+// height claimed by a proof cannot be trusted.
+func confirmationHeight(
+	ctx context.Context,
+	fpDir string,
+	e *registry.Entry,
+	deposit *registry.ParsedDeposit,
+	backend btcbackend.ChainBackend,
+	n *networks.ParsedNetwork,
+) (uint64, bool, error) {
+	if backend == nil {
+		proof, err := registry.LoadSpvProof(fpDir, e)
+		if err != nil || proof == nil {
+			return 0, false, err
+		}
+		verified, err := registry.VerifyDepositSpv(deposit, proof, n.BtcAnchor, n.BtcNetwork, 1)
+		if err != nil {
+			return 0, false, err
+		}
+		return verified.BlockHeight, true, nil
+	}
+
+	info, err := backend.GetTransaction(ctx, &deposit.TxHash)
+	if errors.Is(err, btcbackend.ErrTxNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return info.BlockHeight, info.Confirmed, nil
+}
+
+func runUnlocks(args []string) error {
+	fs := flag.NewFlagSet("unlocks", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "bbn-test-4/finality-providers", "finality providers directory")
+	tip := fs.Uint64("tip", 0, "height of the tip of the chain, taken from the backend if not set")
+	offline := fs.Bool("offline", false, "take the confirmation heights from the spv proofs of the deposits, anchored to the network descriptor, instead of the backend")
+	bf := addBackendFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *offline && *tip == 0 {
+		return fmt.Errorf("--tip is required with --offline")
+	}
+
+	ctx := context.Background()
+	var n *networks.ParsedNetwork
+	var backend btcbackend.ChainBackend
+	var err error
+	if *offline {
+		if n, err = registry.LoadFpNetwork(*fpDir); err != nil {
+			return err
+		}
+		if n.BtcAnchor == nil {
+			return fmt.Errorf("no btc anchor is pinned for %s, confirmation heights cannot be taken from spv proofs", n.ChainID)
+		}
+	} else {
+		backend, err = bf.newBackend()
+		if err != nil {
+			return err
+		}
+		if *tip == 0 {
+			if *tip, err = backend.GetTipHeight(ctx); err != nil {
+				return fmt.Errorf("failed to get tip height: %w", err)
+			}
+		}
+	}
+
+	// retired entries are kept, as their deposits stay locked for the whole
+	// time lock. The deposit of an entry cannot be updated.
+	resolved, err := registry.LoadResolvedRegistry(*fpDir)
+	if err != nil {
+		return err
+	}
+
+	var unlocks []*registry.DepositUnlock
+	for _, r := range resolved {
+		e := r.Entry
+		deposit, err := registry.ParseDeposit(&e.FinalityProvider.Deposit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️ '%s': %v\n", e.Nickname, err)
+			continue
+		}
+
+		height, confirmed, err := confirmationHeight(ctx, *fpDir, e, deposit, backend, n)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", e.Nickname, err)
+		}
+		if !confirmed {
+			fmt.Fprintf(os.Stderr, "⚠️ '%s': deposit %s has no known confirmation height\n", e.Nickname, deposit.TxHash)
+			continue
+		}
+		u := registry.NewDepositUnlock(e.Nickname, deposit.TxHash, height)
+		u.Retired = r.Retired
+		unlocks = append(unlocks, u)
+	}
+	registry.SortDepositUnlocks(unlocks)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NICKNAME\tSTATUS\tDEPOSIT TX\tCONFIRMED AT\tUNLOCKS AT\tBLOCKS REMAINING\tESTIMATED TIME\n")
+	for _, u := range unlocks {
+		remaining := u.BlocksRemaining(*tip)
+		estimate := "unlocked"
+		if remaining > 0 {
+			estimate = (time.Duration(remaining) * blockInterval).String()
+		}
+		status := "active"
+		if u.Retired {
+			status = "retired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+			u.Nickname, status, u.TxHash, u.ConfirmationHeight, u.UnlockHeight, remaining, estimate)
+	}
+	return w.Flush()
+}
+
+func runWithdraw(args []string) error {
+	fs := flag.NewFlagSet("withdraw", flag.ExitOnError)
+	entryFile := fs.String("entry", "", "registry file of the deposit to withdraw")
+	address := fs.String("address", "", "address receiving the deposit")
+	network := fs.String("network", chaincfg.SigNetParams.Name, "bitcoin network of the deposit")
+	feeRate := fs.Int64("fee-rate", 2, "fee rate in satoshis per virtual byte")
+	asPsbt := fs.Bool("psbt", false, "emit a base64 PSBT ready to be signed instead of the raw unsigned tx")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *entryFile == "" || *address == "" {
+		return fmt.Errorf("--entry and --address are required")
+	}
+	if *feeRate < 1 {
+		return fmt.Errorf("invalid --fee-rate %d, it should be at least 1 satoshi per virtual byte", *feeRate)
+	}
+
+	params, err := spv.NetParamsByName(*network)
+	if err != nil {
+		return err
+	}
+	addr, err := btcutil.DecodeAddress(*address, params)
+	if err != nil {
+		return fmt.Errorf("invalid --address: %w", err)
+	}
+	if !addr.IsForNet(params) {
+		return fmt.Errorf("address %s is not a %s address", *address, params.Name)
+	}
+	destination, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return err
+	}
+
+	entry, err := registry.NewEntryFromFile(*entryFile)
+	if err != nil {
+		return err
+	}
+	deposit, parsed, err := registry.ParseDepositStakingTx(entry.FinalityProvider)
+	if err != nil {
+		return fmt.Errorf("'%s': %w", entry.Nickname, err)
+	}
+
+	if *asPsbt {
+		packet, err := btcstaking.BuildTimeLockWithdrawalPsbt(deposit.Tx, parsed, destination, btcutil.Amount(*feeRate))
+		if err != nil {
+			return err
+		}
+		encoded, err := packet.B64Encode()
+		if err != nil {
+			return err
+		}
+		fmt.Println(encoded)
+		return nil
+	}
+
+	tx, err := btcstaking.BuildTimeLockWithdrawalTx(deposit.Tx, parsed, destination, btcutil.Amount(*feeRate))
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return err
+	}
+	fmt.Println(hex.EncodeToString(buf.Bytes()))
+	return nil
+}
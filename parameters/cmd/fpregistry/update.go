@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func runUpdate(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "bbn-test-4/finality-providers", "finality providers directory")
+	nickname := fs.String("nickname", "", "nickname of the finality provider to update")
+	sequence := fs.Uint64("sequence", 0, "sequence of the record, the next one if not set")
+	tombstone := fs.Bool("tombstone", false, "retire the entry instead of updating it")
+	moniker := fs.String("moniker", "", "new moniker")
+	identity := fs.String("identity", "", "new keybase identity")
+	website := fs.String("website", "", "new website")
+	securityContact := fs.String("security-contact", "", "new security contact email")
+	details := fs.String("details", "", "new details")
+	commission := fs.String("commission", "", "new commission as a decimal, e.g. 0.1 for 10%")
+	keyFile := fs.String("key-file", "", "file holding the hex or WIF private key of the finality provider")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fpregistry update [flags] [-- <signer command...>]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	signer, signerPk, err := newSigner(*keyFile, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	entry, err := registry.NewEntryFromFile(filepath.Join(*fpDir, registry.RegistryDirName, *nickname+".json"))
+	if err != nil {
+		return err
+	}
+	current, err := registry.ResolveEntry(*fpDir, entry)
+	if err != nil {
+		return err
+	}
+	if current.Retired {
+		return fmt.Errorf("'%s' is retired", *nickname)
+	}
+	if signerPk != "" && !strings.EqualFold(signerPk, current.State.BtcPk) {
+		return fmt.Errorf("the public key %s of --key-file is not the btc_pk %s of '%s'", signerPk, current.State.BtcPk, *nickname)
+	}
+
+	record := &registry.UpdateRecord{
+		Type:     registry.UpdateRecordType,
+		BtcPk:    current.State.BtcPk,
+		Sequence: *sequence,
+	}
+	if record.Sequence == 0 {
+		record.Sequence = current.Sequence + 1
+		// rejected records keep their sequence
+		for _, r := range current.Rejected {
+			if r.Record.Record.Sequence >= record.Sequence {
+				record.Sequence = r.Record.Record.Sequence + 1
+			}
+		}
+	}
+
+	// only the flags which are set change the entry
+	description := current.State.Description
+	descriptionChanged := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "moniker":
+			description.Moniker = *moniker
+		case "identity":
+			description.Identity = *identity
+		case "website":
+			description.Website = *website
+		case "security-contact":
+			description.SecurityContact = *securityContact
+		case "details":
+			description.Details = *details
+		case "commission":
+			record.Commission = *commission
+		default:
+			return
+		}
+		if f.Name != "commission" {
+			descriptionChanged = true
+		}
+	})
+	if descriptionChanged {
+		record.Description = &description
+	}
+	if *tombstone {
+		if record.Description != nil || record.Commission != "" {
+			return fmt.Errorf("--tombstone cannot be combined with changes")
+		}
+		record.Type = registry.TombstoneRecordType
+	}
+
+	recordPath := registry.UpdateRecordPath(*fpDir, *nickname, record.Sequence)
+	if _, err := os.Stat(recordPath); err == nil {
+		return fmt.Errorf("%s already exists", recordPath)
+	}
+
+	resolved, err := registry.WriteSignedUpdateRecord(*fpDir, *nickname, record, registry.CanonicalV1Scheme, signer)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ '%s' %s %d written to %s and signed in %s (%s)\n",
+		*nickname, record.Type, record.Sequence, recordPath,
+		registry.UpdateSignaturePath(*fpDir, *nickname, record.Sequence), registry.CanonicalV1Scheme)
+	if resolved.Retired {
+		fmt.Printf("'%s' is retired\n", *nickname)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fpDir := fs.String("fp-dir", "", "validate every entry of this finality providers directory instead of the given files")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: fpregistry validate [--fp-dir <dir>] [registry files...]\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var entries []*registry.Entry
+	if *fpDir != "" {
+		// also rejects the update records of unknown entries
+		loaded, err := registry.LoadResolvedRegistry(*fpDir)
+		if err != nil {
+			return err
+		}
+		for _, r := range loaded {
+			entries = append(entries, r.Entry)
+		}
+	}
+	for _, f := range fs.Args() {
+		entry, err := registry.NewEntryFromFile(f)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+	}
+
+	failed := 0
+	for _, e := range entries {
+		if _, err := registry.ParseFinalityProvider(e.FinalityProvider); err != nil {
+			fmt.Printf("❌ '%s': %v\n", e.Nickname, err)
+			failed++
+			continue
+		}
+		// registry files are stored under <fp-dir>/registry
+		entryFpDir := filepath.Dir(filepath.Dir(e.Path))
+		if err := registry.VerifyEntrySignature(entryFpDir, e); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed++
+			continue
+		}
+
+		resolved, err := registry.ResolveEntry(entryFpDir, e)
+		if err != nil {
+			fmt.Printf("❌ '%s': %v\n", e.Nickname, err)
+			failed++
+			continue
+		}
+		if len(resolved.Rejected) > 0 {
+			for _, r := range resolved.Rejected {
+				fmt.Printf("❌ %v\n", r.Err)
+			}
+			failed++
+			continue
+		}
+
+		switch {
+		case resolved.Retired:
+			fmt.Printf("✅ '%s' is a valid registry entry, retired at sequence %d\n", e.Nickname, resolved.Sequence)
+		case resolved.Sequence > 0:
+			fmt.Printf("✅ '%s' is a valid registry entry, updated to sequence %d\n", e.Nickname, resolved.Sequence)
+		default:
+			fmt.Printf("✅ '%s' is a valid registry entry\n", e.Nickname)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries are invalid", failed, len(entries))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+// stringList collects the values of a repeated flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runAvailability(args []string) error {
+	fs := flag.NewFlagSet("availability", flag.ExitOnError)
+	version := fs.Int64("version", -1, "params version, the last one by default")
+	quorum := fs.Uint("quorum", 0, "covenant quorum to evaluate instead of the one of the params")
+	committeeFile := fs.String("committee", "", "committee manifest naming the operators of the keys")
+	availability := fs.Float64("availability", 0.99, "availability probability of each member")
+	var memberAvailability, scenarios stringList
+	fs.Var(&memberAvailability, "member", "availability of the members of an operator or of a key, as <operator|key>=<probability>, repeatable")
+	fs.Var(&scenarios, "down", "failure scenario, as a comma separated list of operators or keys down, repeatable")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: globalparams availability [flags] <global-params.json>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one global params file")
+	}
+
+	params, err := parser.NewParsedGlobalParamsFromFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid global params %s: %w", fs.Arg(0), err)
+	}
+	versioned, err := params.SelectVersion(*version)
+	if err != nil {
+		return err
+	}
+	if *quorum > 0 {
+		proposed := *versioned
+		proposed.CovenantQuorum = uint32(*quorum)
+		versioned = &proposed
+	}
+
+	var committee *covenant.ParsedCommittee
+	if *committeeFile != "" {
+		committee, err = covenant.NewCommitteeFromFile(*committeeFile)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := covenant.NewAvailabilitySimulator(versioned, committee)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version %d: quorum of %d of %d members, the outage of %d members blocks unbonding\n",
+		versioned.Version, s.Quorum(), len(s.Members()), s.BlockingSize())
+
+	unattributed := s.Unattributed()
+	if committee == nil {
+		fmt.Printf("operators: not analyzed, no committee manifest given with --committee\n")
+	} else {
+		fmt.Printf("operators:\n")
+		for _, o := range s.Operators() {
+			if o.CanBlock {
+				fmt.Printf("  ❌ %s runs %d of the keys and can block unbonding on its own\n", o.Operator, len(o.Members))
+			} else {
+				fmt.Printf("  ✅ %s runs %d of the keys\n", o.Operator, len(o.Members))
+			}
+		}
+		if len(unattributed) > 0 {
+			fmt.Printf("  ⚠️ %d of the keys are not attributed to an operator, an operator running several of them may block unbonding on its own\n",
+				len(unattributed))
+		}
+	}
+
+	set, operators := s.MinimalBlockingSet()
+	if len(operators) > 0 {
+		fmt.Printf("minimal blocking set: %d members of %s\n", len(set), strings.Join(operators, ", "))
+	} else {
+		fmt.Printf("minimal blocking set: %d members\n", len(set))
+	}
+	for _, m := range set {
+		operator := m.Operator
+		if operator == "" {
+			operator = "unattributed"
+		}
+		fmt.Printf("  %x (%s)\n", m.CovenantPk.SerializeCompressed(), operator)
+	}
+
+	probabilities := make(map[string]float64)
+	for _, m := range s.Members() {
+		probabilities[hex.EncodeToString(m.CovenantPk.SerializeCompressed())] = *availability
+	}
+	for _, a := range memberAvailability {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("invalid --member %s, expected <operator|key>=<probability>", a)
+		}
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid --member %s: %w", a, err)
+		}
+		keys, err := resolveMembers(s, name)
+		if err != nil {
+			return err
+		}
+		for _, pk := range keys {
+			probabilities[hex.EncodeToString(pk.SerializeCompressed())] = p
+		}
+	}
+	success, err := s.SuccessProbability(probabilities)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("probability that unbonding succeeds with independent outages: %.6f\n", success)
+
+	if len(scenarios) > 0 {
+		fmt.Printf("scenarios:\n")
+	}
+	for _, names := range scenarios {
+		scenario := &covenant.Scenario{Name: names}
+		for _, name := range strings.Split(names, ",") {
+			keys, err := resolveMembers(s, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			scenario.Down = append(scenario.Down, keys...)
+		}
+
+		result, err := s.Run(scenario)
+		if err != nil {
+			return err
+		}
+		if result.Succeeds {
+			fmt.Printf("  ✅ %s down: %d members available, unbonding succeeds\n", names, result.Available)
+		} else {
+			fmt.Printf("  ❌ %s down: %d members available, unbonding is blocked\n", names, result.Available)
+		}
+	}
+
+	// the operators of unattributed keys are unknown, so whether one of them
+	// can block unbonding on its own is not known either
+	if committee != nil && len(unattributed) > 0 {
+		return fmt.Errorf("the operator analysis is incomplete, %d of the %d keys are not attributed to an operator in %s",
+			len(unattributed), len(s.Members()), *committeeFile)
+	}
+	return nil
+}
+
+// resolveMembers returns the keys of the members of an operator, or the key
+// given in hex
+func resolveMembers(s *covenant.AvailabilitySimulator, name string) ([]*btcec.PublicKey, error) {
+	var keys []*btcec.PublicKey
+	for _, m := range s.Members() {
+		if m.Operator == name || hex.EncodeToString(m.CovenantPk.SerializeCompressed()) == name {
+			keys = append(keys, m.CovenantPk)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s is neither an operator nor a covenant key of the committee", name)
+	}
+	return keys, nil
+}
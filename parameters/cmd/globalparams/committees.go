@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func runCommittees(args []string) error {
+	fs := flag.NewFlagSet("committees", flag.ExitOnError)
+	committeeFile := fs.String("committee", "", "committee manifest naming the operators of the current keys")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: globalparams committees [--committee <committee.json>] <global-params.json>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one global params file")
+	}
+
+	params, err := parser.NewParsedGlobalParamsFromFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid global params %s: %w", fs.Arg(0), err)
+	}
+
+	var committee *covenant.ParsedCommittee
+	if *committeeFile != "" {
+		committee, err = covenant.NewCommitteeFromFile(*committeeFile)
+		if err != nil {
+			return err
+		}
+	}
+	describe := func(pk *btcec.PublicKey) string {
+		s := fmt.Sprintf("%x", pk.SerializeCompressed())
+		if committee != nil {
+			if m := committee.MemberByKey(pk); m != nil && m.Operator != "" {
+				s += " (" + m.Operator + ")"
+			}
+		}
+		return s
+	}
+
+	report := covenant.CommitteeRotations(params)
+
+	fmt.Printf("changes:\n")
+	for _, c := range report.Changes {
+		if !c.Changed() {
+			fmt.Printf("  version %d -> %d: unchanged\n", c.FromVersion, c.ToVersion)
+			continue
+		}
+		fmt.Printf("  version %d -> %d at height %d: %d added, %d removed, %d kept, quorum %d -> %d\n",
+			c.FromVersion, c.ToVersion, c.ActivationHeight, len(c.Added), len(c.Removed), len(c.Kept), c.OldQuorum, c.NewQuorum)
+		for _, pk := range c.Added {
+			fmt.Printf("    + %s\n", describe(pk))
+		}
+		for _, pk := range c.Removed {
+			fmt.Printf("    - %s\n", describe(pk))
+		}
+	}
+
+	fmt.Printf("committees:\n")
+	for i, p := range report.Periods {
+		versions := make([]string, len(p.Versions))
+		for j, v := range p.Versions {
+			versions[j] = fmt.Sprint(v)
+		}
+
+		if p.Current() {
+			fmt.Printf("  %d: %d of %d keys, versions %s, staking from height %d, current committee\n",
+				i, p.Quorum, len(p.Keys), strings.Join(versions, ", "), p.FirstHeight)
+		} else {
+			fmt.Printf("  %d: %d of %d keys, versions %s, staking at heights %d to %d, governs unbonding until height %d\n",
+				i, p.Quorum, len(p.Keys), strings.Join(versions, ", "), p.FirstHeight, p.LastHeight, p.UnbondingUntil)
+		}
+		for _, pk := range p.Keys {
+			fmt.Printf("    %s\n", describe(pk))
+		}
+	}
+
+	return nil
+}
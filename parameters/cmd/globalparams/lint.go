@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	strict := fs.Bool("strict", false, "fail if there is any warning")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: globalparams lint [--strict] <global-params.json files...>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	total := 0
+	for _, f := range fs.Args() {
+		params, err := parser.NewParsedGlobalParamsFromFile(f)
+		if err != nil {
+			return fmt.Errorf("invalid global params %s: %w", f, err)
+		}
+
+		warnings := parser.LintGlobalParams(params)
+		for _, w := range warnings {
+			fmt.Printf("⚠️ %s: %s\n", f, w)
+		}
+		if len(warnings) == 0 {
+			fmt.Printf("✅ %s has no warnings\n", f)
+		}
+		total += len(warnings)
+	}
+
+	if *strict && total > 0 {
+		return fmt.Errorf("%d warnings", total)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// globalparams is a set of tools to check and work with the global params
+// of a network.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "lint", usage: "warn about suspicious global params", run: runLint},
+	{name: "committees", usage: "report the covenant committee changes across versions", run: runCommittees},
+	{name: "availability", usage: "simulate the availability of the covenant quorum", run: runAvailability},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: globalparams <command> [flags]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/babylonchain/networks/parameters/networks"
+)
+
+// stringList collects the values of a repeated flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// loadNetwork loads the network of the single argument from the catalog
+func loadNetwork(fs *flag.FlagSet, rootDir string) (*networks.ParsedNetwork, error) {
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return nil, fmt.Errorf("expected one network name")
+	}
+	catalog, err := networks.LoadCatalog(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return catalog.Network(fs.Arg(0))
+}
+
+func runList(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	rootDir := fs.String("root", ".", "root directory of the repository")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	catalog, err := networks.LoadCatalog(*rootDir)
+	if err != nil {
+		return err
+	}
+	for _, n := range catalog.Networks {
+		if n.LockOnly {
+			fmt.Printf("%s: lock-only, btc %s\n", n.ChainID, n.BtcNetwork.Name)
+			continue
+		}
+		fmt.Printf("%s: babylon %s, btc %s\n", n.ChainID, n.BabylonVersion, n.BtcNetwork.Name)
+		for _, p := range n.Providers {
+			fmt.Printf("  %s: %d seeds, %d rpc, %d grpc, %d lcd\n", p.Name, len(p.Seeds), len(p.RPC), len(p.GRPC), len(p.LCD))
+		}
+	}
+	return nil
+}
+
+func runP2PConfig(args []string) error {
+	fs := flag.NewFlagSet("p2p-config", flag.ExitOnError)
+	rootDir := fs.String("root", ".", "root directory of the repository")
+	var seedProviders stringList
+	fs.Var(&seedProviders, "provider", "provider whose seeds are used, repeatable, all providers by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: networks p2p-config [flags] <network>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := loadNetwork(fs, *rootDir)
+	if err != nil {
+		return err
+	}
+	seeds, err := n.SelectProviders(seedProviders)
+	if err != nil {
+		return err
+	}
+	return networks.WriteP2PConfig(os.Stdout, n, seeds)
+}
+
+func runClientConfig(args []string) error {
+	fs := flag.NewFlagSet("client-config", flag.ExitOnError)
+	rootDir := fs.String("root", ".", "root directory of the repository")
+	provider := fs.String("provider", "", "provider whose endpoints are used, the first one serving rpc and grpc by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: networks client-config [flags] <network>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := loadNetwork(fs, *rootDir)
+	if err != nil {
+		return err
+	}
+	c, err := n.ClientConfig(*provider)
+	if err != nil {
+		return err
+	}
+	return c.Write(os.Stdout)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/babylonchain/networks/parameters/networks"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func runGenesis(args []string) error {
+	fs := flag.NewFlagSet("genesis", flag.ExitOnError)
+	rootDir := fs.String("root", ".", "root directory of the repository")
+	archive := fs.String("archive", "", "genesis archive to verify instead of the one of the repository, e.g. a downloaded copy")
+	globalParams := fs.String("global-params", "", "global params the btcstaking params of the genesis should agree with")
+	version := fs.Int64("version", -1, "version of the global params, the last one by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: networks genesis [flags] <network>\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := loadNetwork(fs, *rootDir)
+	if err != nil {
+		return err
+	}
+
+	var g *networks.Genesis
+	if *archive == "" {
+		g, err = networks.VerifyGenesis(filepath.Join(*rootDir, n.ChainID), n)
+	} else {
+		g, err = verifyArchive(*archive, n)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return fmt.Errorf("genesis of %s could not be verified", n.ChainID)
+	}
+
+	fmt.Printf("✅ genesis archive sha256 %x\n", g.ArchiveSha256)
+	fmt.Printf("chain id: %s\n", g.ChainID)
+	fmt.Printf("genesis time: %s\n", g.GenesisTime.Format(time.RFC3339))
+	fmt.Printf("app: %s %s\n", g.AppName, g.AppVersion)
+
+	fmt.Printf("validators:\n")
+	for _, v := range g.Validators {
+		fmt.Printf("  %s %s\n    consensus key %s, self delegation %s, commission %s\n",
+			v.Moniker, v.OperatorAddress, v.ConsensusPubKey, v.SelfDelegation, v.CommissionRate)
+		if v.NodeAddress != "" {
+			fmt.Printf("    node %s\n", v.NodeAddress)
+		}
+	}
+
+	p := g.BtcStakingParams
+	fmt.Printf("btcstaking params:\n")
+	fmt.Printf("  covenant quorum: %d of %d\n", p.CovenantQuorum, len(p.CovenantPks))
+	for _, pk := range p.CovenantPks {
+		fmt.Printf("    %s\n", pk)
+	}
+	fmt.Printf("  slashing address: %s\n", p.SlashingAddress)
+	fmt.Printf("  min slashing tx fee: %d sat\n", p.MinSlashingTxFeeSat)
+	fmt.Printf("  min commission rate: %s\n", p.MinCommissionRate)
+	fmt.Printf("  slashing rate: %s\n", p.SlashingRate)
+	fmt.Printf("  max active finality providers: %d\n", p.MaxActiveFinalityProviders)
+	fmt.Printf("  min unbonding time: %d blocks\n", p.MinUnbondingTime)
+
+	c := g.BtcCheckpointParams
+	fmt.Printf("btccheckpoint params:\n")
+	fmt.Printf("  btc confirmation depth: %d\n", c.BtcConfirmationDepth)
+	fmt.Printf("  checkpoint finalization timeout: %d\n", c.CheckpointFinalizationTimeout)
+	fmt.Printf("  checkpoint tag: %s\n", c.CheckpointTag)
+
+	if *globalParams == "" {
+		return nil
+	}
+	return compareGlobalParams(g, *globalParams, *version)
+}
+
+func compareGlobalParams(g *networks.Genesis, globalParamsFile string, version int64) error {
+	params, err := parser.NewParsedGlobalParamsFromFile(globalParamsFile)
+	if err != nil {
+		return fmt.Errorf("invalid global params %s: %w", globalParamsFile, err)
+	}
+	versioned, err := params.SelectVersion(version)
+	if err != nil {
+		return err
+	}
+
+	diffs, err := networks.CompareStakingParams(g.BtcStakingParams, versioned)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Printf("✅ btcstaking params agree with version %d of the global params, unbonding_time >= the genesis minimum\n",
+			versioned.Version)
+		return nil
+	}
+	for _, d := range diffs {
+		if d.AtLeast {
+			fmt.Printf("❌ %s is %s in version %d of the global params, it should be at least %s %s of the genesis\n",
+				d.GlobalParamsField, d.GlobalParams, versioned.Version, d.GenesisField, d.Genesis)
+			continue
+		}
+		fmt.Printf("❌ %s is %s in version %d of the global params, %s is %s in the genesis\n",
+			d.GlobalParamsField, d.GlobalParams, versioned.Version, d.GenesisField, d.Genesis)
+	}
+	return fmt.Errorf("%d btcstaking params of the genesis differ from the global params", len(diffs))
+}
+
+func verifyArchive(archive string, n *networks.ParsedNetwork) (*networks.Genesis, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return networks.VerifyGenesisArchive(f, n)
+}
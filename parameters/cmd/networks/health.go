@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/health"
+)
+
+func runHealth(args []string) error {
+	flags := flag.NewFlagSet("health", flag.ExitOnError)
+	rootDir := flags.String("root", ".", "root directory of the repository")
+	timeout := flags.Duration("timeout", health.DefaultTimeout, "timeout of the probe of each endpoint")
+	var kinds stringList
+	flags.Var(&kinds, "kind", "kind of endpoint to probe, rpc, grpc, lcd or signer, repeatable, all kinds by default")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: networks health [flags] <network>\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	n, err := loadNetwork(flags, *rootDir)
+	if err != nil {
+		return err
+	}
+
+	targets := health.NetworkTargets(n)
+	committee, err := covenant.LoadCommittee(filepath.Join(*rootDir, n.ChainID))
+	switch {
+	case err == nil:
+		targets = append(targets, health.CommitteeTargets(committee)...)
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	targets, err = filterKinds(targets, kinds)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%s has no endpoints to probe", n.ChainID)
+	}
+
+	checker := health.NewChecker(nil)
+	checker.Timeout = *timeout
+	unhealthy, err := health.WriteReport(os.Stdout, checker.Check(context.Background(), targets))
+	if err != nil {
+		return err
+	}
+	if unhealthy > 0 {
+		return fmt.Errorf("%d endpoints of %s are unhealthy", unhealthy, n.ChainID)
+	}
+	return nil
+}
+
+func filterKinds(targets []*health.Target, kinds []string) ([]*health.Target, error) {
+	if len(kinds) == 0 {
+		return targets, nil
+	}
+	selected := make(map[health.Kind]bool)
+	for _, k := range kinds {
+		switch kind := health.Kind(k); kind {
+		case health.KindRPC, health.KindGRPC, health.KindLCD, health.KindSigner:
+			selected[kind] = true
+		default:
+			return nil, fmt.Errorf("unknown endpoint kind %q", k)
+		}
+	}
+
+	var filtered []*health.Target
+	for _, t := range targets {
+		if selected[t.Kind] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,49 @@
+// networks is a set of tools to work with the network catalog of the
+// repository.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "list", usage: "list the networks and their providers", run: runList},
+	{name: "p2p-config", usage: "render the p2p section of the config.toml of a node", run: runP2PConfig},
+	{name: "client-config", usage: "render the client config of a network", run: runClientConfig},
+	{name: "genesis", usage: "verify the genesis archive of a network against its pinned hash", run: runGenesis},
+	{name: "health", usage: "probe the endpoints of a network and of its covenant committee", run: runHealth},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: networks <command> [flags]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
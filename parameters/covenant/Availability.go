@@ -0,0 +1,209 @@
+package covenant
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+// OperatorShare are the members of the committee run by an operator
+type OperatorShare struct {
+	Operator string
+	Members  []*ParsedMember
+	// CanBlock is true if the outage of the operator alone leaves fewer
+	// members than the quorum
+	CanBlock bool
+}
+
+// Scenario is a set of members down at the same time
+type Scenario struct {
+	Name string
+	Down []*btcec.PublicKey
+}
+
+// ScenarioResult tells whether unbonding succeeds in a scenario
+type ScenarioResult struct {
+	Scenario  *Scenario
+	Available int
+	Succeeds  bool
+}
+
+// AvailabilitySimulator evaluates how robust unbonding is against outages of
+// the members of the committee of a params version, i.e. whether enough
+// members remain to reach the covenant quorum
+type AvailabilitySimulator struct {
+	params  *parser.ParsedVersionedGlobalParams
+	members []*ParsedMember
+}
+
+// NewAvailabilitySimulator returns a simulator for the params version. The
+// committee names the operators of the keys and should match the params; if
+// nil, every key is unattributed and operators cannot be evaluated.
+func NewAvailabilitySimulator(params *parser.ParsedVersionedGlobalParams, committee *ParsedCommittee) (*AvailabilitySimulator, error) {
+	if params.CovenantQuorum == 0 || int(params.CovenantQuorum) > len(params.CovenantPks) {
+		return nil, fmt.Errorf("covenant quorum %d is not between 1 and the %d covenant keys", params.CovenantQuorum, len(params.CovenantPks))
+	}
+
+	s := &AvailabilitySimulator{params: params}
+	if committee != nil {
+		if err := committee.CheckParams(params); err != nil {
+			return nil, err
+		}
+		s.members = committee.Members
+		return s, nil
+	}
+
+	for _, pk := range params.CovenantPks {
+		s.members = append(s.members, &ParsedMember{CovenantPk: pk})
+	}
+	return s, nil
+}
+
+// Members returns the members of the committee
+func (s *AvailabilitySimulator) Members() []*ParsedMember {
+	return s.members
+}
+
+func (s *AvailabilitySimulator) memberByKey(pk *btcec.PublicKey) *ParsedMember {
+	return (&ParsedCommittee{Members: s.members}).MemberByKey(pk)
+}
+
+// Quorum returns the number of members needed to unbond
+func (s *AvailabilitySimulator) Quorum() int {
+	return int(s.params.CovenantQuorum)
+}
+
+// BlockingSize returns the number of members whose outage blocks unbonding
+func (s *AvailabilitySimulator) BlockingSize() int {
+	return len(s.members) - s.Quorum() + 1
+}
+
+// Unattributed returns the members whose operator is not known. They are
+// left out of the operator shares, so an operator running some of them may
+// block unbonding without being reported.
+func (s *AvailabilitySimulator) Unattributed() []*ParsedMember {
+	return (&ParsedCommittee{Members: s.members}).Unattributed()
+}
+
+// Operators returns the share of each operator of attributed members, the
+// operators running the most keys first
+func (s *AvailabilitySimulator) Operators() []*OperatorShare {
+	var shares []*OperatorShare
+	byOperator := make(map[string]*OperatorShare)
+	for _, m := range s.members {
+		if m.Operator == "" {
+			continue
+		}
+		share, ok := byOperator[m.Operator]
+		if !ok {
+			share = &OperatorShare{Operator: m.Operator}
+			byOperator[m.Operator] = share
+			shares = append(shares, share)
+		}
+		share.Members = append(share.Members, m)
+	}
+	for _, share := range shares {
+		share.CanBlock = len(share.Members) >= s.BlockingSize()
+	}
+
+	sort.SliceStable(shares, func(i, j int) bool {
+		return len(shares[i].Members) > len(shares[j].Members)
+	})
+	return shares
+}
+
+// SingleOperatorBlockers returns the operators able to block unbonding on
+// their own
+func (s *AvailabilitySimulator) SingleOperatorBlockers() []*OperatorShare {
+	var blockers []*OperatorShare
+	for _, share := range s.Operators() {
+		if share.CanBlock {
+			blockers = append(blockers, share)
+		}
+	}
+	return blockers
+}
+
+// MinimalBlockingSet returns a smallest set of members whose outage blocks
+// unbonding, taken from the fewest operators, and these operators. Any
+// BlockingSize members block unbonding; the members of the operators running
+// the most keys are taken first, as correlated outages are the likeliest,
+// then the unattributed members.
+func (s *AvailabilitySimulator) MinimalBlockingSet() ([]*ParsedMember, []string) {
+	var set []*ParsedMember
+	var operators []string
+	for _, share := range s.Operators() {
+		if len(set) == s.BlockingSize() {
+			break
+		}
+		operators = append(operators, share.Operator)
+		for _, m := range share.Members {
+			if len(set) == s.BlockingSize() {
+				break
+			}
+			set = append(set, m)
+		}
+	}
+	for _, m := range s.Unattributed() {
+		if len(set) == s.BlockingSize() {
+			break
+		}
+		set = append(set, m)
+	}
+	return set, operators
+}
+
+// Run tells whether unbonding succeeds with the members of the scenario down
+func (s *AvailabilitySimulator) Run(scenario *Scenario) (*ScenarioResult, error) {
+	down := make(map[string]bool)
+	for _, pk := range scenario.Down {
+		if s.memberByKey(pk) == nil {
+			return nil, fmt.Errorf("scenario %s: covenant key %s is not in the committee", scenario.Name, keyString(pk))
+		}
+		down[keyString(pk)] = true
+	}
+	available := len(s.members) - len(down)
+
+	return &ScenarioResult{
+		Scenario:  scenario,
+		Available: available,
+		Succeeds:  available >= s.Quorum(),
+	}, nil
+}
+
+// SuccessProbability returns the probability that at least the quorum of
+// members is available, given the availability probability of each member
+// keyed by its compressed covenant key in hex. Members are taken to fail
+// independently; outages of the keys of one operator are better evaluated
+// with scenarios.
+func (s *AvailabilitySimulator) SuccessProbability(availability map[string]float64) (float64, error) {
+	// available[k] is the probability that exactly k of the members seen so
+	// far are available
+	available := []float64{1}
+	for _, m := range s.members {
+		p, ok := availability[keyString(m.CovenantPk)]
+		if !ok {
+			return 0, fmt.Errorf("no availability for covenant key %s", keyString(m.CovenantPk))
+		}
+		// NaN fails both comparisons
+		if !(p >= 0 && p <= 1) {
+			return 0, fmt.Errorf("availability %v of covenant key %s is not a probability", p, keyString(m.CovenantPk))
+		}
+
+		next := make([]float64, len(available)+1)
+		for k, q := range available {
+			next[k] += q * (1 - p)
+			next[k+1] += q * p
+		}
+		available = next
+	}
+
+	success := 0.0
+	for k := s.Quorum(); k < len(available); k++ {
+		success += available[k]
+	}
+	return success, nil
+}
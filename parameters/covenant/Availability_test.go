@@ -0,0 +1,156 @@
+package covenant_test
+
+import (
+	"encoding/hex"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func TestBbnTest4Availability(t *testing.T) {
+	committee, err := covenant.LoadCommittee(bbnTest4Dir)
+	require.NoError(t, err)
+	params := lastBbnTest4Params(t)
+
+	s, err := covenant.NewAvailabilitySimulator(params, committee)
+	require.NoError(t, err)
+	assert.Equal(t, 6, s.Quorum())
+	assert.Equal(t, 4, s.BlockingSize())
+
+	// no key is attributed, operators cannot be evaluated
+	assert.Empty(t, s.Operators())
+	assert.Len(t, s.Unattributed(), 9)
+	set, blockingOperators := s.MinimalBlockingSet()
+	assert.Len(t, set, 4)
+	assert.Empty(t, blockingOperators)
+
+	// once attributed, the three keys of the Babylon Foundation and the keys
+	// of the other operators in turn, as a hypothetical pairing
+	c := readBbnTest4Committee(t)
+	for i, m := range c.Members {
+		operator := 0
+		if i >= 3 {
+			operator = i - 2
+		}
+		m.Operator = c.Operators[operator].Name
+	}
+	committee, err = covenant.NewCommitteeFromBytes(marshal(t, c))
+	require.NoError(t, err)
+	s, err = covenant.NewAvailabilitySimulator(params, committee)
+	require.NoError(t, err)
+	assert.Empty(t, s.Unattributed())
+
+	operators := s.Operators()
+	require.Len(t, operators, 7)
+	assert.Equal(t, "Babylon Foundation", operators[0].Operator)
+	assert.Len(t, operators[0].Members, 3)
+	assert.False(t, operators[0].CanBlock)
+	assert.Empty(t, s.SingleOperatorBlockers())
+
+	// the Babylon Foundation and any other operator block unbonding
+	set, blockingOperators = s.MinimalBlockingSet()
+	assert.Len(t, set, 4)
+	assert.Equal(t, []string{"Babylon Foundation", "CoinSummer Labs"}, blockingOperators)
+	result, err := s.Run(&covenant.Scenario{Name: "blocking set", Down: memberKeys(set)})
+	require.NoError(t, err)
+	assert.False(t, result.Succeeds)
+
+	result, err = s.Run(&covenant.Scenario{Name: "babylon down", Down: memberKeys(operators[0].Members)})
+	require.NoError(t, err)
+	assert.True(t, result.Succeeds)
+	assert.Equal(t, 6, result.Available)
+
+	// with a quorum of 7, the Babylon Foundation blocks unbonding on its own
+	proposed := *params
+	proposed.CovenantQuorum = 7
+	s, err = covenant.NewAvailabilitySimulator(&proposed, committee)
+	require.NoError(t, err)
+	blockers := s.SingleOperatorBlockers()
+	require.Len(t, blockers, 1)
+	assert.Equal(t, "Babylon Foundation", blockers[0].Operator)
+}
+
+func TestSuccessProbability(t *testing.T) {
+	params := lastBbnTest4Params(t)
+	s, err := covenant.NewAvailabilitySimulator(params, nil)
+	require.NoError(t, err)
+	// without committee, no key is attributed to an operator
+	assert.Empty(t, s.Operators())
+	assert.Empty(t, s.SingleOperatorBlockers())
+	require.Len(t, s.Unattributed(), 9)
+
+	availability := func(p float64) map[string]float64 {
+		m := make(map[string]float64)
+		for _, pk := range params.CovenantPks {
+			m[hex.EncodeToString(pk.SerializeCompressed())] = p
+		}
+		return m
+	}
+
+	success, err := s.SuccessProbability(availability(1))
+	require.NoError(t, err)
+	assert.InDelta(t, 1, success, 1e-12)
+
+	// 6 of 9 members available with probability 0.5 each: 130/512
+	success, err = s.SuccessProbability(availability(0.5))
+	require.NoError(t, err)
+	assert.InDelta(t, 130.0/512, success, 1e-12)
+
+	// 4 members down for good block unbonding
+	a := availability(1)
+	for _, pk := range params.CovenantPks[:4] {
+		a[hex.EncodeToString(pk.SerializeCompressed())] = 0
+	}
+	success, err = s.SuccessProbability(a)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, success, 1e-12)
+
+	delete(a, hex.EncodeToString(params.CovenantPks[0].SerializeCompressed()))
+	_, err = s.SuccessProbability(a)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no availability for covenant key")
+
+	for _, p := range []float64{1.5, -0.1, math.NaN()} {
+		a[hex.EncodeToString(params.CovenantPks[0].SerializeCompressed())] = p
+		_, err = s.SuccessProbability(a)
+		require.Error(t, err, p)
+		assert.Contains(t, err.Error(), "is not a probability")
+	}
+}
+
+func TestFailAvailabilitySimulator(t *testing.T) {
+	params := lastBbnTest4Params(t)
+
+	proposed := *params
+	proposed.CovenantQuorum = 10
+	_, err := covenant.NewAvailabilitySimulator(&proposed, nil)
+	require.Error(t, err)
+	assert.Equal(t, "covenant quorum 10 is not between 1 and the 9 covenant keys", err.Error())
+
+	s, err := covenant.NewAvailabilitySimulator(params, nil)
+	require.NoError(t, err)
+	_, err = s.Run(&covenant.Scenario{Name: "unknown", Down: []*btcec.PublicKey{genPrivKey(t).PubKey()}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scenario unknown: covenant key")
+}
+
+func lastBbnTest4Params(t *testing.T) *parser.ParsedVersionedGlobalParams {
+	params, err := parser.NewParsedGlobalParamsFromFile(filepath.Join(bbnTest4Dir, covenant.GlobalParamsPath))
+	require.NoError(t, err)
+	return params.Versions[len(params.Versions)-1]
+}
+
+func memberKeys(members []*covenant.ParsedMember) []*btcec.PublicKey {
+	keys := make([]*btcec.PublicKey, len(members))
+	for i, m := range members {
+		keys[i] = m.CovenantPk
+	}
+	return keys
+}
@@ -0,0 +1,309 @@
+package covenant
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+const (
+	DefaultRequestTimeout = 10 * time.Second
+	DefaultRetries        = 2
+	DefaultRetryDelay     = 500 * time.Millisecond
+
+	// maximum size of a response read from a signer
+	maxSignerResponseSize = 1024 * 1024
+)
+
+// Client collects the covenant signatures of unbonding transactions from the
+// signers of the committee
+type Client struct {
+	client *http.Client
+	// RequestTimeout bounds each request to a signer
+	RequestTimeout time.Duration
+	// Retries is the number of times a request failing with a network error or
+	// a server error is retried, on each endpoint of a member
+	Retries    int
+	RetryDelay time.Duration
+}
+
+func NewClient(client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{
+		client:         client,
+		RequestTimeout: DefaultRequestTimeout,
+		Retries:        DefaultRetries,
+		RetryDelay:     DefaultRetryDelay,
+	}
+}
+
+// UnbondingRequest is an unbonding transaction signed by the staker, to be
+// signed by the committee of the params version of its staking transaction
+type UnbondingRequest struct {
+	StakingTx          *wire.MsgTx
+	UnbondingTx        *wire.MsgTx
+	StakerUnbondingSig *schnorr.Signature
+	Params             *parser.ParsedVersionedGlobalParams
+	Committee          *ParsedCommittee
+}
+
+// CovenantSignature is a verified signature of a member of the committee
+type CovenantSignature struct {
+	Member   *ParsedMember
+	Endpoint *url.URL
+	Sig      *schnorr.Signature
+}
+
+// SignerError is the last error of a member which did not sign
+type SignerError struct {
+	Member   *ParsedMember
+	Endpoint *url.URL
+	Err      error
+}
+
+func (e *SignerError) Error() string {
+	operator := e.Member.Operator
+	if operator == "" {
+		operator = "an unattributed operator"
+	}
+	if e.Endpoint == nil {
+		return fmt.Sprintf("covenant %x of %s: %v", e.Member.CovenantPk.SerializeCompressed(), operator, e.Err)
+	}
+	return fmt.Sprintf("covenant %x of %s at %s: %v", e.Member.CovenantPk.SerializeCompressed(), operator, e.Endpoint, e.Err)
+}
+
+func (e *SignerError) Unwrap() error {
+	return e.Err
+}
+
+// CollectedSignatures are the signatures collected until the quorum was
+// reached, and the errors of the members which answered before
+type CollectedSignatures struct {
+	Signatures []*CovenantSignature
+	Errors     []*SignerError
+}
+
+// signerStatusError is a response of a signer with an error status
+type signerStatusError struct {
+	status int
+	body   SignerErrorResponse
+}
+
+func (e *signerStatusError) Error() string {
+	if e.body.Message == "" {
+		return fmt.Sprintf("signer returned %d %s", e.status, http.StatusText(e.status))
+	}
+	return fmt.Sprintf("signer returned %d %s: %s: %s", e.status, http.StatusText(e.status), e.body.ErrorCode, e.body.Message)
+}
+
+// retryable returns true for the errors a later attempt may not have, i.e.
+// network errors, timeouts and server errors. Refusals and invalid
+// signatures are not retried.
+func retryable(err error) bool {
+	var statusErr *signerStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+	}
+	var sigErr *invalidSignatureError
+	return !errors.As(err, &sigErr)
+}
+
+type invalidSignatureError struct {
+	err error
+}
+
+func (e *invalidSignatureError) Error() string {
+	return "invalid signature: " + e.err.Error()
+}
+
+type memberResult struct {
+	sig *CovenantSignature
+	err *SignerError
+}
+
+// CollectSignatures asks every member of the committee to sign the unbonding
+// transaction concurrently, verifies each returned signature against the key
+// of the member, and returns as soon as the quorum of the params is reached.
+// Unattributed members are asked at every endpoint of the committee, the
+// signers of other keys refusing the request.
+// The requests still running are then cancelled. An error is returned with
+// the collected signatures if the quorum cannot be reached.
+func (c *Client) CollectSignatures(ctx context.Context, req *UnbondingRequest) (*CollectedSignatures, error) {
+	params := req.Params
+	if err := req.Committee.CheckParams(params); err != nil {
+		return nil, err
+	}
+
+	parsed, err := btcstaking.ParseV0StakingTx(req.StakingTx, params.Tag, params.CovenantPks, params.CovenantQuorum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking tx: %w", err)
+	}
+	if err := btcstaking.CheckUnbondingTx(req.StakingTx, parsed, req.UnbondingTx, params.UnbondingFee); err != nil {
+		return nil, err
+	}
+	if err := btcstaking.VerifyUnbondingSig(parsed, req.UnbondingTx, parsed.OpReturnData.StakerKey, req.StakerUnbondingSig); err != nil {
+		return nil, fmt.Errorf("invalid staker signature: %w", err)
+	}
+
+	var unbondingTx bytes.Buffer
+	if err := req.UnbondingTx.Serialize(&unbondingTx); err != nil {
+		return nil, err
+	}
+	base := SignUnbondingTxRequest{
+		StakingOutputPkScriptHex: hex.EncodeToString(parsed.StakingOutput.PkScript),
+		UnbondingTxHex:           hex.EncodeToString(unbondingTx.Bytes()),
+		StakerUnbondingSigHex:    hex.EncodeToString(req.StakerUnbondingSig.Serialize()),
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// buffered so that the members still running after the quorum do not
+	// block once cancelled
+	results := make(chan *memberResult, len(req.Committee.Members))
+	allEndpoints := req.Committee.Endpoints()
+	for _, m := range req.Committee.Members {
+		endpoints := m.Endpoints
+		if m.Operator == "" {
+			endpoints = allEndpoints
+		}
+		go func(m *ParsedMember, endpoints []*url.URL) {
+			signReq := base
+			signReq.CovenantPublicKey = hex.EncodeToString(m.CovenantPk.SerializeCompressed())
+			results <- c.signByMember(ctx, m, endpoints, &signReq, parsed, req.UnbondingTx)
+		}(m, endpoints)
+	}
+
+	collected := &CollectedSignatures{}
+	quorum := int(params.CovenantQuorum)
+	for range req.Committee.Members {
+		r := <-results
+		if r.err != nil {
+			collected.Errors = append(collected.Errors, r.err)
+			continue
+		}
+		collected.Signatures = append(collected.Signatures, r.sig)
+		if len(collected.Signatures) == quorum {
+			return collected, nil
+		}
+	}
+
+	msgs := make([]string, len(collected.Errors))
+	for i, e := range collected.Errors {
+		msgs[i] = e.Error()
+	}
+	return collected, fmt.Errorf("collected %d of the %d covenant signatures needed: %s",
+		len(collected.Signatures), quorum, strings.Join(msgs, "; "))
+}
+
+// signByMember tries each endpoint in turn, retrying the requests failing
+// with a retryable error
+func (c *Client) signByMember(
+	ctx context.Context,
+	m *ParsedMember,
+	endpoints []*url.URL,
+	req *SignUnbondingTxRequest,
+	parsed *btcstaking.ParsedV0StakingTx,
+	unbondingTx *wire.MsgTx,
+) *memberResult {
+	if len(endpoints) == 0 {
+		return &memberResult{err: &SignerError{Member: m, Err: fmt.Errorf("no endpoint to ask")}}
+	}
+	var lastErr *SignerError
+	for _, endpoint := range endpoints {
+		for attempt := 0; attempt <= c.Retries; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return &memberResult{err: &SignerError{Member: m, Endpoint: endpoint, Err: ctx.Err()}}
+				case <-time.After(c.RetryDelay):
+				}
+			}
+
+			sig, err := c.sign(ctx, endpoint, req)
+			if err == nil {
+				if verifyErr := btcstaking.VerifyUnbondingSig(parsed, unbondingTx, m.CovenantPk, sig); verifyErr != nil {
+					err = &invalidSignatureError{err: verifyErr}
+				}
+			}
+			if err == nil {
+				return &memberResult{sig: &CovenantSignature{Member: m, Endpoint: endpoint, Sig: sig}}
+			}
+
+			lastErr = &SignerError{Member: m, Endpoint: endpoint, Err: err}
+			if ctx.Err() != nil {
+				return &memberResult{err: lastErr}
+			}
+			if !retryable(err) {
+				break
+			}
+		}
+	}
+	return &memberResult{err: lastErr}
+}
+
+func (c *Client) sign(ctx context.Context, endpoint *url.URL, req *SignUnbondingTxRequest) (*schnorr.Signature, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.RequestTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(endpoint.String(), "/")+SignUnbondingTxPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxSignerResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &signerStatusError{status: resp.StatusCode}
+		// the body is only informative
+		_ = json.Unmarshal(respBody, &statusErr.body)
+		return nil, statusErr
+	}
+
+	var signResp signerResponse
+	if err := json.Unmarshal(respBody, &signResp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if signResp.Data == nil {
+		return nil, fmt.Errorf("invalid response: no data")
+	}
+	sigBytes, err := hex.DecodeString(signResp.Data.SignatureHex)
+	if err != nil {
+		return nil, &invalidSignatureError{err: err}
+	}
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return nil, &invalidSignatureError{err: err}
+	}
+	return sig, nil
+}
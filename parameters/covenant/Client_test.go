@@ -0,0 +1,320 @@
+package covenant_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func TestCollectSignatures(t *testing.T) {
+	u := newTestUnbonding(t, 5, 3)
+
+	var requests atomic.Int32
+	committee := u.committee(t, func(i int, w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		u.sign(t, i, w, r)
+	})
+
+	collected, err := newTestClient().CollectSignatures(context.Background(), u.request(committee))
+	require.NoError(t, err)
+	require.Len(t, collected.Signatures, 3)
+	for _, s := range collected.Signatures {
+		require.NoError(t, btcstaking.VerifyUnbondingSig(u.parsed, u.unbondingTx, s.Member.CovenantPk, s.Sig))
+	}
+	assert.LessOrEqual(t, requests.Load(), int32(5))
+}
+
+func TestCollectSignaturesUnattributed(t *testing.T) {
+	u := newTestUnbonding(t, 4, 3)
+
+	// the signers only sign for their own key
+	attributed := u.committee(t, func(i int, w http.ResponseWriter, r *http.Request) {
+		u.sign(t, i, w, r)
+	})
+	operator := &covenant.ParsedOperator{Name: "operator"}
+	committee := &covenant.ParsedCommittee{Network: "test", Operators: []*covenant.ParsedOperator{operator}}
+	for _, m := range attributed.Members {
+		operator.Endpoints = append(operator.Endpoints, m.Endpoints...)
+		committee.Members = append(committee.Members, &covenant.ParsedMember{CovenantPk: m.CovenantPk})
+	}
+
+	collected, err := newTestClient().CollectSignatures(context.Background(), u.request(committee))
+	require.NoError(t, err)
+	require.Len(t, collected.Signatures, 3)
+	for _, s := range collected.Signatures {
+		require.NoError(t, btcstaking.VerifyUnbondingSig(u.parsed, u.unbondingTx, s.Member.CovenantPk, s.Sig))
+		assert.Equal(t, attributed.MemberByKey(s.Member.CovenantPk).Endpoints[0], s.Endpoint)
+	}
+
+	// without endpoints, unattributed members cannot be asked
+	committee.Operators = nil
+	collected, err = newTestClient().CollectSignatures(context.Background(), u.request(committee))
+	require.Error(t, err)
+	require.Len(t, collected.Errors, 4)
+	for _, e := range collected.Errors {
+		assert.Equal(t, fmt.Sprintf("covenant %x of an unattributed operator: no endpoint to ask",
+			e.Member.CovenantPk.SerializeCompressed()), e.Error())
+	}
+}
+
+func TestCollectSignaturesStopsAtQuorum(t *testing.T) {
+	u := newTestUnbonding(t, 5, 2)
+
+	// the last members only answer once the test is over
+	done := make(chan struct{})
+	defer close(done)
+	committee := u.committee(t, func(i int, w http.ResponseWriter, r *http.Request) {
+		if i >= 2 {
+			select {
+			case <-done:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		u.sign(t, i, w, r)
+	})
+
+	start := time.Now()
+	collected, err := newTestClient().CollectSignatures(context.Background(), u.request(committee))
+	require.NoError(t, err)
+	require.Len(t, collected.Signatures, 2)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestCollectSignaturesWithFailures(t *testing.T) {
+	u := newTestUnbonding(t, 5, 3)
+
+	var attempts [5]atomic.Int32
+	committee := u.committee(t, func(i int, w http.ResponseWriter, r *http.Request) {
+		n := attempts[i].Add(1)
+		switch i {
+		case 0:
+			// refusals are not retried
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(&covenant.SignerErrorResponse{ErrorCode: "BAD_REQUEST", Message: "refused"})
+		case 1:
+			// signature of another key
+			writeSignature(t, w, signWith(t, u, u.covenantKeys[4]))
+		case 2:
+			// recovers after a server error
+			if n == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			u.sign(t, i, w, r)
+		default:
+			u.sign(t, i, w, r)
+		}
+	})
+
+	collected, err := newTestClient().CollectSignatures(context.Background(), u.request(committee))
+	require.NoError(t, err)
+	require.Len(t, collected.Signatures, 3)
+	assert.Equal(t, int32(1), attempts[0].Load())
+	assert.Equal(t, int32(1), attempts[1].Load())
+	assert.Equal(t, int32(2), attempts[2].Load())
+	require.Len(t, collected.Errors, 2)
+}
+
+func TestFailCollectSignatures(t *testing.T) {
+	u := newTestUnbonding(t, 3, 2)
+
+	committee := u.committee(t, func(i int, w http.ResponseWriter, r *http.Request) {
+		if i == 0 {
+			u.sign(t, i, w, r)
+			return
+		}
+		// slower than the request timeout
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	client := newTestClient()
+	client.RequestTimeout = 50 * time.Millisecond
+	collected, err := client.CollectSignatures(context.Background(), u.request(committee))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collected 1 of the 2 covenant signatures needed")
+	assert.Contains(t, err.Error(), "context deadline exceeded")
+	require.Len(t, collected.Signatures, 1)
+	require.Len(t, collected.Errors, 2)
+
+	// the staker signature is checked before contacting the committee
+	req := u.request(committee)
+	req.StakerUnbondingSig = signWith(t, u, u.covenantKeys[0])
+	_, err = client.CollectSignatures(context.Background(), req)
+	require.Error(t, err)
+	assert.Equal(t, fmt.Sprintf("invalid staker signature: signature of the unbonding tx is not valid for key %x",
+		schnorr.SerializePubKey(u.stakerKey.PubKey())), err.Error())
+
+	// the committee is the one of the params
+	req = u.request(committee)
+	req.Committee = &covenant.ParsedCommittee{Network: "test", Members: committee.Members[1:]}
+	_, err = client.CollectSignatures(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "committee does not match version 0 of the global params: missing covenant keys")
+
+	// the unbonding tx pays the unbonding fee
+	req = u.request(committee)
+	req.UnbondingTx = u.unbondingTx.Copy()
+	req.UnbondingTx.TxOut[0].Value++
+	_, err = client.CollectSignatures(context.Background(), req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unbonding output has value")
+}
+
+type testUnbonding struct {
+	covenantKeys []*btcec.PrivateKey
+	params       *parser.ParsedVersionedGlobalParams
+	stakerKey    *btcec.PrivateKey
+	stakingTx    *wire.MsgTx
+	unbondingTx  *wire.MsgTx
+	parsed       *btcstaking.ParsedV0StakingTx
+	stakerSig    *schnorr.Signature
+}
+
+func newTestUnbonding(t *testing.T, numCovenants int, quorum uint32) *testUnbonding {
+	params := &parser.ParsedVersionedGlobalParams{
+		Tag:              []byte("test"),
+		CovenantQuorum:   quorum,
+		UnbondingTime:    10,
+		UnbondingFee:     1000,
+		MinStakingAmount: 10000,
+		MaxStakingAmount: 100000,
+		MinStakingTime:   100,
+		MaxStakingTime:   100,
+	}
+	var covenantKeys []*btcec.PrivateKey
+	for i := 0; i < numCovenants; i++ {
+		key := genPrivKey(t)
+		covenantKeys = append(covenantKeys, key)
+		params.CovenantPks = append(params.CovenantPks, key.PubKey())
+	}
+	return newTestUnbondingWithParams(t, params, covenantKeys)
+}
+
+// newTestUnbondingWithParams builds a staking tx of 50000 satoshis locked
+// for 100 blocks under the params, and its unbonding tx signed by the staker
+func newTestUnbondingWithParams(t *testing.T, params *parser.ParsedVersionedGlobalParams, covenantKeys []*btcec.PrivateKey) *testUnbonding {
+	u := &testUnbonding{
+		covenantKeys: covenantKeys,
+		params:       params,
+		stakerKey:    genPrivKey(t),
+	}
+	quorum := params.CovenantQuorum
+
+	fpKey := genPrivKey(t).PubKey()
+	info, err := btcstaking.BuildStakingInfo(u.stakerKey.PubKey(), []*btcec.PublicKey{fpKey},
+		u.params.CovenantPks, quorum, 100, 50000)
+	require.NoError(t, err)
+	opReturn, err := btcstaking.BuildV0OpReturnScript(u.params.Tag, u.stakerKey.PubKey(), fpKey, 100)
+	require.NoError(t, err)
+
+	u.stakingTx = wire.NewMsgTx(2)
+	u.stakingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0), nil, nil))
+	u.stakingTx.AddTxOut(info.StakingOutput)
+	u.stakingTx.AddTxOut(wire.NewTxOut(0, opReturn))
+
+	u.parsed, err = btcstaking.ParseV0StakingTx(u.stakingTx, u.params.Tag, u.params.CovenantPks, quorum)
+	require.NoError(t, err)
+
+	stakingHash := u.stakingTx.TxHash()
+	u.unbondingTx = wire.NewMsgTx(2)
+	u.unbondingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&stakingHash, 0), nil, nil))
+	u.unbondingTx.AddTxOut(wire.NewTxOut(int64(50000-u.params.UnbondingFee), []byte{txscript.OP_TRUE}))
+
+	u.stakerSig = signWith(t, u, u.stakerKey)
+	return u
+}
+
+// committee starts a signer server per covenant key, whose requests are
+// handled by the handler with the index of the key
+func (u *testUnbonding) committee(t *testing.T, handler func(i int, w http.ResponseWriter, r *http.Request)) *covenant.ParsedCommittee {
+	committee := &covenant.ParsedCommittee{Network: "test"}
+	for i, key := range u.covenantKeys {
+		i := i
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(i, w, r)
+		}))
+		t.Cleanup(server.Close)
+
+		endpoint, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		committee.Members = append(committee.Members, &covenant.ParsedMember{
+			Operator:   fmt.Sprintf("operator %d", i),
+			Endpoints:  []*url.URL{endpoint},
+			CovenantPk: key.PubKey(),
+		})
+	}
+	return committee
+}
+
+func (u *testUnbonding) request(committee *covenant.ParsedCommittee) *covenant.UnbondingRequest {
+	return &covenant.UnbondingRequest{
+		StakingTx:          u.stakingTx,
+		UnbondingTx:        u.unbondingTx,
+		StakerUnbondingSig: u.stakerSig,
+		Params:             u.params,
+		Committee:          committee,
+	}
+}
+
+// sign answers the request with the signature of the covenant key i
+func (u *testUnbonding) sign(t *testing.T, i int, w http.ResponseWriter, r *http.Request) {
+	var req covenant.SignUnbondingTxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || r.URL.Path != covenant.SignUnbondingTxPath {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if req.CovenantPublicKey != hex.EncodeToString(u.covenantKeys[i].PubKey().SerializeCompressed()) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	writeSignature(t, w, signWith(t, u, u.covenantKeys[i]))
+}
+
+func newTestClient() *covenant.Client {
+	client := covenant.NewClient(nil)
+	client.RetryDelay = time.Millisecond
+	return client
+}
+
+func signWith(t *testing.T, u *testUnbonding, key *btcec.PrivateKey) *schnorr.Signature {
+	sig, err := btcstaking.SignUnbondingTx(u.parsed, u.unbondingTx, key)
+	require.NoError(t, err)
+	return sig
+}
+
+func writeSignature(t *testing.T, w http.ResponseWriter, sig *schnorr.Signature) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": &covenant.SignUnbondingTxResponse{SignatureHex: hex.EncodeToString(sig.Serialize())},
+	})
+	require.NoError(t, err)
+}
+
+func genPrivKey(t *testing.T) *btcec.PrivateKey {
+	key, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	return key
+}
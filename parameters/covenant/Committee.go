@@ -0,0 +1,341 @@
+package covenant
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+const (
+	// CommitteeDirName is the directory of a network describing its covenant
+	// committee
+	CommitteeDirName = "covenant-committee"
+	// CommitteeFileName is the manifest of the committee in its directory
+	CommitteeFileName = "committee.json"
+	// CommitteeReadmeFileName is the README of the committee, which states
+	// its size
+	CommitteeReadmeFileName = "README.md"
+	// GlobalParamsPath is the global params file relative to the directory
+	// of a network
+	GlobalParamsPath = "parameters/global-params.json"
+)
+
+// the README states the size as "the Covenant Emulation Committee has 9
+// members"
+var readmeSizeRegex = regexp.MustCompile(`[Cc]ommittee has (\d+) members`)
+
+// Operator is an entity running covenant signers, with the endpoints of its
+// signers
+type Operator struct {
+	Name      string   `json:"name"`
+	Endpoints []string `json:"endpoints"`
+}
+
+// Member is a covenant key of the committee. The operator running its signer
+// is only set once confirmed by the operator; members without operator are
+// unattributed.
+type Member struct {
+	CovenantPk string `json:"covenant_pk"`
+	Operator   string `json:"operator,omitempty"`
+}
+
+type Committee struct {
+	Network   string      `json:"network"`
+	Operators []*Operator `json:"operators"`
+	Members   []*Member   `json:"members"`
+}
+
+type ParsedOperator struct {
+	Name      string
+	Endpoints []*url.URL
+}
+
+// ParsedMember is a covenant key with the endpoints of its operator. Both are
+// empty for unattributed members.
+type ParsedMember struct {
+	Operator   string
+	Endpoints  []*url.URL
+	CovenantPk *btcec.PublicKey
+}
+
+type ParsedCommittee struct {
+	Network   string
+	Operators []*ParsedOperator
+	Members   []*ParsedMember
+}
+
+// parseCovenantPkFromHex parses a covenant key in the 33 bytes compressed
+// encoding of the global params
+func parseCovenantPkFromHex(pkStr string) (*btcec.PublicKey, error) {
+	pkBytes, err := hex.DecodeString(pkStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return btcec.ParsePubKey(pkBytes)
+}
+
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("should be an http or https url")
+	}
+	return u, nil
+}
+
+// keyString is the key used to compare covenant keys
+func keyString(pk *btcec.PublicKey) string {
+	return hex.EncodeToString(pk.SerializeCompressed())
+}
+
+func parseOperator(o *Operator) (*ParsedOperator, error) {
+	if strings.TrimSpace(o.Name) == "" {
+		return nil, fmt.Errorf("name should be set")
+	}
+	if len(o.Endpoints) == 0 {
+		return nil, fmt.Errorf("no endpoints")
+	}
+
+	var endpoints []*url.URL
+	for _, e := range o.Endpoints {
+		u, err := parseEndpoint(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %s: %w", e, err)
+		}
+		endpoints = append(endpoints, u)
+	}
+
+	return &ParsedOperator{Name: o.Name, Endpoints: endpoints}, nil
+}
+
+func ParseCommittee(c *Committee) (*ParsedCommittee, error) {
+	if c.Network == "" {
+		return nil, fmt.Errorf("network should be set")
+	}
+	if len(c.Members) == 0 {
+		return nil, fmt.Errorf("committee has no members")
+	}
+
+	operators := make(map[string]*ParsedOperator)
+	var parsedOperators []*ParsedOperator
+	for i, o := range c.Operators {
+		parsed, err := parseOperator(o)
+		if err != nil {
+			return nil, fmt.Errorf("operator %d: %w", i, err)
+		}
+		if operators[parsed.Name] != nil {
+			return nil, fmt.Errorf("operator %d: duplicate operator %s", i, parsed.Name)
+		}
+		operators[parsed.Name] = parsed
+		parsedOperators = append(parsedOperators, parsed)
+	}
+
+	keys := make(map[string]bool)
+	var members []*ParsedMember
+	for i, m := range c.Members {
+		pk, err := parseCovenantPkFromHex(m.CovenantPk)
+		if err != nil {
+			return nil, fmt.Errorf("member %d: invalid covenant_pk %s: %w", i, m.CovenantPk, err)
+		}
+		if keys[keyString(pk)] {
+			return nil, fmt.Errorf("member %d: duplicate covenant_pk %s", i, m.CovenantPk)
+		}
+		keys[keyString(pk)] = true
+
+		member := &ParsedMember{CovenantPk: pk}
+		if m.Operator != "" {
+			o := operators[m.Operator]
+			if o == nil {
+				return nil, fmt.Errorf("member %d: unknown operator %s", i, m.Operator)
+			}
+			member.Operator = o.Name
+			member.Endpoints = o.Endpoints
+		}
+		members = append(members, member)
+	}
+
+	return &ParsedCommittee{
+		Network:   c.Network,
+		Operators: parsedOperators,
+		Members:   members,
+	}, nil
+}
+
+func NewCommitteeFromFile(filePath string) (*ParsedCommittee, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCommitteeFromBytes(data)
+}
+
+func NewCommitteeFromBytes(data []byte) (*ParsedCommittee, error) {
+	var c Committee
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid committee manifest: %w", err)
+	}
+
+	parsed, err := ParseCommittee(&c)
+	if err != nil {
+		return nil, fmt.Errorf("invalid committee manifest: %w", err)
+	}
+	return parsed, nil
+}
+
+// Keys returns the covenant keys of the members, in the manifest order
+func (c *ParsedCommittee) Keys() []*btcec.PublicKey {
+	keys := make([]*btcec.PublicKey, len(c.Members))
+	for i, m := range c.Members {
+		keys[i] = m.CovenantPk
+	}
+	return keys
+}
+
+// Unattributed returns the members whose operator is not known, in the
+// manifest order
+func (c *ParsedCommittee) Unattributed() []*ParsedMember {
+	var unattributed []*ParsedMember
+	for _, m := range c.Members {
+		if m.Operator == "" {
+			unattributed = append(unattributed, m)
+		}
+	}
+	return unattributed
+}
+
+// Endpoints returns the distinct endpoints of the operators and of the
+// members, in the manifest order
+func (c *ParsedCommittee) Endpoints() []*url.URL {
+	var endpoints []*url.URL
+	seen := make(map[string]bool)
+	add := func(urls []*url.URL) {
+		for _, u := range urls {
+			if !seen[u.String()] {
+				seen[u.String()] = true
+				endpoints = append(endpoints, u)
+			}
+		}
+	}
+	for _, o := range c.Operators {
+		add(o.Endpoints)
+	}
+	for _, m := range c.Members {
+		add(m.Endpoints)
+	}
+	return endpoints
+}
+
+// MemberByKey returns the member holding the covenant key, or nil
+func (c *ParsedCommittee) MemberByKey(pk *btcec.PublicKey) *ParsedMember {
+	for _, m := range c.Members {
+		if keyString(m.CovenantPk) == keyString(pk) {
+			return m
+		}
+	}
+	return nil
+}
+
+// CheckParams checks that the keys of the committee are exactly the covenant
+// keys of the params. The order does not matter, as the staking scripts sort
+// the covenant keys.
+func (c *ParsedCommittee) CheckParams(p *parser.ParsedVersionedGlobalParams) error {
+	inParams := make(map[string]bool)
+	for _, pk := range p.CovenantPks {
+		inParams[keyString(pk)] = true
+	}
+	inCommittee := make(map[string]bool)
+	for _, m := range c.Members {
+		inCommittee[keyString(m.CovenantPk)] = true
+	}
+
+	var missing, unknown []string
+	for k := range inParams {
+		if !inCommittee[k] {
+			missing = append(missing, k)
+		}
+	}
+	for k := range inCommittee {
+		if !inParams[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unknown)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing covenant keys "+strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		problems = append(problems, "covenant keys not in the params "+strings.Join(unknown, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("committee does not match version %d of the global params: %s", p.Version, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ReadmeCommitteeSize returns the size of the committee stated by its README
+func ReadmeCommitteeSize(readmePath string) (int, error) {
+	data, err := os.ReadFile(readmePath)
+	if err != nil {
+		return 0, err
+	}
+
+	matches := readmeSizeRegex.FindAllSubmatch(data, -1)
+	if len(matches) != 1 {
+		return 0, fmt.Errorf("%s should state the committee size once, found %d statements", readmePath, len(matches))
+	}
+	return strconv.Atoi(string(matches[0][1]))
+}
+
+// LoadCommittee loads the committee manifest of the network directory and
+// checks it against the README of the committee and the last version of the
+// global params of the network
+func LoadCommittee(networkDir string) (*ParsedCommittee, error) {
+	committeeDir := filepath.Join(networkDir, CommitteeDirName)
+	committee, err := NewCommitteeFromFile(filepath.Join(committeeDir, CommitteeFileName))
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(networkDir)
+	if err != nil {
+		return nil, err
+	}
+	if network := filepath.Base(absDir); committee.Network != network {
+		return nil, fmt.Errorf("committee manifest is for network %s, not %s", committee.Network, network)
+	}
+
+	size, err := ReadmeCommitteeSize(filepath.Join(committeeDir, CommitteeReadmeFileName))
+	if err != nil {
+		return nil, err
+	}
+	if size != len(committee.Members) {
+		return nil, fmt.Errorf("committee manifest has %d members, the README states %d", len(committee.Members), size)
+	}
+
+	params, err := parser.NewParsedGlobalParamsFromFile(filepath.Join(networkDir, GlobalParamsPath))
+	if err != nil {
+		return nil, err
+	}
+	if err := committee.CheckParams(params.Versions[len(params.Versions)-1]); err != nil {
+		return nil, err
+	}
+
+	return committee, nil
+}
@@ -0,0 +1,201 @@
+package covenant_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+const bbnTest4Dir = "../../bbn-test-4"
+
+func TestLoadBbnTest4Committee(t *testing.T) {
+	committee, err := covenant.LoadCommittee(bbnTest4Dir)
+	require.NoError(t, err)
+	require.Len(t, committee.Members, 9)
+	var operators []string
+	for _, o := range committee.Operators {
+		operators = append(operators, o.Name)
+	}
+	assert.Equal(t, []string{"Babylon Foundation", "CoinSummer Labs", "RockX", "AltLayer", "Zellic",
+		"Informal Systems", "Cubist"}, operators)
+	assert.Len(t, committee.Operators[0].Endpoints, 3)
+	assert.Len(t, committee.Endpoints(), 9)
+	// no operator has confirmed the key of its signers yet
+	assert.Len(t, committee.Unattributed(), 9)
+
+	// the first versions of the params had other keys
+	params, err := parser.NewParsedGlobalParamsFromFile(filepath.Join(bbnTest4Dir, covenant.GlobalParamsPath))
+	require.NoError(t, err)
+	err = committee.CheckParams(params.Versions[0])
+	require.Error(t, err)
+	assert.Equal(t, "committee does not match version 0 of the global params: "+
+		"missing covenant keys 0249766ccd9e3cd94343e2040474a77fb37cdfd30530d05f9f1e96ae1e2102c86e, 0276d1ae01f8fb6bf30108731c884cddcf57ef6eef2d9d9559e130894e0e40c62c; "+
+		"covenant keys not in the params 020aee0509b16db71c999238a4827db945526859b13c95487ab46725357c9a9f25, 03fa9d882d45f4060bdb8042183828cd87544f1ea997380e586cab77d5fd698737",
+		err.Error())
+
+	for _, pk := range params.Versions[len(params.Versions)-1].CovenantPks {
+		require.NotNil(t, committee.MemberByKey(pk))
+	}
+}
+
+func TestFailCommitteeParsing(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(c *covenant.Committee)
+		errMsg string
+	}{
+		{
+			name:   "no network",
+			modify: func(c *covenant.Committee) { c.Network = "" },
+			errMsg: "invalid committee manifest: network should be set",
+		},
+		{
+			name:   "no members",
+			modify: func(c *covenant.Committee) { c.Members = nil },
+			errMsg: "invalid committee manifest: committee has no members",
+		},
+		{
+			name:   "no operator name",
+			modify: func(c *covenant.Committee) { c.Operators[1].Name = " " },
+			errMsg: "invalid committee manifest: operator 1: name should be set",
+		},
+		{
+			name:   "duplicate operator",
+			modify: func(c *covenant.Committee) { c.Operators[2].Name = c.Operators[0].Name },
+			errMsg: "invalid committee manifest: operator 2: duplicate operator Babylon Foundation",
+		},
+		{
+			name:   "no endpoints",
+			modify: func(c *covenant.Committee) { c.Operators[0].Endpoints = nil },
+			errMsg: "invalid committee manifest: operator 0: no endpoints",
+		},
+		{
+			name:   "invalid endpoint",
+			modify: func(c *covenant.Committee) { c.Operators[1].Endpoints = []string{"54.167.222.28:9791"} },
+			errMsg: "invalid committee manifest: operator 1: invalid endpoint 54.167.222.28:9791: " +
+				"parse \"54.167.222.28:9791\": first path segment in URL cannot contain colon",
+		},
+		{
+			name:   "endpoint without scheme",
+			modify: func(c *covenant.Committee) { c.Operators[2].Endpoints = []string{"grpc://signer.rockx.com"} },
+			errMsg: "invalid committee manifest: operator 2: invalid endpoint grpc://signer.rockx.com: should be an http or https url",
+		},
+		{
+			name:   "unknown operator",
+			modify: func(c *covenant.Committee) { c.Members[3].Operator = "Unknown Labs" },
+			errMsg: "invalid committee manifest: member 3: unknown operator Unknown Labs",
+		},
+		{
+			name:   "x-only key",
+			modify: func(c *covenant.Committee) { c.Members[2].CovenantPk = c.Members[2].CovenantPk[2:] },
+			errMsg: "invalid committee manifest: member 2: invalid covenant_pk " +
+				"17921cf156ccb4e73d428f996ed11b245313e37e27c978ac4d2cc21eca4672e4: malformed public key: invalid length: 32",
+		},
+		{
+			name:   "duplicate key",
+			modify: func(c *covenant.Committee) { c.Members[8].CovenantPk = c.Members[0].CovenantPk },
+			errMsg: "invalid committee manifest: member 8: duplicate covenant_pk " +
+				"03fa9d882d45f4060bdb8042183828cd87544f1ea997380e586cab77d5fd698737",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := readBbnTest4Committee(t)
+			tc.modify(c)
+			_, err := covenant.NewCommitteeFromBytes(marshal(t, c))
+			require.Error(t, err)
+			assert.Equal(t, tc.errMsg, err.Error())
+		})
+	}
+}
+
+func TestAttributedMembers(t *testing.T) {
+	c := readBbnTest4Committee(t)
+	c.Members[3].Operator = "CoinSummer Labs"
+	committee, err := covenant.NewCommitteeFromBytes(marshal(t, c))
+	require.NoError(t, err)
+
+	m := committee.Members[3]
+	assert.Equal(t, "CoinSummer Labs", m.Operator)
+	require.Len(t, m.Endpoints, 1)
+	assert.Equal(t, "http://54.167.222.28:9791", m.Endpoints[0].String())
+	assert.Len(t, committee.Unattributed(), 8)
+	assert.NotContains(t, committee.Unattributed(), m)
+}
+
+func TestFailLoadCommittee(t *testing.T) {
+	// the README states another size
+	networkDir := copyBbnTest4(t)
+	readme := filepath.Join(networkDir, covenant.CommitteeDirName, covenant.CommitteeReadmeFileName)
+	require.NoError(t, os.WriteFile(readme, []byte("The Covenant Emulation Committee has 7 members.\n"), 0644))
+	_, err := covenant.LoadCommittee(networkDir)
+	require.Error(t, err)
+	assert.Equal(t, "committee manifest has 9 members, the README states 7", err.Error())
+
+	require.NoError(t, os.WriteFile(readme, []byte("No size.\n"), 0644))
+	_, err = covenant.LoadCommittee(networkDir)
+	require.Error(t, err)
+	assert.Equal(t, readme+" should state the committee size once, found 0 statements", err.Error())
+
+	// a member missing from the manifest
+	networkDir = copyBbnTest4(t)
+	c := readBbnTest4Committee(t)
+	c.Members = c.Members[:8]
+	writeCommittee(t, networkDir, c)
+	_, err = covenant.LoadCommittee(networkDir)
+	require.Error(t, err)
+	assert.Equal(t, "committee manifest has 8 members, the README states 9", err.Error())
+
+	// the manifest of another network
+	networkDir = copyBbnTest4(t)
+	c = readBbnTest4Committee(t)
+	c.Network = "bbn-test-3"
+	writeCommittee(t, networkDir, c)
+	_, err = covenant.LoadCommittee(networkDir)
+	require.Error(t, err)
+	assert.Equal(t, "committee manifest is for network bbn-test-3, not bbn-test-4", err.Error())
+}
+
+func readBbnTest4Committee(t *testing.T) *covenant.Committee {
+	data, err := os.ReadFile(filepath.Join(bbnTest4Dir, covenant.CommitteeDirName, covenant.CommitteeFileName))
+	require.NoError(t, err)
+	var c covenant.Committee
+	require.NoError(t, json.Unmarshal(data, &c))
+	return &c
+}
+
+func marshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// copyBbnTest4 copies the committee and the params of bbn-test-4 to a
+// temporary network directory of the same name
+func copyBbnTest4(t *testing.T) string {
+	networkDir := filepath.Join(t.TempDir(), "bbn-test-4")
+	for _, f := range []string{
+		filepath.Join(covenant.CommitteeDirName, covenant.CommitteeFileName),
+		filepath.Join(covenant.CommitteeDirName, covenant.CommitteeReadmeFileName),
+		covenant.GlobalParamsPath,
+	} {
+		data, err := os.ReadFile(filepath.Join(bbnTest4Dir, f))
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(networkDir, f)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(networkDir, f), data, 0644))
+	}
+	return networkDir
+}
+
+func writeCommittee(t *testing.T, networkDir string, c *covenant.Committee) {
+	path := filepath.Join(networkDir, covenant.CommitteeDirName, covenant.CommitteeFileName)
+	require.NoError(t, os.WriteFile(path, marshal(t, c), 0644))
+}
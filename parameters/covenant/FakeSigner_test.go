@@ -0,0 +1,148 @@
+package covenant_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/covenant/covenanttest"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+// startFakeCommittee starts a committee of 9 signers with a quorum of 6, and
+// returns it with an unbonding tx whose staking tx is known to the signers
+func startFakeCommittee(t *testing.T) (*covenanttest.FakeCommittee, *testUnbonding) {
+	backend := btcbackend.NewFakeBackend()
+	committee, err := covenanttest.StartFakeCommittee(&parser.VersionedGlobalParams{
+		Version:           0,
+		ActivationHeight:  100,
+		StakingCap:        1000000,
+		Tag:               hex.EncodeToString([]byte("fake")),
+		CovenantQuorum:    6,
+		UnbondingTime:     10,
+		UnbondingFee:      1000,
+		MaxStakingAmount:  100000,
+		MinStakingAmount:  10000,
+		MaxStakingTime:    100,
+		MinStakingTime:    100,
+		ConfirmationDepth: 10,
+	}, 9, backend)
+	require.NoError(t, err)
+	t.Cleanup(committee.Close)
+
+	u := newTestUnbondingWithParams(t, committee.ParsedParams, committee.Keys)
+	backend.AddTransaction(u.stakingTx, 110)
+	return committee, u
+}
+
+func TestFakeCommittee(t *testing.T) {
+	committee, u := startFakeCommittee(t)
+	require.Len(t, committee.Params.CovenantPks, 9)
+	require.Len(t, committee.Committee.Members, 9)
+	require.NoError(t, committee.Committee.CheckParams(committee.ParsedParams))
+
+	collected, err := newTestClient().CollectSignatures(context.Background(), u.request(committee.Committee))
+	require.NoError(t, err)
+	require.Len(t, collected.Signatures, 6)
+}
+
+func TestFakeCommitteeFaults(t *testing.T) {
+	committee, u := startFakeCommittee(t)
+
+	// 3 members fail for good, one recovers after a server error and the
+	// slow ones answer too late
+	committee.Signers[0].SetFaults(covenanttest.Faults{Refuse: true})
+	committee.Signers[1].SetFaults(covenanttest.Faults{WrongSignature: true})
+	committee.Signers[2].SetFaults(covenanttest.Faults{Delay: time.Second})
+	committee.Signers[3].SetFaults(covenanttest.Faults{ServerErrors: 1})
+
+	client := newTestClient()
+	client.RequestTimeout = 200 * time.Millisecond
+	client.Retries = 1
+	collected, err := client.CollectSignatures(context.Background(), u.request(committee.Committee))
+	require.NoError(t, err)
+	require.Len(t, collected.Signatures, 6)
+	for _, s := range collected.Signatures {
+		assert.NotContains(t, []string{"fake operator 0", "fake operator 1", "fake operator 2"}, s.Member.Operator)
+	}
+	assert.Equal(t, 1, committee.Signers[0].Requests())
+	assert.Equal(t, 1, committee.Signers[1].Requests())
+	assert.Equal(t, 2, committee.Signers[3].Requests())
+
+	// the quorum cannot be reached with 4 members refusing
+	committee.Signers[3].SetFaults(covenanttest.Faults{Refuse: true})
+	committee.Signers[2].SetFaults(covenanttest.Faults{Refuse: true})
+	_, err = client.CollectSignatures(context.Background(), u.request(committee.Committee))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collected 5 of the 6 covenant signatures needed")
+	assert.Contains(t, err.Error(), "signer returned 400 Bad Request: BAD_REQUEST: request refused")
+	assert.Contains(t, err.Error(), "invalid signature: signature of the unbonding tx is not valid for key")
+}
+
+func TestFakeSignerChecksRequests(t *testing.T) {
+	committee, u := startFakeCommittee(t)
+	endpoint := committee.Committee.Members[0].Endpoints[0].String() + covenant.SignUnbondingTxPath
+
+	var unbondingTx bytes.Buffer
+	require.NoError(t, u.unbondingTx.Serialize(&unbondingTx))
+	valid := covenant.SignUnbondingTxRequest{
+		StakingOutputPkScriptHex: hex.EncodeToString(u.parsed.StakingOutput.PkScript),
+		UnbondingTxHex:           hex.EncodeToString(unbondingTx.Bytes()),
+		StakerUnbondingSigHex:    hex.EncodeToString(u.stakerSig.Serialize()),
+		CovenantPublicKey:        hex.EncodeToString(committee.Keys[0].PubKey().SerializeCompressed()),
+	}
+
+	status, _ := post(t, endpoint, &valid)
+	require.Equal(t, http.StatusOK, status)
+
+	req := valid
+	req.CovenantPublicKey = hex.EncodeToString(committee.Keys[1].PubKey().SerializeCompressed())
+	status, errResp := post(t, endpoint, &req)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "covenant_public_key "+req.CovenantPublicKey+" is not the key of the signer", errResp.Message)
+
+	req = valid
+	req.StakerUnbondingSigHex = hex.EncodeToString(signWith(t, u, committee.Keys[0]).Serialize())
+	status, errResp = post(t, endpoint, &req)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, errResp.Message, "invalid staker signature")
+
+	req = valid
+	req.StakingOutputPkScriptHex = "51"
+	status, errResp = post(t, endpoint, &req)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "staking_output_pk_script_hex is not the script of the staking output", errResp.Message)
+
+	// the staking tx is not on chain
+	other := newTestUnbondingWithParams(t, committee.ParsedParams, committee.Keys)
+	unbondingTx.Reset()
+	require.NoError(t, other.unbondingTx.Serialize(&unbondingTx))
+	req = valid
+	req.UnbondingTxHex = hex.EncodeToString(unbondingTx.Bytes())
+	status, errResp = post(t, endpoint, &req)
+	require.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "NOT_FOUND", errResp.ErrorCode)
+}
+
+func post(t *testing.T, endpoint string, req *covenant.SignUnbondingTxRequest) (int, *covenant.SignerErrorResponse) {
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	resp, err := http.Post(endpoint, "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var errResp covenant.SignerErrorResponse
+	if resp.StatusCode != http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	}
+	return resp.StatusCode, &errResp
+}
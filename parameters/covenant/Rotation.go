@@ -0,0 +1,149 @@
+package covenant
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+// CommitteeChange is the difference of the covenant committee between two
+// consecutive versions of the global params
+type CommitteeChange struct {
+	FromVersion uint64
+	ToVersion   uint64
+	// ActivationHeight is the height the new committee applies from
+	ActivationHeight uint64
+	Added            []*btcec.PublicKey
+	Removed          []*btcec.PublicKey
+	Kept             []*btcec.PublicKey
+	OldQuorum        uint32
+	NewQuorum        uint32
+}
+
+// Changed returns true if the keys or the quorum changed
+func (c *CommitteeChange) Changed() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0 || c.OldQuorum != c.NewQuorum
+}
+
+// CommitteePeriod is a range of consecutive versions of the global params
+// with the same covenant committee. Staking transactions included in
+// [FirstHeight, LastHeight] commit to this committee, which then governs
+// their unbonding until their time lock expires.
+type CommitteePeriod struct {
+	Versions []uint64
+	// Keys are sorted as in the staking scripts
+	Keys   []*btcec.PublicKey
+	Quorum uint32
+	// FirstHeight is the activation height of the first version
+	FirstHeight uint64
+	// LastHeight is the height before the activation of the next committee,
+	// zero if the committee is still the current one
+	LastHeight uint64
+	// UnbondingUntil is the last height at which a staking transaction of the
+	// period can still be unbonded, i.e. LastHeight plus the largest staking
+	// time of the versions, zero if the committee is the current one
+	UnbondingUntil uint64
+}
+
+// Current returns true if the committee is the one of the last version
+func (p *CommitteePeriod) Current() bool {
+	return p.LastHeight == 0
+}
+
+// RotationReport lists the committee changes between consecutive versions of
+// the global params and the periods governed by each committee
+type RotationReport struct {
+	Changes []*CommitteeChange
+	Periods []*CommitteePeriod
+}
+
+func sortedKeys(keys []*btcec.PublicKey) []*btcec.PublicKey {
+	sorted := append([]*btcec.PublicKey{}, keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].SerializeCompressed(), sorted[j].SerializeCompressed()) < 0
+	})
+	return sorted
+}
+
+func diffCommittee(from, to *parser.ParsedVersionedGlobalParams) *CommitteeChange {
+	inFrom := make(map[string]bool)
+	for _, pk := range from.CovenantPks {
+		inFrom[keyString(pk)] = true
+	}
+	inTo := make(map[string]bool)
+	for _, pk := range to.CovenantPks {
+		inTo[keyString(pk)] = true
+	}
+
+	change := &CommitteeChange{
+		FromVersion:      from.Version,
+		ToVersion:        to.Version,
+		ActivationHeight: to.ActivationHeight,
+		OldQuorum:        from.CovenantQuorum,
+		NewQuorum:        to.CovenantQuorum,
+	}
+	for _, pk := range sortedKeys(to.CovenantPks) {
+		if inFrom[keyString(pk)] {
+			change.Kept = append(change.Kept, pk)
+		} else {
+			change.Added = append(change.Added, pk)
+		}
+	}
+	for _, pk := range sortedKeys(from.CovenantPks) {
+		if !inTo[keyString(pk)] {
+			change.Removed = append(change.Removed, pk)
+		}
+	}
+	return change
+}
+
+// CommitteeRotations returns the committee changes between each pair of
+// consecutive versions, including the ones without change, and the periods
+// of versions with the same committee
+func CommitteeRotations(p *parser.ParsedGlobalParams) *RotationReport {
+	report := &RotationReport{}
+	if len(p.Versions) == 0 {
+		return report
+	}
+
+	first := p.Versions[0]
+	current := &CommitteePeriod{
+		Versions:    []uint64{first.Version},
+		Keys:        sortedKeys(first.CovenantPks),
+		Quorum:      first.CovenantQuorum,
+		FirstHeight: first.ActivationHeight,
+	}
+	maxStakingTime := uint64(first.MaxStakingTime)
+
+	for i := 1; i < len(p.Versions); i++ {
+		prev, next := p.Versions[i-1], p.Versions[i]
+		change := diffCommittee(prev, next)
+		report.Changes = append(report.Changes, change)
+
+		if !change.Changed() {
+			current.Versions = append(current.Versions, next.Version)
+			if uint64(next.MaxStakingTime) > maxStakingTime {
+				maxStakingTime = uint64(next.MaxStakingTime)
+			}
+			continue
+		}
+
+		current.LastHeight = next.ActivationHeight - 1
+		current.UnbondingUntil = current.LastHeight + maxStakingTime
+		report.Periods = append(report.Periods, current)
+
+		current = &CommitteePeriod{
+			Versions:    []uint64{next.Version},
+			Keys:        sortedKeys(next.CovenantPks),
+			Quorum:      next.CovenantQuorum,
+			FirstHeight: next.ActivationHeight,
+		}
+		maxStakingTime = uint64(next.MaxStakingTime)
+	}
+	report.Periods = append(report.Periods, current)
+
+	return report
+}
@@ -0,0 +1,102 @@
+package covenant_test
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func TestBbnTest4CommitteeRotations(t *testing.T) {
+	params, err := parser.NewParsedGlobalParamsFromFile(filepath.Join(bbnTest4Dir, covenant.GlobalParamsPath))
+	require.NoError(t, err)
+
+	report := covenant.CommitteeRotations(params)
+	require.Len(t, report.Changes, 4)
+
+	c := report.Changes[0]
+	assert.True(t, c.Changed())
+	assert.Equal(t, uint64(198665), c.ActivationHeight)
+	assert.Equal(t, []string{"0209585ab55a971a231c945790a0a81df754e5a07263a5c20829931cc24683bbb7"}, hexKeys(c.Added))
+	assert.Equal(t, []string{"0249766ccd9e3cd94343e2040474a77fb37cdfd30530d05f9f1e96ae1e2102c86e"}, hexKeys(c.Removed))
+	assert.Len(t, c.Kept, 8)
+
+	c = report.Changes[1]
+	assert.Equal(t, []string{
+		"020aee0509b16db71c999238a4827db945526859b13c95487ab46725357c9a9f25",
+		"03fa9d882d45f4060bdb8042183828cd87544f1ea997380e586cab77d5fd698737",
+	}, hexKeys(c.Added))
+	assert.Equal(t, []string{
+		"0209585ab55a971a231c945790a0a81df754e5a07263a5c20829931cc24683bbb7",
+		"0276d1ae01f8fb6bf30108731c884cddcf57ef6eef2d9d9559e130894e0e40c62c",
+	}, hexKeys(c.Removed))
+	assert.Len(t, c.Kept, 7)
+
+	assert.False(t, report.Changes[2].Changed())
+	assert.False(t, report.Changes[3].Changed())
+
+	require.Len(t, report.Periods, 3)
+	assert.Equal(t, []uint64{0}, report.Periods[0].Versions)
+	assert.Equal(t, uint64(197535), report.Periods[0].FirstHeight)
+	assert.Equal(t, uint64(198664), report.Periods[0].LastHeight)
+	assert.Equal(t, uint64(198664+64000), report.Periods[0].UnbondingUntil)
+	assert.False(t, report.Periods[0].Current())
+
+	assert.Equal(t, []uint64{1}, report.Periods[1].Versions)
+	assert.Equal(t, uint64(200664), report.Periods[1].LastHeight)
+
+	current := report.Periods[2]
+	assert.Equal(t, []uint64{2, 3, 4}, current.Versions)
+	assert.Equal(t, uint64(200665), current.FirstHeight)
+	assert.True(t, current.Current())
+	assert.Zero(t, current.UnbondingUntil)
+	assert.Equal(t, uint32(6), current.Quorum)
+
+	// the current committee is the one of the manifest
+	committee, err := covenant.LoadCommittee(bbnTest4Dir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, hexKeys(committee.Keys()), hexKeys(current.Keys))
+}
+
+func TestQuorumOnlyRotation(t *testing.T) {
+	params, err := parser.NewParsedGlobalParamsFromFile(filepath.Join(bbnTest4Dir, covenant.GlobalParamsPath))
+	require.NoError(t, err)
+
+	// the same keys in another order with a lower quorum
+	v3, v4 := *params.Versions[3], *params.Versions[4]
+	v4.CovenantPks = append([]*btcec.PublicKey{}, v4.CovenantPks...)
+	v4.CovenantPks[0], v4.CovenantPks[8] = v4.CovenantPks[8], v4.CovenantPks[0]
+	v4.CovenantQuorum = 5
+	v4.MaxStakingTime = 100
+
+	report := covenant.CommitteeRotations(&parser.ParsedGlobalParams{
+		Versions: []*parser.ParsedVersionedGlobalParams{&v3, &v4},
+	})
+	require.Len(t, report.Changes, 1)
+	c := report.Changes[0]
+	assert.True(t, c.Changed())
+	assert.Empty(t, c.Added)
+	assert.Empty(t, c.Removed)
+	assert.Len(t, c.Kept, 9)
+	assert.Equal(t, uint32(6), c.OldQuorum)
+	assert.Equal(t, uint32(5), c.NewQuorum)
+
+	require.Len(t, report.Periods, 2)
+	assert.Equal(t, v4.ActivationHeight-1, report.Periods[0].LastHeight)
+	assert.Equal(t, v4.ActivationHeight-1+uint64(v3.MaxStakingTime), report.Periods[0].UnbondingUntil)
+	assert.Equal(t, hexKeys(report.Periods[0].Keys), hexKeys(report.Periods[1].Keys))
+}
+
+func hexKeys(keys []*btcec.PublicKey) []string {
+	var s []string
+	for _, pk := range keys {
+		s = append(s, hex.EncodeToString(pk.SerializeCompressed()))
+	}
+	return s
+}
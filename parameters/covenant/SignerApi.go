@@ -0,0 +1,29 @@
+package covenant
+
+// SignUnbondingTxPath is the endpoint of the covenant signer daemons signing
+// unbonding transactions
+const SignUnbondingTxPath = "/v1/sign-unbonding-tx"
+
+// SignUnbondingTxRequest is the request of the covenant signer daemons. The
+// covenant key is the 33 bytes compressed key of the member asked to sign.
+type SignUnbondingTxRequest struct {
+	StakingOutputPkScriptHex string `json:"staking_output_pk_script_hex"`
+	UnbondingTxHex           string `json:"unbonding_tx_hex"`
+	StakerUnbondingSigHex    string `json:"staker_unbonding_sig_hex"`
+	CovenantPublicKey        string `json:"covenant_public_key"`
+}
+
+type SignUnbondingTxResponse struct {
+	SignatureHex string `json:"signature_hex"`
+}
+
+// signerResponse is the envelope of successful responses
+type signerResponse struct {
+	Data *SignUnbondingTxResponse `json:"data"`
+}
+
+// SignerErrorResponse is the body of failed responses
+type SignerErrorResponse struct {
+	ErrorCode string `json:"errorCode"`
+	Message   string `json:"message"`
+}
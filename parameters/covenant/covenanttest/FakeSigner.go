@@ -0,0 +1,285 @@
+// Package covenanttest runs fake covenant signers for the tests of the
+// clients of the committee
+package covenanttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+// Faults are the failures a fake signer injects in its responses
+type Faults struct {
+	// Delay is waited before handling each request
+	Delay time.Duration
+	// ServerErrors is the number of next requests failing with a server error
+	ServerErrors int
+	// Refuse makes the signer refuse every request
+	Refuse bool
+	// WrongSignature makes the signer sign with another key than its
+	// covenant key
+	WrongSignature bool
+}
+
+// FakeSigner serves the requests of the covenant signer daemons for tests,
+// signing with a local key. As the daemons, it looks up the staking tx spent
+// by the unbonding tx in a chain backend.
+type FakeSigner struct {
+	key     *btcec.PrivateKey
+	params  *parser.ParsedVersionedGlobalParams
+	backend btcbackend.ChainBackend
+
+	mu       sync.Mutex
+	faults   Faults
+	requests int
+}
+
+var _ http.Handler = (*FakeSigner)(nil)
+
+func NewFakeSigner(key *btcec.PrivateKey, params *parser.ParsedVersionedGlobalParams, backend btcbackend.ChainBackend) *FakeSigner {
+	return &FakeSigner{
+		key:     key,
+		params:  params,
+		backend: backend,
+	}
+}
+
+// PublicKey returns the covenant key of the signer
+func (s *FakeSigner) PublicKey() *btcec.PublicKey {
+	return s.key.PubKey()
+}
+
+// SetFaults replaces the faults injected by the signer
+func (s *FakeSigner) SetFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+}
+
+// Requests returns the number of requests received by the signer
+func (s *FakeSigner) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeSignerError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, &covenant.SignerErrorResponse{ErrorCode: code, Message: msg})
+}
+
+func (s *FakeSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != covenant.SignUnbondingTxPath {
+		writeSignerError(w, http.StatusNotFound, "NOT_FOUND", "unknown path "+r.URL.Path)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeSignerError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "only POST is allowed")
+		return
+	}
+
+	s.mu.Lock()
+	s.requests++
+	faults := s.faults
+	serverError := s.faults.ServerErrors > 0
+	if serverError {
+		s.faults.ServerErrors--
+	}
+	s.mu.Unlock()
+
+	if faults.Delay > 0 {
+		select {
+		case <-time.After(faults.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if serverError {
+		writeSignerError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", "injected server error")
+		return
+	}
+	if faults.Refuse {
+		writeSignerError(w, http.StatusBadRequest, "BAD_REQUEST", "request refused")
+		return
+	}
+
+	var req covenant.SignUnbondingTxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSignerError(w, http.StatusBadRequest, "BAD_REQUEST", "invalid request: "+err.Error())
+		return
+	}
+
+	sig, err := s.sign(r.Context(), &req, faults.WrongSignature)
+	var notFound *stakingTxNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		writeSignerError(w, http.StatusNotFound, "NOT_FOUND", err.Error())
+	case err != nil:
+		writeSignerError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+	default:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data": &covenant.SignUnbondingTxResponse{SignatureHex: hex.EncodeToString(sig.Serialize())},
+		})
+	}
+}
+
+type stakingTxNotFoundError struct {
+	outPoint wire.OutPoint
+}
+
+func (e *stakingTxNotFoundError) Error() string {
+	return fmt.Sprintf("staking tx %s is not known", e.outPoint.Hash)
+}
+
+// sign checks the request as the covenant signer daemons do and signs the
+// unbonding tx
+func (s *FakeSigner) sign(ctx context.Context, req *covenant.SignUnbondingTxRequest, wrongSignature bool) (*schnorr.Signature, error) {
+	if req.CovenantPublicKey != hex.EncodeToString(s.key.PubKey().SerializeCompressed()) {
+		return nil, fmt.Errorf("covenant_public_key %s is not the key of the signer", req.CovenantPublicKey)
+	}
+
+	txBytes, err := hex.DecodeString(req.UnbondingTxHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unbonding_tx_hex: %w", err)
+	}
+	var unbondingTx wire.MsgTx
+	if err := unbondingTx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, fmt.Errorf("invalid unbonding_tx_hex: %w", err)
+	}
+	if len(unbondingTx.TxIn) != 1 {
+		return nil, fmt.Errorf("unbonding tx should have exactly one input, got %d", len(unbondingTx.TxIn))
+	}
+
+	outPoint := unbondingTx.TxIn[0].PreviousOutPoint
+	info, err := s.backend.GetTransaction(ctx, &outPoint.Hash)
+	if errors.Is(err, btcbackend.ErrTxNotFound) {
+		return nil, &stakingTxNotFoundError{outPoint: outPoint}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := btcstaking.ParseV0StakingTx(info.Tx, s.params.Tag, s.params.CovenantPks, s.params.CovenantQuorum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking tx: %w", err)
+	}
+	if hex.EncodeToString(parsed.StakingOutput.PkScript) != req.StakingOutputPkScriptHex {
+		return nil, fmt.Errorf("staking_output_pk_script_hex is not the script of the staking output")
+	}
+	if err := btcstaking.CheckUnbondingTx(info.Tx, parsed, &unbondingTx, s.params.UnbondingFee); err != nil {
+		return nil, err
+	}
+
+	stakerSigBytes, err := hex.DecodeString(req.StakerUnbondingSigHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker_unbonding_sig_hex: %w", err)
+	}
+	stakerSig, err := schnorr.ParseSignature(stakerSigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staker_unbonding_sig_hex: %w", err)
+	}
+	if err := btcstaking.VerifyUnbondingSig(parsed, &unbondingTx, parsed.OpReturnData.StakerKey, stakerSig); err != nil {
+		return nil, fmt.Errorf("invalid staker signature: %w", err)
+	}
+
+	key := s.key
+	if wrongSignature {
+		key, err = btcec.NewPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return btcstaking.SignUnbondingTx(parsed, &unbondingTx, key)
+}
+
+// FakeCommittee is a committee of fake signers, each served by a local HTTP
+// server, for the covenant keys of generated params
+type FakeCommittee struct {
+	// Params are the given params with the generated covenant keys
+	Params       *parser.VersionedGlobalParams
+	ParsedParams *parser.ParsedVersionedGlobalParams
+	Committee    *covenant.ParsedCommittee
+	Signers      []*FakeSigner
+	Keys         []*btcec.PrivateKey
+
+	servers []*httptest.Server
+}
+
+// StartFakeCommittee generates numMembers covenant keys, sets them as the
+// covenant keys of a copy of the params, and starts a fake signer for each of
+// them. The committee should be closed once done.
+func StartFakeCommittee(params *parser.VersionedGlobalParams, numMembers int, backend btcbackend.ChainBackend) (*FakeCommittee, error) {
+	generated := *params
+	generated.CovenantPks = nil
+	var keys []*btcec.PrivateKey
+	for i := 0; i < numMembers; i++ {
+		key, err := btcec.NewPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		generated.CovenantPks = append(generated.CovenantPks, hex.EncodeToString(key.PubKey().SerializeCompressed()))
+	}
+
+	parsed, err := parser.ParseGlobalParams(&parser.GlobalParams{Versions: []*parser.VersionedGlobalParams{&generated}})
+	if err != nil {
+		return nil, err
+	}
+	parsedParams := parsed.Versions[0]
+
+	c := &FakeCommittee{
+		Params:       &generated,
+		ParsedParams: parsedParams,
+		Committee:    &covenant.ParsedCommittee{Network: "fake"},
+		Keys:         keys,
+	}
+	for i, key := range keys {
+		signer := NewFakeSigner(key, parsedParams, backend)
+		server := httptest.NewServer(signer)
+		endpoint, err := url.Parse(server.URL)
+		if err != nil {
+			server.Close()
+			c.Close()
+			return nil, err
+		}
+
+		c.servers = append(c.servers, server)
+		c.Signers = append(c.Signers, signer)
+		c.Committee.Members = append(c.Committee.Members, &covenant.ParsedMember{
+			Operator:   fmt.Sprintf("fake operator %d", i),
+			Endpoints:  []*url.URL{endpoint},
+			CovenantPk: key.PubKey(),
+		})
+	}
+
+	return c, nil
+}
+
+// Close stops the servers of the signers
+func (c *FakeCommittee) Close() {
+	for _, s := range c.servers {
+		s.Close()
+	}
+}
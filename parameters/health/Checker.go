@@ -0,0 +1,384 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/networks"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+
+	// StatusPath is the CometBFT RPC route of the status of the node
+	StatusPath = "/status"
+	// NodeInfoPath is the LCD route of the info of the node
+	NodeInfoPath = "/cosmos/base/tendermint/v1beta1/node_info"
+
+	// maximum size of a response read from an endpoint
+	maxResponseSize = 1024 * 1024
+
+	// gRPC status of a method a server does not implement
+	grpcUnimplemented = "12"
+
+	// reason a gRPC endpoint served over plain http is not probed
+	plainGRPC = "gRPC over plain http needs HTTP/2 without TLS, which the default transport does not speak"
+)
+
+// Kind is the type of an endpoint, which sets the request probing it
+type Kind string
+
+const (
+	KindRPC    Kind = "rpc"
+	KindGRPC   Kind = "grpc"
+	KindLCD    Kind = "lcd"
+	KindSigner Kind = "signer"
+)
+
+// Target is an endpoint to probe. The chain id, if set, is the network the
+// RPC and LCD nodes should report.
+type Target struct {
+	Kind    Kind
+	Owner   string
+	ChainID string
+	URL     *url.URL
+}
+
+// Result is the outcome of the probe of a target
+type Result struct {
+	Target  *Target
+	Latency time.Duration
+	// Detail describes the state reported by a healthy endpoint
+	Detail string
+	Err    error
+	// Skipped is set if the target could not be probed, with the reason in
+	// Detail
+	Skipped bool
+}
+
+func (r *Result) Healthy() bool {
+	return r.Err == nil && !r.Skipped
+}
+
+// NetworkTargets returns the RPC, gRPC and LCD endpoints of the providers of
+// the network
+func NetworkTargets(n *networks.ParsedNetwork) []*Target {
+	var targets []*Target
+	add := func(kind Kind, owner string, urls []*url.URL) {
+		for _, u := range urls {
+			targets = append(targets, &Target{Kind: kind, Owner: owner, ChainID: n.ChainID, URL: u})
+		}
+	}
+	for _, p := range n.Providers {
+		add(KindRPC, p.Name, p.RPC)
+		add(KindGRPC, p.Name, p.GRPC)
+		add(KindLCD, p.Name, p.LCD)
+	}
+	return targets
+}
+
+// CommitteeTargets returns the signer endpoints of the operators of the
+// committee
+func CommitteeTargets(c *covenant.ParsedCommittee) []*Target {
+	var targets []*Target
+	for _, o := range c.Operators {
+		for _, u := range o.Endpoints {
+			targets = append(targets, &Target{Kind: KindSigner, Owner: o.Name, URL: u})
+		}
+	}
+	return targets
+}
+
+// Checker probes endpoints with a request appropriate to their kind
+type Checker struct {
+	client *http.Client
+	// Timeout bounds the probe of each target
+	Timeout time.Duration
+	// skipPlainGRPC is set with the default transport, which cannot probe
+	// gRPC endpoints served over plain http
+	skipPlainGRPC bool
+}
+
+// NewChecker returns a checker sending its requests with the transport, or
+// with a transport negotiating HTTP/2 over TLS if nil. gRPC endpoints served
+// over plain http need HTTP/2 without TLS: the default transport does not
+// probe them, a given transport speaking it does.
+func NewChecker(transport http.RoundTripper) *Checker {
+	skipPlainGRPC := false
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.ForceAttemptHTTP2 = true
+		transport = t
+		skipPlainGRPC = true
+	}
+	return &Checker{
+		client:        &http.Client{Transport: transport},
+		Timeout:       DefaultTimeout,
+		skipPlainGRPC: skipPlainGRPC,
+	}
+}
+
+// Check probes the targets concurrently, and returns their results in the
+// order of the targets
+func (c *Checker) Check(ctx context.Context, targets []*Target) []*Result {
+	results := make([]*Result, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t *Target) {
+			defer wg.Done()
+			results[i] = c.CheckTarget(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+// CheckTarget probes a single target
+func (c *Checker) CheckTarget(ctx context.Context, t *Target) *Result {
+	if c.skipPlainGRPC && t.Kind == KindGRPC && t.URL.Scheme == "http" {
+		return &Result{Target: t, Skipped: true, Detail: plainGRPC}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	var detail string
+	var err error
+	switch t.Kind {
+	case KindRPC:
+		detail, err = c.checkRPC(ctx, t)
+	case KindGRPC:
+		detail, err = c.checkGRPC(ctx, t)
+	case KindLCD:
+		detail, err = c.checkLCD(ctx, t)
+	case KindSigner:
+		detail, err = c.checkSigner(ctx, t)
+	default:
+		err = fmt.Errorf("unknown endpoint kind %q", t.Kind)
+	}
+
+	return &Result{
+		Target:  t,
+		Latency: time.Since(start),
+		Detail:  detail,
+		Err:     err,
+	}
+}
+
+func endpointURL(u *url.URL, path string) string {
+	return strings.TrimSuffix(u.String(), "/") + path
+}
+
+// get sends a GET request and decodes the JSON response into v if not nil
+func (c *Checker) get(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+func checkNetwork(t *Target, network string) error {
+	if t.ChainID != "" && network != t.ChainID {
+		return fmt.Errorf("node is on network %q, not %s", network, t.ChainID)
+	}
+	return nil
+}
+
+// cometStatus is the part of the CometBFT /status response which is checked
+type cometStatus struct {
+	Result struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHeight string    `json:"latest_block_height"`
+			LatestBlockTime   time.Time `json:"latest_block_time"`
+			CatchingUp        bool      `json:"catching_up"`
+		} `json:"sync_info"`
+	} `json:"result"`
+}
+
+func (c *Checker) checkRPC(ctx context.Context, t *Target) (string, error) {
+	var status cometStatus
+	if err := c.get(ctx, endpointURL(t.URL, StatusPath), &status); err != nil {
+		return "", err
+	}
+	info, syncInfo := status.Result.NodeInfo, status.Result.SyncInfo
+	if err := checkNetwork(t, info.Network); err != nil {
+		return "", err
+	}
+	if syncInfo.CatchingUp {
+		return "", fmt.Errorf("node is catching up at height %s", syncInfo.LatestBlockHeight)
+	}
+	return fmt.Sprintf("%s at height %s of %s, cometbft %s", info.Network, syncInfo.LatestBlockHeight,
+		syncInfo.LatestBlockTime.Format(time.RFC3339), info.Version), nil
+}
+
+// nodeInfo is the part of the LCD node_info response which is checked
+type nodeInfo struct {
+	DefaultNodeInfo struct {
+		Network string `json:"network"`
+	} `json:"default_node_info"`
+	ApplicationVersion struct {
+		Version string `json:"version"`
+	} `json:"application_version"`
+}
+
+func (c *Checker) checkLCD(ctx context.Context, t *Target) (string, error) {
+	var info nodeInfo
+	if err := c.get(ctx, endpointURL(t.URL, NodeInfoPath), &info); err != nil {
+		return "", err
+	}
+	if err := checkNetwork(t, info.DefaultNodeInfo.Network); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s, app %s", info.DefaultNodeInfo.Network, info.ApplicationVersion.Version), nil
+}
+
+// checkSigner sends an empty signing request, which a serving signer rejects
+// with the JSON error of its API, as the signers have no health route
+func (c *Checker) checkSigner(ctx context.Context, t *Target) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL(t.URL, covenant.SignUnbondingTxPath), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", err
+	}
+	var signerErr covenant.SignerErrorResponse
+	isSignerErr := json.Unmarshal(body, &signerErr) == nil && signerErr.ErrorCode != ""
+	switch {
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && isSignerErr:
+		return fmt.Sprintf("serving, rejected an empty request with %s", signerErr.ErrorCode), nil
+	case isSignerErr:
+		return "", fmt.Errorf("returned %d %s: %s: %s", resp.StatusCode, http.StatusText(resp.StatusCode),
+			signerErr.ErrorCode, signerErr.Message)
+	case resp.StatusCode == http.StatusOK:
+		return "", fmt.Errorf("accepted an empty signing request")
+	default:
+		return "", fmt.Errorf("returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+}
+
+func (c *Checker) checkGRPC(ctx context.Context, t *Target) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpointURL(t.URL, ReflectionPath), bytes.NewReader(listServicesRequest()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if resp.ProtoMajor != 2 {
+		return "", fmt.Errorf("answered over %s, gRPC needs HTTP/2", resp.Proto)
+	}
+
+	// a response without messages has its status in the headers
+	status, msg := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, msg = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	switch status {
+	case "0":
+	case grpcUnimplemented:
+		return "serving, reflection is not enabled", nil
+	case "":
+		return "", fmt.Errorf("response has no grpc status")
+	default:
+		return "", fmt.Errorf("grpc status %s: %s", status, msg)
+	}
+
+	frames, err := readFrames(body)
+	if err != nil {
+		return "", err
+	}
+	if len(frames) == 0 {
+		return "", fmt.Errorf("reflection response has no message")
+	}
+	services, err := parseListServicesResponse(frames[0])
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d services", len(services)), nil
+}
+
+// WriteReport writes the results, and returns the number of unhealthy
+// endpoints. Endpoints which were not probed are not counted as unhealthy.
+func WriteReport(w io.Writer, results []*Result) (int, error) {
+	unhealthy, skipped := 0, 0
+	for _, r := range results {
+		t := r.Target
+		var err error
+		if r.Skipped {
+			skipped++
+			_, err = fmt.Fprintf(w, "⚠️ %-6s %s (%s): not probed, %s\n", t.Kind, t.URL, t.Owner, r.Detail)
+		} else if r.Healthy() {
+			_, err = fmt.Fprintf(w, "✅ %-6s %s (%s) in %s: %s\n", t.Kind, t.URL, t.Owner, r.Latency.Round(time.Millisecond), r.Detail)
+		} else {
+			unhealthy++
+			_, err = fmt.Fprintf(w, "❌ %-6s %s (%s): %v\n", t.Kind, t.URL, t.Owner, r.Err)
+		}
+		if err != nil {
+			return unhealthy, err
+		}
+	}
+	summary := fmt.Sprintf("%d of %d endpoints healthy", len(results)-unhealthy-skipped, len(results))
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d not probed", skipped)
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return unhealthy, err
+}
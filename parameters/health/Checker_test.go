@@ -0,0 +1,234 @@
+package health_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/covenant"
+	"github.com/babylonchain/networks/parameters/covenant/covenanttest"
+	"github.com/babylonchain/networks/parameters/health"
+	"github.com/babylonchain/networks/parameters/networks"
+)
+
+// standIn serves the routes probed by the checker under a prefix per case,
+// over HTTP/2 with TLS as gRPC needs it
+func standIn(t *testing.T) (*httptest.Server, *health.Checker) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/rpc"+health.StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":-1,"result":{
+			"node_info":{"network":"bbn-test-3","version":"0.38.5"},
+			"sync_info":{"latest_block_height":"1234","latest_block_time":"2024-02-08T14:11:01Z","catching_up":false}}}`)
+	})
+	mux.HandleFunc("/rpc-syncing"+health.StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"result":{"node_info":{"network":"bbn-test-3"},"sync_info":{"latest_block_height":"12","catching_up":true}}}`)
+	})
+	mux.HandleFunc("/rpc-other"+health.StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"result":{"node_info":{"network":"bbn-test-2"},"sync_info":{}}}`)
+	})
+	mux.HandleFunc("/lcd"+health.NodeInfoPath, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"default_node_info":{"network":"bbn-test-3"},"application_version":{"version":"0.8.4"}}`)
+	})
+	mux.HandleFunc("/lcd-down"+health.NodeInfoPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	mux.HandleFunc("/grpc"+health.ReflectionPath, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/grpc" || len(body) < 5 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		list := append(protoField(1, protoField(1, []byte("cosmos.bank.v1beta1.Query"))),
+			protoField(1, protoField(1, []byte("grpc.reflection.v1alpha.ServerReflection")))...)
+		_, _ = w.Write(grpcFrame(protoField(6, list)))
+		w.Header().Set("Grpc-Status", "0")
+	})
+	mux.HandleFunc("/grpc-no-reflection"+health.ReflectionPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "12")
+	})
+	mux.HandleFunc("/grpc-failing"+health.ReflectionPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "14")
+		w.Header().Set("Grpc-Message", "unavailable")
+	})
+	mux.HandleFunc("/signer"+covenant.SignUnbondingTxPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"errorCode":"BAD_REQUEST","message":"invalid request: EOF"}`)
+	})
+	mux.HandleFunc("/signer-failing"+covenant.SignUnbondingTxPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, `{"errorCode":"INTERNAL_SERVER_ERROR","message":"no key"}`)
+	})
+	mux.HandleFunc("/slow/", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	checker := health.NewChecker(server.Client().Transport)
+	checker.Timeout = 200 * time.Millisecond
+	return server, checker
+}
+
+func TestCheck(t *testing.T) {
+	server, checker := standIn(t)
+	target := func(kind health.Kind, prefix string) *health.Target {
+		u, err := url.Parse(server.URL + prefix)
+		require.NoError(t, err)
+		return &health.Target{Kind: kind, Owner: "stand-in", ChainID: "bbn-test-3", URL: u}
+	}
+
+	tests := []struct {
+		target *health.Target
+		detail string
+		err    string
+	}{
+		{target: target(health.KindRPC, "/rpc"), detail: "bbn-test-3 at height 1234 of 2024-02-08T14:11:01Z, cometbft 0.38.5"},
+		{target: target(health.KindRPC, "/rpc-syncing"), err: "node is catching up at height 12"},
+		{target: target(health.KindRPC, "/rpc-other"), err: `node is on network "bbn-test-2", not bbn-test-3`},
+		{target: target(health.KindLCD, "/lcd"), detail: "bbn-test-3, app 0.8.4"},
+		{target: target(health.KindLCD, "/lcd-down"), err: "returned 502 Bad Gateway"},
+		{target: target(health.KindGRPC, "/grpc"), detail: "2 services"},
+		{target: target(health.KindGRPC, "/grpc-no-reflection"), detail: "serving, reflection is not enabled"},
+		{target: target(health.KindGRPC, "/grpc-failing"), err: "grpc status 14: unavailable"},
+		{target: target(health.KindSigner, "/signer"), detail: "serving, rejected an empty request with BAD_REQUEST"},
+		{target: target(health.KindSigner, "/signer-failing"), err: "returned 500 Internal Server Error: INTERNAL_SERVER_ERROR: no key"},
+		{target: target(health.KindSigner, "/unknown"), err: "returned 404 Not Found"},
+		{target: target(health.KindSigner, "/slow"), err: "context deadline exceeded"},
+	}
+	var targets []*health.Target
+	for _, tt := range tests {
+		targets = append(targets, tt.target)
+	}
+
+	results := checker.Check(context.Background(), targets)
+	require.Len(t, results, len(tests))
+	for i, tt := range tests {
+		r := results[i]
+		assert.Equal(t, tt.target, r.Target)
+		if tt.err != "" {
+			require.Error(t, r.Err, tt.target.URL.String())
+			assert.Contains(t, r.Err.Error(), tt.err)
+			assert.False(t, r.Healthy())
+			continue
+		}
+		require.NoError(t, r.Err, tt.target.URL.String())
+		assert.Equal(t, tt.detail, r.Detail)
+	}
+
+	var report strings.Builder
+	unhealthy, err := health.WriteReport(&report, results)
+	require.NoError(t, err)
+	assert.Equal(t, 7, unhealthy)
+	assert.Contains(t, report.String(), "✅ rpc    "+server.URL+"/rpc (stand-in) in ")
+	assert.Contains(t, report.String(), "❌ lcd    "+server.URL+"/lcd-down (stand-in): returned 502 Bad Gateway\n")
+	assert.True(t, strings.HasSuffix(report.String(), "5 of 12 endpoints healthy\n"))
+}
+
+func TestCheckFakeCommittee(t *testing.T) {
+	committee, err := covenant.LoadCommittee("../../bbn-test-4")
+	require.NoError(t, err)
+	targets := health.CommitteeTargets(committee)
+	require.Len(t, targets, 9)
+	assert.Equal(t, health.KindSigner, targets[0].Kind)
+	assert.Equal(t, "Babylon Foundation", targets[0].Owner)
+
+	// a signer stand-in rejects the empty request, a closed one is down
+	signer := httptest.NewServer(covenanttest.NewFakeSigner(nil, nil, nil))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer signer.Close()
+
+	u, err := url.Parse(signer.URL)
+	require.NoError(t, err)
+	closedURL, err := url.Parse(closed.URL)
+	require.NoError(t, err)
+	results := health.NewChecker(nil).Check(context.Background(), []*health.Target{
+		{Kind: health.KindSigner, URL: u},
+		{Kind: health.KindSigner, URL: closedURL},
+	})
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "serving, rejected an empty request with BAD_REQUEST", results[0].Detail)
+	assert.False(t, results[1].Healthy())
+}
+
+func TestCheckPlainGRPC(t *testing.T) {
+	u, err := url.Parse("http://babylon-testnet-grpc.polkachu.com:20690")
+	require.NoError(t, err)
+	target := &health.Target{Kind: health.KindGRPC, Owner: "Polkachu", URL: u}
+
+	// the target is not dialed, so the result does not depend on the network
+	results := health.NewChecker(nil).Check(context.Background(), []*health.Target{target})
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Skipped)
+	assert.False(t, results[0].Healthy())
+	assert.NoError(t, results[0].Err)
+
+	var report strings.Builder
+	unhealthy, err := health.WriteReport(&report, results)
+	require.NoError(t, err)
+	assert.Equal(t, 0, unhealthy)
+	assert.Equal(t, "⚠️ grpc   http://babylon-testnet-grpc.polkachu.com:20690 (Polkachu): not probed, "+
+		"gRPC over plain http needs HTTP/2 without TLS, which the default transport does not speak\n"+
+		"0 of 1 endpoints healthy, 1 not probed\n", report.String())
+
+	// an injected transport probes the plain http endpoints, this one only
+	// speaks HTTP/1.1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	u, err = url.Parse(server.URL)
+	require.NoError(t, err)
+	results = health.NewChecker(server.Client().Transport).Check(context.Background(), []*health.Target{
+		{Kind: health.KindGRPC, URL: u},
+	})
+	assert.False(t, results[0].Skipped)
+	require.Error(t, results[0].Err)
+	assert.Contains(t, results[0].Err.Error(), "answered over HTTP/1.1, gRPC needs HTTP/2")
+}
+
+func TestNetworkTargets(t *testing.T) {
+	catalog, err := networks.LoadCatalog("../..")
+	require.NoError(t, err)
+	n, err := catalog.Network("bbn-test-3")
+	require.NoError(t, err)
+
+	targets := health.NetworkTargets(n)
+	require.Len(t, targets, 6)
+	assert.Equal(t, health.KindRPC, targets[0].Kind)
+	assert.Equal(t, "Babylon Foundation", targets[0].Owner)
+	assert.Equal(t, "bbn-test-3", targets[0].ChainID)
+	assert.Equal(t, "https://rpc.testnet3.babylonchain.io:443", targets[0].URL.String())
+	assert.Equal(t, health.KindGRPC, targets[4].Kind)
+	assert.Equal(t, "Polkachu", targets[4].Owner)
+}
+
+func protoField(num byte, data []byte) []byte {
+	field := []byte{num<<3 | 2}
+	field = binary.AppendUvarint(field, uint64(len(data)))
+	return append(field, data...)
+}
+
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5)
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// The gRPC probe lists the services of the server with the reflection
+// service. Its messages are encoded by hand to keep the module free of the
+// gRPC and protobuf libraries.
+
+// ReflectionPath is the method of the gRPC reflection service listing the
+// services of a server
+const ReflectionPath = "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"
+
+const (
+	// field numbers of grpc.reflection.v1alpha messages
+	listServicesRequestField  = 7
+	listServicesResponseField = 6
+	errorResponseField        = 7
+	serviceField              = 1
+	serviceNameField          = 1
+
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+)
+
+// listServicesRequest is the gRPC frame of a ServerReflectionRequest with
+// list_services set
+func listServicesRequest() []byte {
+	msg := []byte{listServicesRequestField<<3 | protoWireBytes, 0}
+
+	frame := make([]byte, 5, 5+len(msg))
+	// not compressed
+	frame[0] = 0
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// readFrames splits a gRPC response body into its messages
+func readFrames(body []byte) ([][]byte, error) {
+	var msgs [][]byte
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return nil, fmt.Errorf("truncated grpc frame")
+		}
+		if body[0] != 0 {
+			return nil, fmt.Errorf("compressed grpc frames are not supported")
+		}
+		size := binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(size) {
+			return nil, fmt.Errorf("truncated grpc frame")
+		}
+		msgs = append(msgs, body[5:5+size])
+		body = body[5+size:]
+	}
+	return msgs, nil
+}
+
+// forEachField calls f with the number and the content of each length
+// delimited field of a protobuf message, and skips the other fields
+func forEachField(msg []byte, f func(num uint64, data []byte)) error {
+	r := bytes.NewReader(msg)
+	for r.Len() > 0 {
+		key, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("invalid protobuf message: %w", err)
+		}
+
+		var skip uint64
+		switch key & 7 {
+		case protoWireVarint:
+			if _, err := binary.ReadUvarint(r); err != nil {
+				return fmt.Errorf("invalid protobuf message: %w", err)
+			}
+		case protoWireFixed64:
+			skip = 8
+		case protoWireFixed32:
+			skip = 4
+		case protoWireBytes:
+			size, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("invalid protobuf message: %w", err)
+			}
+			if size > uint64(r.Len()) {
+				return fmt.Errorf("invalid protobuf message: truncated field %d", key>>3)
+			}
+			data := make([]byte, size)
+			_, _ = r.Read(data)
+			f(key>>3, data)
+		default:
+			return fmt.Errorf("invalid protobuf message: unsupported wire type %d", key&7)
+		}
+		if skip > uint64(r.Len()) {
+			return fmt.Errorf("invalid protobuf message: truncated field %d", key>>3)
+		}
+		_, _ = r.Seek(int64(skip), 1)
+	}
+	return nil
+}
+
+// parseListServicesResponse returns the services listed by a
+// ServerReflectionResponse
+func parseListServicesResponse(msg []byte) ([]string, error) {
+	var list, errResp []byte
+	err := forEachField(msg, func(num uint64, data []byte) {
+		switch num {
+		case listServicesResponseField:
+			list = data
+		case errorResponseField:
+			errResp = data
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		return nil, fmt.Errorf("reflection error response")
+	}
+	if list == nil {
+		return nil, fmt.Errorf("reflection response has no service list")
+	}
+
+	var services []string
+	err = forEachField(list, func(num uint64, service []byte) {
+		if num != serviceField {
+			return
+		}
+		_ = forEachField(service, func(num uint64, name []byte) {
+			if num == serviceNameField {
+				services = append(services, string(name))
+			}
+		})
+	})
+	return services, err
+}
@@ -0,0 +1,288 @@
+package networks
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/bzip2"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// GenesisFileName is the name of the genesis in its archive
+const GenesisFileName = "genesis.json"
+
+// BtcStakingParams are the params of the btcstaking module of Babylon. The
+// staking amounts and times, the unbonding fee and min_unbonding_time_blocks
+// are only set by the releases after v0.8, which has min_unbonding_time.
+type BtcStakingParams struct {
+	// CovenantPks are BIP340 x-only keys in hex
+	CovenantPks                []string `json:"covenant_pks"`
+	CovenantQuorum             uint32   `json:"covenant_quorum"`
+	SlashingAddress            string   `json:"slashing_address"`
+	MinSlashingTxFeeSat        int64    `json:"min_slashing_tx_fee_sat,string"`
+	MinCommissionRate          string   `json:"min_commission_rate"`
+	SlashingRate               string   `json:"slashing_rate"`
+	MaxActiveFinalityProviders uint32   `json:"max_active_finality_providers"`
+	MinUnbondingTime           uint32   `json:"min_unbonding_time"`
+
+	MinStakingValueSat     *int64  `json:"min_staking_value_sat,string,omitempty"`
+	MaxStakingValueSat     *int64  `json:"max_staking_value_sat,string,omitempty"`
+	MinStakingTimeBlocks   *uint32 `json:"min_staking_time_blocks,omitempty"`
+	MaxStakingTimeBlocks   *uint32 `json:"max_staking_time_blocks,omitempty"`
+	MinUnbondingTimeBlocks *uint32 `json:"min_unbonding_time_blocks,omitempty"`
+	UnbondingFeeSat        *int64  `json:"unbonding_fee_sat,string,omitempty"`
+}
+
+// BtcCheckpointParams are the params of the btccheckpoint module of Babylon
+type BtcCheckpointParams struct {
+	BtcConfirmationDepth          uint64 `json:"btc_confirmation_depth,string"`
+	CheckpointFinalizationTimeout uint64 `json:"checkpoint_finalization_timeout,string"`
+	// CheckpointTag is the tag of the checkpoints in hex
+	CheckpointTag string `json:"checkpoint_tag"`
+}
+
+// GenesisValidator is a validator of the genesis, created by a gentx or
+// listed in the genesis state of the staking module
+type GenesisValidator struct {
+	Moniker          string
+	OperatorAddress  string
+	ConsensusPubKey  string
+	SelfDelegation   string
+	NodeAddress      string
+	CommissionRate   string
+	FromGenesisState bool
+}
+
+type coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type pubKey struct {
+	Type string `json:"@type"`
+	Key  string `json:"key"`
+}
+
+type description struct {
+	Moniker string `json:"moniker"`
+}
+
+type genTx struct {
+	Body struct {
+		Messages []struct {
+			Type        string      `json:"@type"`
+			Description description `json:"description"`
+			Commission  struct {
+				Rate string `json:"rate"`
+			} `json:"commission"`
+			ValidatorAddress string `json:"validator_address"`
+			PubKey           pubKey `json:"pubkey"`
+			Value            coin   `json:"value"`
+		} `json:"messages"`
+		// Memo is the address of the node of the validator
+		Memo string `json:"memo"`
+	} `json:"body"`
+}
+
+type stakingValidator struct {
+	OperatorAddress string      `json:"operator_address"`
+	ConsensusPubKey pubKey      `json:"consensus_pubkey"`
+	Description     description `json:"description"`
+	Tokens          string      `json:"tokens"`
+	Commission      struct {
+		CommissionRates struct {
+			Rate string `json:"rate"`
+		} `json:"commission_rates"`
+	} `json:"commission"`
+}
+
+// genesisDoc is the part of the genesis of a Babylon chain which is reported
+type genesisDoc struct {
+	AppName     string    `json:"app_name"`
+	AppVersion  string    `json:"app_version"`
+	GenesisTime time.Time `json:"genesis_time"`
+	ChainID     string    `json:"chain_id"`
+	AppState    struct {
+		BtcStaking *struct {
+			Params *BtcStakingParams `json:"params"`
+		} `json:"btcstaking"`
+		BtcCheckpoint *struct {
+			Params *BtcCheckpointParams `json:"params"`
+		} `json:"btccheckpoint"`
+		Genutil struct {
+			GenTxs []*genTx `json:"gen_txs"`
+		} `json:"genutil"`
+		Staking struct {
+			Params struct {
+				BondDenom string `json:"bond_denom"`
+			} `json:"params"`
+			Validators []*stakingValidator `json:"validators"`
+		} `json:"staking"`
+	} `json:"app_state"`
+}
+
+// Genesis is the report of a verified genesis archive
+type Genesis struct {
+	ArchiveSha256       []byte
+	AppName             string
+	AppVersion          string
+	ChainID             string
+	GenesisTime         time.Time
+	Validators          []*GenesisValidator
+	BtcStakingParams    *BtcStakingParams
+	BtcCheckpointParams *BtcCheckpointParams
+}
+
+const createValidatorMsgType = "/cosmos.staking.v1beta1.MsgCreateValidator"
+
+// ParseGenesis parses the genesis of a Babylon chain
+func ParseGenesis(r io.Reader) (*Genesis, error) {
+	var doc genesisDoc
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if doc.ChainID == "" {
+		return nil, fmt.Errorf("genesis has no chain_id")
+	}
+	if doc.AppState.BtcStaking == nil || doc.AppState.BtcStaking.Params == nil {
+		return nil, fmt.Errorf("genesis has no btcstaking params")
+	}
+	if doc.AppState.BtcCheckpoint == nil || doc.AppState.BtcCheckpoint.Params == nil {
+		return nil, fmt.Errorf("genesis has no btccheckpoint params")
+	}
+
+	g := &Genesis{
+		AppName:             doc.AppName,
+		AppVersion:          doc.AppVersion,
+		ChainID:             doc.ChainID,
+		GenesisTime:         doc.GenesisTime,
+		BtcStakingParams:    doc.AppState.BtcStaking.Params,
+		BtcCheckpointParams: doc.AppState.BtcCheckpoint.Params,
+	}
+
+	for i, tx := range doc.AppState.Genutil.GenTxs {
+		for _, msg := range tx.Body.Messages {
+			if msg.Type != createValidatorMsgType {
+				return nil, fmt.Errorf("gentx %d has a %s message, not a %s one", i, msg.Type, createValidatorMsgType)
+			}
+			g.Validators = append(g.Validators, &GenesisValidator{
+				Moniker:         msg.Description.Moniker,
+				OperatorAddress: msg.ValidatorAddress,
+				ConsensusPubKey: msg.PubKey.Key,
+				SelfDelegation:  msg.Value.Amount + msg.Value.Denom,
+				NodeAddress:     tx.Body.Memo,
+				CommissionRate:  msg.Commission.Rate,
+			})
+		}
+	}
+	for _, v := range doc.AppState.Staking.Validators {
+		g.Validators = append(g.Validators, &GenesisValidator{
+			Moniker:          v.Description.Moniker,
+			OperatorAddress:  v.OperatorAddress,
+			ConsensusPubKey:  v.ConsensusPubKey.Key,
+			SelfDelegation:   v.Tokens + doc.AppState.Staking.Params.BondDenom,
+			CommissionRate:   v.Commission.CommissionRates.Rate,
+			FromGenesisState: true,
+		})
+	}
+	if len(g.Validators) == 0 {
+		return nil, fmt.Errorf("genesis has no validators")
+	}
+
+	return g, nil
+}
+
+// ReadGenesisArchive streams the bz2 tar archive, decompressing it in memory,
+// checks its hash against the pinned one, and parses the genesis.json it
+// contains. The genesis is only returned if the hash matches.
+func ReadGenesisArchive(r io.Reader, pinnedSha256 []byte) (*Genesis, error) {
+	h := sha256.New()
+	hashed := io.TeeReader(r, h)
+
+	var g *Genesis
+	var parseErr error
+	found := false
+	archive := tar.NewReader(bzip2.NewReader(hashed))
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || path.Base(header.Name) != GenesisFileName {
+			continue
+		}
+		if found {
+			return nil, fmt.Errorf("invalid genesis archive: several %s files", GenesisFileName)
+		}
+		found = true
+
+		// parse errors are reported once the hash is known to match
+		g, err = ParseGenesis(archive)
+		if err != nil {
+			parseErr = fmt.Errorf("invalid %s: %w", header.Name, err)
+		}
+	}
+	// the hash covers the whole input, including any padding after the
+	// archive
+	if _, err := io.Copy(io.Discard, hashed); err != nil {
+		return nil, err
+	}
+
+	hash := h.Sum(nil)
+	if !bytes.Equal(hash, pinnedSha256) {
+		return nil, fmt.Errorf("genesis archive has sha256 %x, expected %x", hash, pinnedSha256)
+	}
+	if !found {
+		return nil, fmt.Errorf("invalid genesis archive: no %s", GenesisFileName)
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	g.ArchiveSha256 = hash
+	return g, nil
+}
+
+// VerifyGenesis verifies the genesis archive of the network against the hash
+// pinned by its descriptor
+func VerifyGenesis(networkDir string, n *ParsedNetwork) (*Genesis, error) {
+	if n.GenesisArchive == "" {
+		return nil, fmt.Errorf("network %s has no genesis archive", n.ChainID)
+	}
+
+	f, err := os.Open(filepath.Join(networkDir, n.GenesisArchive))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return VerifyGenesisArchive(f, n)
+}
+
+// VerifyGenesisArchive verifies a genesis archive, e.g. a downloaded copy,
+// against the hash pinned by the descriptor of the network, and checks that
+// the genesis is the one of its chain
+func VerifyGenesisArchive(r io.Reader, n *ParsedNetwork) (*Genesis, error) {
+	if n.GenesisSha256 == nil {
+		return nil, fmt.Errorf("network %s has no genesis archive", n.ChainID)
+	}
+
+	g, err := ReadGenesisArchive(r, n.GenesisSha256)
+	if err != nil {
+		return nil, err
+	}
+	if g.ChainID != n.ChainID {
+		return nil, fmt.Errorf("genesis is for chain %s, not %s", g.ChainID, n.ChainID)
+	}
+	return g, nil
+}
@@ -0,0 +1,145 @@
+package networks
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+// notInGenesis is the genesis value of a param the genesis does not set
+const notInGenesis = "not set"
+
+// ParamDifference is a param of the global params whose value differs in the
+// btcstaking params of a genesis
+type ParamDifference struct {
+	GlobalParamsField string
+	GenesisField      string
+	GlobalParams      string
+	Genesis           string
+	// AtLeast is set for the params of the global params which should be at
+	// least the minimum of the genesis, instead of equal to it
+	AtLeast bool
+}
+
+// CompareStakingParams compares the btcstaking params of a genesis with a
+// version of the global params, and returns every param which differs. Params
+// the genesis does not set differ. The covenant keys are compared as BIP340
+// keys, as the genesis drops the parity of the global params keys, and
+// regardless of their order; duplicate genesis keys differ. The unbonding
+// time of the global params should be at least the minimum of the genesis.
+func CompareStakingParams(g *BtcStakingParams, p *parser.ParsedVersionedGlobalParams) ([]*ParamDifference, error) {
+	var diffs []*ParamDifference
+	add := func(globalField, genesisField string, global, genesis interface{}) {
+		globalStr, genesisStr := fmt.Sprint(global), fmt.Sprint(genesis)
+		if globalStr != genesisStr {
+			diffs = append(diffs, &ParamDifference{
+				GlobalParamsField: globalField,
+				GenesisField:      genesisField,
+				GlobalParams:      globalStr,
+				Genesis:           genesisStr,
+			})
+		}
+	}
+
+	keysDiff, err := compareCovenantKeys(g.CovenantPks, p)
+	if err != nil {
+		return nil, err
+	}
+	if keysDiff != nil {
+		diffs = append(diffs, keysDiff)
+	}
+	add("covenant_quorum", "covenant_quorum", p.CovenantQuorum, g.CovenantQuorum)
+
+	add("min_staking_amount", "min_staking_value_sat", int64(p.MinStakingAmount), int64OrNotSet(g.MinStakingValueSat))
+	add("max_staking_amount", "max_staking_value_sat", int64(p.MaxStakingAmount), int64OrNotSet(g.MaxStakingValueSat))
+	add("min_staking_time", "min_staking_time_blocks", p.MinStakingTime, uint32OrNotSet(g.MinStakingTimeBlocks))
+	add("max_staking_time", "max_staking_time_blocks", p.MaxStakingTime, uint32OrNotSet(g.MaxStakingTimeBlocks))
+	minUnbondingField, minUnbondingTime := "min_unbonding_time", g.MinUnbondingTime
+	if g.MinUnbondingTimeBlocks != nil {
+		minUnbondingField, minUnbondingTime = "min_unbonding_time_blocks", *g.MinUnbondingTimeBlocks
+	}
+	if uint32(p.UnbondingTime) < minUnbondingTime {
+		diffs = append(diffs, &ParamDifference{
+			GlobalParamsField: "unbonding_time",
+			GenesisField:      minUnbondingField,
+			GlobalParams:      fmt.Sprint(p.UnbondingTime),
+			Genesis:           fmt.Sprint(minUnbondingTime),
+			AtLeast:           true,
+		})
+	}
+	add("unbonding_fee", "unbonding_fee_sat", int64(p.UnbondingFee), int64OrNotSet(g.UnbondingFeeSat))
+
+	return diffs, nil
+}
+
+func int64OrNotSet(v *int64) interface{} {
+	if v == nil {
+		return notInGenesis
+	}
+	return *v
+}
+
+func uint32OrNotSet(v *uint32) interface{} {
+	if v == nil {
+		return notInGenesis
+	}
+	return *v
+}
+
+func compareCovenantKeys(genesisKeys []string, p *parser.ParsedVersionedGlobalParams) (*ParamDifference, error) {
+	inGenesis := make(map[string]bool)
+	var duplicates []string
+	for _, k := range genesisKeys {
+		keyBytes, err := hex.DecodeString(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis covenant key %s: %w", k, err)
+		}
+		pk, err := schnorr.ParsePubKey(keyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid genesis covenant key %s: %w", k, err)
+		}
+		key := hex.EncodeToString(schnorr.SerializePubKey(pk))
+		if inGenesis[key] {
+			duplicates = append(duplicates, key)
+		}
+		inGenesis[key] = true
+	}
+	inParams := make(map[string]bool)
+	for _, pk := range p.CovenantPks {
+		inParams[hex.EncodeToString(schnorr.SerializePubKey(pk))] = true
+	}
+
+	var onlyGenesis, onlyParams []string
+	for k := range inGenesis {
+		if !inParams[k] {
+			onlyGenesis = append(onlyGenesis, k)
+		}
+	}
+	for k := range inParams {
+		if !inGenesis[k] {
+			onlyParams = append(onlyParams, k)
+		}
+	}
+	if len(onlyGenesis) == 0 && len(onlyParams) == 0 && len(duplicates) == 0 {
+		return nil, nil
+	}
+	sort.Strings(onlyGenesis)
+	sort.Strings(onlyParams)
+	sort.Strings(duplicates)
+
+	genesis := fmt.Sprintf("%d keys, not in the global params: [%s]", len(genesisKeys), strings.Join(onlyGenesis, ", "))
+	if len(duplicates) > 0 {
+		genesis += fmt.Sprintf(", duplicated: [%s]", strings.Join(duplicates, ", "))
+	}
+	return &ParamDifference{
+		GlobalParamsField: "covenant_pks",
+		GenesisField:      "covenant_pks",
+		GlobalParams:      fmt.Sprintf("%d keys, not in the genesis: [%s]", len(p.CovenantPks), strings.Join(onlyParams, ", ")),
+		Genesis:           genesis,
+	}, nil
+}
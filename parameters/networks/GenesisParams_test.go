@@ -0,0 +1,159 @@
+package networks_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/networks"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func TestCompareStakingParams(t *testing.T) {
+	p := lastBbnTest4Params(t)
+
+	g := genesisParamsOf(p)
+	diffs, err := networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	// the order of the keys does not matter
+	g.CovenantPks[0], g.CovenantPks[1] = g.CovenantPks[1], g.CovenantPks[0]
+	diffs, err = networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	removed := g.CovenantPks[0]
+	g.CovenantPks = g.CovenantPks[1:]
+	g.CovenantQuorum = 5
+	*g.MaxStakingValueSat = 1
+	g.MinUnbondingTimeBlocks = nil
+	g.MinUnbondingTime = uint32(p.UnbondingTime)
+	diffs, err = networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	assert.Equal(t, []*networks.ParamDifference{
+		{
+			GlobalParamsField: "covenant_pks",
+			GenesisField:      "covenant_pks",
+			GlobalParams:      "9 keys, not in the genesis: [" + removed + "]",
+			Genesis:           "8 keys, not in the global params: []",
+		},
+		{GlobalParamsField: "covenant_quorum", GenesisField: "covenant_quorum", GlobalParams: "6", Genesis: "5"},
+		{GlobalParamsField: "max_staking_amount", GenesisField: "max_staking_value_sat", GlobalParams: "50000000", Genesis: "1"},
+	}, diffs)
+
+	// the unbonding time should be at least the minimum of the genesis
+	g = genesisParamsOf(p)
+	*g.MinUnbondingTimeBlocks = uint32(p.UnbondingTime) - 1
+	diffs, err = networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+	*g.MinUnbondingTimeBlocks = uint32(p.UnbondingTime) + 1
+	diffs, err = networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	assert.Equal(t, []*networks.ParamDifference{{
+		GlobalParamsField: "unbonding_time",
+		GenesisField:      "min_unbonding_time_blocks",
+		GlobalParams:      fmt.Sprint(p.UnbondingTime),
+		Genesis:           fmt.Sprint(p.UnbondingTime + 1),
+		AtLeast:           true,
+	}}, diffs)
+
+	// duplicate genesis keys are reported
+	g = genesisParamsOf(p)
+	g.CovenantPks = append(g.CovenantPks[1:], g.CovenantPks[1])
+	diffs, err = networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, "9 keys, not in the global params: [], duplicated: ["+g.CovenantPks[0]+"]", diffs[0].Genesis)
+	g.CovenantPks = genesisParamsOf(p).CovenantPks
+	g.CovenantPks = append(g.CovenantPks, g.CovenantPks[2])
+	diffs, err = networks.CompareStakingParams(g, p)
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	assert.Equal(t, "9 keys, not in the genesis: []", diffs[0].GlobalParams)
+	assert.Equal(t, "10 keys, not in the global params: [], duplicated: ["+g.CovenantPks[2]+"]", diffs[0].Genesis)
+
+	g.CovenantPks = []string{"00"}
+	_, err = networks.CompareStakingParams(g, p)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid genesis covenant key 00")
+}
+
+func TestCompareBbnTest3GenesisParams(t *testing.T) {
+	n := loadNetwork(t, "bbn-test-3")
+	genesis, err := networks.VerifyGenesis(filepath.Join(rootDir, "bbn-test-3"), n)
+	require.NoError(t, err)
+
+	// the v0.8 genesis sets neither the staking amounts nor the staking times
+	diffs, err := networks.CompareStakingParams(genesis.BtcStakingParams, lastBbnTest4Params(t))
+	require.NoError(t, err)
+	var fields []string
+	for _, d := range diffs {
+		fields = append(fields, d.GenesisField)
+	}
+	// the unbonding time of bbn-test-4 is above the minimum of 100 blocks
+	assert.Equal(t, []string{"covenant_pks", "covenant_quorum", "min_staking_value_sat", "max_staking_value_sat",
+		"min_staking_time_blocks", "max_staking_time_blocks", "unbonding_fee_sat"}, fields)
+	assert.Equal(t, "not set", diffs[2].Genesis)
+	assert.Equal(t, uint32(100), genesis.BtcStakingParams.MinUnbondingTime)
+}
+
+func TestParseGenesisStakingParams(t *testing.T) {
+	g, err := networks.ParseGenesis(strings.NewReader(`{
+		"chain_id": "test",
+		"app_state": {
+			"btcstaking": {"params": {
+				"covenant_quorum": 6,
+				"min_staking_value_sat": "50000",
+				"max_staking_value_sat": "50000000",
+				"min_staking_time_blocks": 64000,
+				"max_staking_time_blocks": 64000,
+				"min_unbonding_time_blocks": 1008,
+				"unbonding_fee_sat": "5000"
+			}},
+			"btccheckpoint": {"params": {}},
+			"genutil": {"gen_txs": [{"body": {"messages": [{"@type": "/cosmos.staking.v1beta1.MsgCreateValidator"}]}}]}
+		}
+	}`))
+	require.NoError(t, err)
+
+	p := lastBbnTest4Params(t)
+	g.BtcStakingParams.CovenantPks = genesisParamsOf(p).CovenantPks
+	diffs, err := networks.CompareStakingParams(g.BtcStakingParams, p)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
+
+// genesisParamsOf returns the btcstaking params of a genesis agreeing with
+// the global params
+func genesisParamsOf(p *parser.ParsedVersionedGlobalParams) *networks.BtcStakingParams {
+	minValue, maxValue, fee := int64(p.MinStakingAmount), int64(p.MaxStakingAmount), int64(p.UnbondingFee)
+	minTime, maxTime, unbondingTime := uint32(p.MinStakingTime), uint32(p.MaxStakingTime), uint32(p.UnbondingTime)
+
+	g := &networks.BtcStakingParams{
+		CovenantQuorum:         p.CovenantQuorum,
+		MinStakingValueSat:     &minValue,
+		MaxStakingValueSat:     &maxValue,
+		MinStakingTimeBlocks:   &minTime,
+		MaxStakingTimeBlocks:   &maxTime,
+		MinUnbondingTimeBlocks: &unbondingTime,
+		UnbondingFeeSat:        &fee,
+	}
+	for _, pk := range p.CovenantPks {
+		g.CovenantPks = append(g.CovenantPks, hex.EncodeToString(schnorr.SerializePubKey(pk)))
+	}
+	return g
+}
+
+func lastBbnTest4Params(t *testing.T) *parser.ParsedVersionedGlobalParams {
+	params, err := parser.NewParsedGlobalParamsFromFile(filepath.Join(rootDir, "bbn-test-4", "parameters", "global-params.json"))
+	require.NoError(t, err)
+	return params.Versions[len(params.Versions)-1]
+}
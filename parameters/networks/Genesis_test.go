@@ -0,0 +1,130 @@
+package networks_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/networks"
+)
+
+func TestVerifyBbnTest3Genesis(t *testing.T) {
+	n := loadNetwork(t, "bbn-test-3")
+	g, err := networks.VerifyGenesis(filepath.Join(rootDir, "bbn-test-3"), n)
+	require.NoError(t, err)
+
+	assert.Equal(t, "bbn-test-3", g.ChainID)
+	assert.Equal(t, "v0.8.0", g.AppVersion)
+	assert.Equal(t, time.Date(2024, 2, 8, 14, 11, 1, 0, time.UTC), g.GenesisTime)
+	require.Len(t, g.Validators, 5)
+	assert.Equal(t, &networks.GenesisValidator{
+		Moniker:         "Babylon Foundation 0",
+		OperatorAddress: "bbnvaloper17npmralae7zql7zem0ksftzy8d7ccnqfkmjxtj",
+		ConsensusPubKey: "ucBSFEKwCk2uiLUOFMCkJLj8Hf1WbgHpzM9bNtAwgy4=",
+		SelfDelegation:  "7500000000000ubbn",
+		NodeAddress:     "10b483d706782dd53834eca77562e081e52b16dd@10.150.73.127:26656",
+		CommissionRate:  "0.100000000000000000",
+	}, g.Validators[0])
+
+	assert.Len(t, g.BtcStakingParams.CovenantPks, 5)
+	assert.Equal(t, uint32(3), g.BtcStakingParams.CovenantQuorum)
+	assert.Equal(t, "tb1qv03wm7hxhag6awldvwacy0z42edtt6kwljrhd9", g.BtcStakingParams.SlashingAddress)
+	assert.Equal(t, int64(1000), g.BtcStakingParams.MinSlashingTxFeeSat)
+	assert.Equal(t, uint32(80), g.BtcStakingParams.MaxActiveFinalityProviders)
+	assert.Equal(t, uint32(100), g.BtcStakingParams.MinUnbondingTime)
+	assert.Equal(t, &networks.BtcCheckpointParams{
+		BtcConfirmationDepth:          6,
+		CheckpointFinalizationTimeout: 100,
+		CheckpointTag:                 "62627433",
+	}, g.BtcCheckpointParams)
+}
+
+func TestFailReadGenesisArchive(t *testing.T) {
+	archive, err := os.ReadFile(filepath.Join(rootDir, "bbn-test-3", "genesis.tar.bz2"))
+	require.NoError(t, err)
+	n := loadNetwork(t, "bbn-test-3")
+
+	// the hash covers the bytes after the archive too
+	_, err = networks.ReadGenesisArchive(bytes.NewReader(append(archive, 0)), n.GenesisSha256)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "genesis archive has sha256")
+
+	_, err = networks.ReadGenesisArchive(bytes.NewReader(archive), make([]byte, 32))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected 0000000000000000000000000000000000000000000000000000000000000000")
+
+	_, err = networks.ReadGenesisArchive(bytes.NewReader([]byte("not an archive")), n.GenesisSha256)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid genesis archive: bzip2 data invalid")
+
+	// the genesis is the one of the chain of the descriptor
+	n.ChainID = "bbn-test-2"
+	_, err = networks.VerifyGenesis(filepath.Join(rootDir, "bbn-test-3"), n)
+	require.Error(t, err)
+	assert.Equal(t, "genesis is for chain bbn-test-3, not bbn-test-2", err.Error())
+
+	n.GenesisArchive = ""
+	_, err = networks.VerifyGenesis(filepath.Join(rootDir, "bbn-test-3"), n)
+	require.Error(t, err)
+	assert.Equal(t, "network bbn-test-2 has no genesis archive", err.Error())
+}
+
+func TestFailParseGenesis(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(g map[string]interface{})
+		err    string
+	}{
+		{"no chain id", func(g map[string]interface{}) { delete(g, "chain_id") }, "genesis has no chain_id"},
+		{"no btcstaking", func(g map[string]interface{}) { delete(appState(g), "btcstaking") }, "genesis has no btcstaking params"},
+		{"no btccheckpoint", func(g map[string]interface{}) { delete(appState(g), "btccheckpoint") }, "genesis has no btccheckpoint params"},
+		{"no validators", func(g map[string]interface{}) { delete(appState(g), "genutil") }, "genesis has no validators"},
+		{"other gentx", func(g map[string]interface{}) {
+			appState(g)["genutil"] = map[string]interface{}{"gen_txs": []interface{}{
+				map[string]interface{}{"body": map[string]interface{}{"messages": []interface{}{
+					map[string]interface{}{"@type": "/cosmos.bank.v1beta1.MsgSend"},
+				}}},
+			}}
+		}, "gentx 0 has a /cosmos.bank.v1beta1.MsgSend message, not a /cosmos.staking.v1beta1.MsgCreateValidator one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := map[string]interface{}{
+				"chain_id":     "test",
+				"genesis_time": "2024-02-08T14:11:01Z",
+				"app_state": map[string]interface{}{
+					"btcstaking":    map[string]interface{}{"params": map[string]interface{}{"covenant_quorum": 1}},
+					"btccheckpoint": map[string]interface{}{"params": map[string]interface{}{"btc_confirmation_depth": "6"}},
+					"genutil": map[string]interface{}{"gen_txs": []interface{}{
+						map[string]interface{}{"body": map[string]interface{}{"messages": []interface{}{
+							map[string]interface{}{"@type": "/cosmos.staking.v1beta1.MsgCreateValidator"},
+						}}},
+					}},
+				},
+			}
+			_, err := networks.ParseGenesis(bytes.NewReader(marshal(t, g)))
+			require.NoError(t, err)
+
+			tt.modify(g)
+			_, err = networks.ParseGenesis(bytes.NewReader(marshal(t, g)))
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func appState(g map[string]interface{}) map[string]interface{} {
+	return g["app_state"].(map[string]interface{})
+}
+
+func marshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
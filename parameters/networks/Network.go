@@ -0,0 +1,330 @@
+package networks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+const (
+	// NetworkFileName is the descriptor of a network in its directory
+	NetworkFileName = "network.json"
+	// NetworkDirPattern matches the directories of the networks in the root
+	// of the repository
+	NetworkDirPattern = "bbn-test-*"
+
+	// nodeIDLength is the length of a CometBFT node id in hex, the first 20
+	// bytes of the hash of its key
+	nodeIDLength = 40
+)
+
+// babylon releases are tagged as v0.8.4
+var babylonVersionRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// Provider is an entity running public nodes of a network
+type Provider struct {
+	Name  string   `json:"name"`
+	Seeds []string `json:"seeds"`
+	RPC   []string `json:"rpc"`
+	GRPC  []string `json:"grpc"`
+	LCD   []string `json:"lcd"`
+}
+
+// Network describes a network of the repository. Lock-only networks have no
+// Babylon chain, hence no version nor nodes.
+type Network struct {
+	ChainID        string      `json:"chain_id"`
+	BabylonVersion string      `json:"babylon_version,omitempty"`
+	BtcNetwork     string      `json:"btc_network"`
+	LockOnly       bool        `json:"lock_only,omitempty"`
+	Providers      []*Provider `json:"providers,omitempty"`
+	// GenesisArchive is the bz2 tar archive of the genesis, relative to the
+	// directory of the network, and GenesisSha256 its pinned hash
+	GenesisArchive string `json:"genesis_archive,omitempty"`
+	GenesisSha256  string `json:"genesis_sha256,omitempty"`
+	// BtcAnchor is the trusted Bitcoin block SPV proofs of the network build
+	// on. Without it, deposits cannot be verified offline.
+	BtcAnchor *spv.Anchor `json:"btc_anchor,omitempty"`
+}
+
+// Seed is a CometBFT peer address, as nodeid@host:port
+type Seed struct {
+	NodeID string
+	Host   string
+	Port   uint16
+}
+
+func (s *Seed) String() string {
+	return s.NodeID + "@" + net.JoinHostPort(s.Host, strconv.Itoa(int(s.Port)))
+}
+
+type ParsedProvider struct {
+	Name  string
+	Seeds []*Seed
+	RPC   []*url.URL
+	GRPC  []*url.URL
+	LCD   []*url.URL
+}
+
+type ParsedNetwork struct {
+	ChainID        string
+	BabylonVersion string
+	BtcNetwork     *chaincfg.Params
+	LockOnly       bool
+	Providers      []*ParsedProvider
+	GenesisArchive string
+	GenesisSha256  []byte
+	BtcAnchor      *spv.ParsedAnchor
+}
+
+// ParseSeed parses a seed in the nodeid@host:port format of CometBFT
+func ParseSeed(seed string) (*Seed, error) {
+	nodeID, address, ok := strings.Cut(seed, "@")
+	if !ok {
+		return nil, fmt.Errorf("should be nodeid@host:port")
+	}
+	if len(nodeID) != nodeIDLength || strings.ToLower(nodeID) != nodeID {
+		return nil, fmt.Errorf("node id should be %d lowercase hex characters", nodeIDLength)
+	}
+	if _, err := hex.DecodeString(nodeID); err != nil {
+		return nil, fmt.Errorf("node id should be %d lowercase hex characters", nodeIDLength)
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host should be set")
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil || port == 0 {
+		return nil, fmt.Errorf("invalid port %s", portStr)
+	}
+
+	return &Seed{NodeID: nodeID, Host: host, Port: uint16(port)}, nil
+}
+
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("should be an http or https url")
+	}
+	return u, nil
+}
+
+func parseEndpoints(provider, kind string, endpoints []string) ([]*url.URL, error) {
+	var parsed []*url.URL
+	for _, e := range endpoints {
+		u, err := parseEndpoint(e)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: invalid %s endpoint %s: %w", provider, kind, e, err)
+		}
+		parsed = append(parsed, u)
+	}
+	return parsed, nil
+}
+
+func parseProvider(p *Provider) (*ParsedProvider, error) {
+	if strings.TrimSpace(p.Name) == "" {
+		return nil, fmt.Errorf("provider name should be set")
+	}
+	if len(p.Seeds)+len(p.RPC)+len(p.GRPC)+len(p.LCD) == 0 {
+		return nil, fmt.Errorf("provider %s has no seeds nor endpoints", p.Name)
+	}
+
+	parsed := &ParsedProvider{Name: p.Name}
+	for _, s := range p.Seeds {
+		seed, err := ParseSeed(s)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: invalid seed %s: %w", p.Name, s, err)
+		}
+		parsed.Seeds = append(parsed.Seeds, seed)
+	}
+
+	var err error
+	if parsed.RPC, err = parseEndpoints(p.Name, "rpc", p.RPC); err != nil {
+		return nil, err
+	}
+	if parsed.GRPC, err = parseEndpoints(p.Name, "grpc", p.GRPC); err != nil {
+		return nil, err
+	}
+	if parsed.LCD, err = parseEndpoints(p.Name, "lcd", p.LCD); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+func ParseNetwork(n *Network) (*ParsedNetwork, error) {
+	if n.ChainID == "" {
+		return nil, fmt.Errorf("chain_id should be set")
+	}
+	btcNet, err := spv.NetParamsByName(n.BtcNetwork)
+	if err != nil {
+		return nil, fmt.Errorf("invalid btc_network: %w", err)
+	}
+	var anchor *spv.ParsedAnchor
+	if n.BtcAnchor != nil {
+		if anchor, err = spv.ParseAnchor(n.BtcAnchor, btcNet); err != nil {
+			return nil, fmt.Errorf("invalid btc_anchor: %w", err)
+		}
+	}
+
+	if n.LockOnly {
+		if n.BabylonVersion != "" || len(n.Providers) > 0 || n.GenesisArchive != "" {
+			return nil, fmt.Errorf("lock-only network %s has no babylon chain, so no babylon_version, providers nor genesis", n.ChainID)
+		}
+		return &ParsedNetwork{ChainID: n.ChainID, BtcNetwork: btcNet, LockOnly: true, BtcAnchor: anchor}, nil
+	}
+
+	if !babylonVersionRegex.MatchString(n.BabylonVersion) {
+		return nil, fmt.Errorf("invalid babylon_version %q, should be a release tag such as v0.8.4", n.BabylonVersion)
+	}
+	if len(n.Providers) == 0 {
+		return nil, fmt.Errorf("network %s has no providers", n.ChainID)
+	}
+
+	parsed := &ParsedNetwork{
+		ChainID:        n.ChainID,
+		BabylonVersion: n.BabylonVersion,
+		BtcNetwork:     btcNet,
+		GenesisArchive: n.GenesisArchive,
+		BtcAnchor:      anchor,
+	}
+	if n.GenesisArchive != "" || n.GenesisSha256 != "" {
+		if n.GenesisArchive == "" || filepath.IsAbs(n.GenesisArchive) {
+			return nil, fmt.Errorf("genesis_archive should be a path relative to the network directory")
+		}
+		hash, err := hex.DecodeString(n.GenesisSha256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("genesis_sha256 should be a sha256 hash in hex")
+		}
+		parsed.GenesisSha256 = hash
+	}
+
+	names := make(map[string]bool)
+	seeds := 0
+	for _, p := range n.Providers {
+		provider, err := parseProvider(p)
+		if err != nil {
+			return nil, err
+		}
+		if names[provider.Name] {
+			return nil, fmt.Errorf("duplicate provider %s", provider.Name)
+		}
+		names[provider.Name] = true
+		seeds += len(provider.Seeds)
+		parsed.Providers = append(parsed.Providers, provider)
+	}
+	if seeds == 0 {
+		return nil, fmt.Errorf("network %s has no seeds", n.ChainID)
+	}
+
+	return parsed, nil
+}
+
+func NewNetworkFromFile(filePath string) (*ParsedNetwork, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNetworkFromBytes(data)
+}
+
+func NewNetworkFromBytes(data []byte) (*ParsedNetwork, error) {
+	var n Network
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, fmt.Errorf("invalid network descriptor: %w", err)
+	}
+
+	parsed, err := ParseNetwork(&n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network descriptor: %w", err)
+	}
+	return parsed, nil
+}
+
+// Provider returns the provider of the given name, or nil
+func (n *ParsedNetwork) Provider(name string) *ParsedProvider {
+	for _, p := range n.Providers {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Catalog is the set of networks described in the repository
+type Catalog struct {
+	Networks []*ParsedNetwork
+}
+
+// LoadCatalog loads the descriptor of every network directory of the root of
+// the repository. The chain id of each descriptor should be the name of its
+// directory.
+func LoadCatalog(rootDir string) (*Catalog, error) {
+	dirs, err := filepath.Glob(filepath.Join(rootDir, NetworkDirPattern))
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			continue
+		}
+
+		n, err := NewNetworkFromFile(filepath.Join(dir, NetworkFileName))
+		if err != nil {
+			return nil, fmt.Errorf("network %s: %w", filepath.Base(dir), err)
+		}
+		if n.ChainID != filepath.Base(dir) {
+			return nil, fmt.Errorf("network %s: descriptor is for chain %s", filepath.Base(dir), n.ChainID)
+		}
+		catalog.Networks = append(catalog.Networks, n)
+	}
+	if len(catalog.Networks) == 0 {
+		return nil, fmt.Errorf("no networks in %s", rootDir)
+	}
+
+	return catalog, nil
+}
+
+// ChainIDs returns the chain ids of the networks
+func (c *Catalog) ChainIDs() []string {
+	ids := make([]string, len(c.Networks))
+	for i, n := range c.Networks {
+		ids[i] = n.ChainID
+	}
+	return ids
+}
+
+// Network returns the network of the chain id
+func (c *Catalog) Network(chainID string) (*ParsedNetwork, error) {
+	for _, n := range c.Networks {
+		if n.ChainID == chainID {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown network %s, known networks are %s", chainID, strings.Join(c.ChainIDs(), ", "))
+}
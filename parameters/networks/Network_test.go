@@ -0,0 +1,190 @@
+package networks_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/networks"
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+const rootDir = "../.."
+
+func TestLoadCatalog(t *testing.T) {
+	catalog, err := networks.LoadCatalog(rootDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bbn-test-3", "bbn-test-4"}, catalog.ChainIDs())
+
+	n, err := catalog.Network("bbn-test-3")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.8.4", n.BabylonVersion)
+	assert.Equal(t, &chaincfg.SigNetParams, n.BtcNetwork)
+	assert.False(t, n.LockOnly)
+	require.Len(t, n.Providers, 2)
+
+	polkachu := n.Provider("Polkachu")
+	require.NotNil(t, polkachu)
+	require.Len(t, polkachu.Seeds, 1)
+	assert.Equal(t, "ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0", polkachu.Seeds[0].NodeID)
+	assert.Equal(t, "testnet-seeds.polkachu.com", polkachu.Seeds[0].Host)
+	assert.Equal(t, uint16(20656), polkachu.Seeds[0].Port)
+	assert.Equal(t, "http://babylon-testnet-grpc.polkachu.com:20690", polkachu.GRPC[0].String())
+	assert.Nil(t, n.Provider("unknown"))
+
+	n, err = catalog.Network("bbn-test-4")
+	require.NoError(t, err)
+	assert.True(t, n.LockOnly)
+	assert.Empty(t, n.Providers)
+	// the signet genesis block is pinned, so its deposits can be verified
+	// offline
+	assert.Equal(t, spv.GenesisAnchor(&chaincfg.SigNetParams), n.BtcAnchor)
+
+	_, err = catalog.Network("bbn-test-2")
+	require.Error(t, err)
+	assert.Equal(t, "unknown network bbn-test-2, known networks are bbn-test-3, bbn-test-4", err.Error())
+}
+
+// the descriptor lists the same nodes as the README of the network
+func TestBbnTest3DescriptorMatchesReadme(t *testing.T) {
+	readme, err := os.ReadFile(filepath.Join(rootDir, "bbn-test-3", "README.md"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(rootDir, "bbn-test-3", networks.NetworkFileName))
+	require.NoError(t, err)
+	var n networks.Network
+	require.NoError(t, json.Unmarshal(data, &n))
+
+	var listed []string
+	for _, line := range strings.Split(string(readme), "\n") {
+		if strings.HasPrefix(line, "- `") {
+			listed = append(listed, strings.Trim(strings.TrimPrefix(line, "- "), "`"))
+		}
+	}
+
+	var described []string
+	for _, p := range n.Providers {
+		described = append(described, p.Seeds...)
+		described = append(described, p.RPC...)
+		described = append(described, p.GRPC...)
+		described = append(described, p.LCD...)
+	}
+	assert.ElementsMatch(t, listed, described)
+	assert.Contains(t, string(readme), "- Network: `"+n.BtcNetwork+"`")
+	assert.Contains(t, string(readme), "Version ["+n.BabylonVersion+"]")
+}
+
+func TestParseSeed(t *testing.T) {
+	seed, err := networks.ParseSeed("49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:26656")
+	require.NoError(t, err)
+	assert.Equal(t, "3.14.89.82", seed.Host)
+	assert.Equal(t, uint16(26656), seed.Port)
+	assert.Equal(t, "49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:26656", seed.String())
+
+	tests := []struct {
+		seed string
+		err  string
+	}{
+		{"3.14.89.82:26656", "should be nodeid@host:port"},
+		{"49b4685f@3.14.89.82:26656", "node id should be 40 lowercase hex characters"},
+		{"49B4685F16670E784A0FE78F37CD37D56C7AFF0E@3.14.89.82:26656", "node id should be 40 lowercase hex characters"},
+		{"zzb4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:26656", "node id should be 40 lowercase hex characters"},
+		{"49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82", "address 3.14.89.82: missing port in address"},
+		{"49b4685f16670e784a0fe78f37cd37d56c7aff0e@:26656", "host should be set"},
+		{"49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:65536", "invalid port 65536"},
+		{"49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:0", "invalid port 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.seed, func(t *testing.T) {
+			_, err := networks.ParseSeed(tt.seed)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestFailNetworkParsing(t *testing.T) {
+	valid := func() *networks.Network {
+		return &networks.Network{
+			ChainID:        "bbn-test-3",
+			BabylonVersion: "v0.8.4",
+			BtcNetwork:     "signet",
+			Providers: []*networks.Provider{{
+				Name:  "provider",
+				Seeds: []string{"49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:26656"},
+				RPC:   []string{"https://rpc.testnet3.babylonchain.io:443"},
+			}},
+		}
+	}
+	_, err := networks.ParseNetwork(valid())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(n *networks.Network)
+		err    string
+	}{
+		{"no chain id", func(n *networks.Network) { n.ChainID = "" }, "chain_id should be set"},
+		{"unknown btc network", func(n *networks.Network) { n.BtcNetwork = "litecoin" },
+			`invalid btc_network: unsupported network "litecoin"`},
+		{"invalid version", func(n *networks.Network) { n.BabylonVersion = "0.8.4" },
+			`invalid babylon_version "0.8.4", should be a release tag such as v0.8.4`},
+		{"no providers", func(n *networks.Network) { n.Providers = nil }, "network bbn-test-3 has no providers"},
+		{"no seeds", func(n *networks.Network) { n.Providers[0].Seeds = nil }, "network bbn-test-3 has no seeds"},
+		{"empty provider", func(n *networks.Network) {
+			n.Providers = append(n.Providers, &networks.Provider{Name: "empty"})
+		}, "provider empty has no seeds nor endpoints"},
+		{"duplicate provider", func(n *networks.Network) {
+			n.Providers = append(n.Providers, n.Providers[0])
+		}, "duplicate provider provider"},
+		{"invalid seed", func(n *networks.Network) { n.Providers[0].Seeds = []string{"3.14.89.82:26656"} },
+			"provider provider: invalid seed 3.14.89.82:26656: should be nodeid@host:port"},
+		{"invalid rpc scheme", func(n *networks.Network) { n.Providers[0].RPC = []string{"tcp://3.14.89.82:26657"} },
+			"provider provider: invalid rpc endpoint tcp://3.14.89.82:26657: should be an http or https url"},
+		{"invalid grpc url", func(n *networks.Network) { n.Providers[0].GRPC = []string{"grpc.testnet3.babylonchain.io:443"} },
+			"provider provider: invalid grpc endpoint grpc.testnet3.babylonchain.io:443: should be an http or https url"},
+		{"lock-only with providers", func(n *networks.Network) { n.LockOnly = true },
+			"lock-only network bbn-test-3 has no babylon chain, so no babylon_version, providers nor genesis"},
+		{"genesis without hash", func(n *networks.Network) { n.GenesisArchive = "genesis.tar.bz2" },
+			"genesis_sha256 should be a sha256 hash in hex"},
+		{"hash without genesis", func(n *networks.Network) { n.GenesisSha256 = strings.Repeat("00", 32) },
+			"genesis_archive should be a path relative to the network directory"},
+		{"invalid btc anchor", func(n *networks.Network) {
+			n.BtcAnchor = &spv.Anchor{Hash: chaincfg.SigNetParams.GenesisHash.String(), Bits: "207fffff"}
+		}, "invalid btc_anchor: anchor bits 207fffff are not a valid signet target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := valid()
+			tt.modify(n)
+			_, err := networks.ParseNetwork(n)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestFailLoadCatalog(t *testing.T) {
+	root := t.TempDir()
+	_, err := networks.LoadCatalog(root)
+	require.Error(t, err)
+	assert.Equal(t, "no networks in "+root, err.Error())
+
+	dir := filepath.Join(root, "bbn-test-5")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	_, err = networks.LoadCatalog(root)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "network bbn-test-5: open")
+
+	data, err := json.Marshal(&networks.Network{ChainID: "bbn-test-4", BtcNetwork: "signet", LockOnly: true})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, networks.NetworkFileName), data, 0644))
+	_, err = networks.LoadCatalog(root)
+	require.Error(t, err)
+	assert.Equal(t, "network bbn-test-5: descriptor is for chain bbn-test-4", err.Error())
+}
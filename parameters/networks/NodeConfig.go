@@ -0,0 +1,118 @@
+package networks
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// SelectProviders returns the providers of the given names, in the given
+// order, or every provider of the network if no names are given
+func (n *ParsedNetwork) SelectProviders(names []string) ([]*ParsedProvider, error) {
+	if n.LockOnly {
+		return nil, fmt.Errorf("network %s is lock-only and has no nodes", n.ChainID)
+	}
+	if len(names) == 0 {
+		return n.Providers, nil
+	}
+
+	var selected []*ParsedProvider
+	for _, name := range names {
+		p := n.Provider(name)
+		if p == nil {
+			return nil, fmt.Errorf("network %s has no provider %s, its providers are %s", n.ChainID, name, strings.Join(n.providerNames(), ", "))
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+func (n *ParsedNetwork) providerNames() []string {
+	names := make([]string, len(n.Providers))
+	for i, p := range n.Providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func joinSeeds(providers []*ParsedProvider) string {
+	var seeds []string
+	for _, p := range providers {
+		for _, s := range p.Seeds {
+			seeds = append(seeds, s.String())
+		}
+	}
+	return strings.Join(seeds, ",")
+}
+
+// WriteP2PConfig writes the [p2p] section of the CometBFT config.toml of a
+// node of the network, with the seeds of the providers. Seeds are not
+// persistent peers and the descriptor lists none, so persistent_peers is left
+// to the operator of the node.
+func WriteP2PConfig(w io.Writer, n *ParsedNetwork, providers []*ParsedProvider) error {
+	_, err := fmt.Fprintf(w, "# %s, babylon %s\n[p2p]\nseeds = %s\n",
+		n.ChainID, n.BabylonVersion, strconv.Quote(joinSeeds(providers)))
+	return err
+}
+
+// ClientConfig is the configuration of a client of the network connecting to
+// the nodes of a provider
+type ClientConfig struct {
+	ChainID string
+	// Node is the CometBFT RPC endpoint
+	Node string
+	// GRPCAddress is the host:port of the gRPC endpoint, insecure if served
+	// over http
+	GRPCAddress  string
+	GRPCInsecure bool
+}
+
+// ClientConfig returns the configuration of a client connecting to the first
+// RPC and gRPC endpoints of the provider, or of the first provider serving
+// both if the name is empty
+func (n *ParsedNetwork) ClientConfig(providerName string) (*ClientConfig, error) {
+	var names []string
+	if providerName != "" {
+		names = []string{providerName}
+	}
+	providers, err := n.SelectProviders(names)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range providers {
+		if len(p.RPC) == 0 || len(p.GRPC) == 0 {
+			continue
+		}
+
+		grpc := p.GRPC[0]
+		port := grpc.Port()
+		if port == "" {
+			port = "443"
+			if grpc.Scheme == "http" {
+				port = "80"
+			}
+		}
+		return &ClientConfig{
+			ChainID:      n.ChainID,
+			Node:         p.RPC[0].String(),
+			GRPCAddress:  net.JoinHostPort(grpc.Hostname(), port),
+			GRPCInsecure: grpc.Scheme == "http",
+		}, nil
+	}
+
+	if providerName != "" {
+		return nil, fmt.Errorf("provider %s of network %s has no rpc and grpc endpoints", providerName, n.ChainID)
+	}
+	return nil, fmt.Errorf("no provider of network %s has rpc and grpc endpoints", n.ChainID)
+}
+
+// Write writes the configuration in the TOML format of the client.toml of
+// the Cosmos SDK
+func (c *ClientConfig) Write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "chain-id = %s\nnode = %s\ngrpc-address = %s\ngrpc-insecure = %t\n",
+		strconv.Quote(c.ChainID), strconv.Quote(c.Node), strconv.Quote(c.GRPCAddress), c.GRPCInsecure)
+	return err
+}
@@ -0,0 +1,75 @@
+package networks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/networks"
+)
+
+func TestWriteP2PConfig(t *testing.T) {
+	n := loadNetwork(t, "bbn-test-3")
+
+	all, err := n.SelectProviders(nil)
+	require.NoError(t, err)
+
+	var b strings.Builder
+	require.NoError(t, networks.WriteP2PConfig(&b, n, all))
+	assert.Equal(t, `# bbn-test-3, babylon v0.8.4
+[p2p]
+seeds = "49b4685f16670e784a0fe78f37cd37d56c7aff0e@3.14.89.82:26656,9cb1974618ddd541c9a4f4562b842b96ffaf1446@3.16.63.237:26656,ade4d8bc8cbe014af6ebdf3cb7b1e9ad36f412c0@testnet-seeds.polkachu.com:20656"
+`, b.String())
+
+	babylon, err := n.SelectProviders([]string{"Babylon Foundation"})
+	require.NoError(t, err)
+	b.Reset()
+	require.NoError(t, networks.WriteP2PConfig(&b, n, babylon))
+	assert.NotContains(t, b.String(), "polkachu")
+	assert.NotContains(t, b.String(), "persistent_peers")
+
+	_, err = n.SelectProviders([]string{"Unknown"})
+	require.Error(t, err)
+	assert.Equal(t, "network bbn-test-3 has no provider Unknown, its providers are Babylon Foundation, Polkachu", err.Error())
+
+	_, err = loadNetwork(t, "bbn-test-4").SelectProviders(nil)
+	require.Error(t, err)
+	assert.Equal(t, "network bbn-test-4 is lock-only and has no nodes", err.Error())
+}
+
+func TestClientConfig(t *testing.T) {
+	n := loadNetwork(t, "bbn-test-3")
+
+	c, err := n.ClientConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, &networks.ClientConfig{
+		ChainID:     "bbn-test-3",
+		Node:        "https://rpc.testnet3.babylonchain.io:443",
+		GRPCAddress: "grpc.testnet3.babylonchain.io:443",
+	}, c)
+
+	c, err = n.ClientConfig("Polkachu")
+	require.NoError(t, err)
+	var b strings.Builder
+	require.NoError(t, c.Write(&b))
+	assert.Equal(t, `chain-id = "bbn-test-3"
+node = "https://babylon-testnet-rpc.polkachu.com:443"
+grpc-address = "babylon-testnet-grpc.polkachu.com:20690"
+grpc-insecure = true
+`, b.String())
+
+	n.Providers[1].GRPC = nil
+	_, err = n.ClientConfig("Polkachu")
+	require.Error(t, err)
+	assert.Equal(t, "provider Polkachu of network bbn-test-3 has no rpc and grpc endpoints", err.Error())
+}
+
+func loadNetwork(t *testing.T, chainID string) *networks.ParsedNetwork {
+	catalog, err := networks.LoadCatalog(rootDir)
+	require.NoError(t, err)
+	n, err := catalog.Network(chainID)
+	require.NoError(t, err)
+	return n
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+// Warning is an issue of a version of the global params which does not make
+// the params invalid, but most likely is a mistake
+type Warning struct {
+	Version uint64
+	Message string
+}
+
+func (w *Warning) String() string {
+	return fmt.Sprintf("version %d: %s", w.Version, w.Message)
+}
+
+// LintGlobalParams returns the warnings of every version of the params. The
+// BIP341 NUMS key is only meant to disable the covenant paths of deposits, a
+// real covenant committee including it has one less member able to sign.
+func LintGlobalParams(p *ParsedGlobalParams) []*Warning {
+	var warnings []*Warning
+	for _, v := range p.Versions {
+		numsKeys := 0
+		for _, pk := range v.CovenantPks {
+			if btcstaking.IsNumsKey(pk) {
+				numsKeys++
+				warnings = append(warnings, &Warning{
+					Version: v.Version,
+					Message: fmt.Sprintf("covenant key %x is the BIP341 NUMS point, it has no known private key and cannot sign",
+						schnorr.SerializePubKey(pk)),
+				})
+			}
+		}
+
+		signers := len(v.CovenantPks) - numsKeys
+		if numsKeys > 0 && signers < int(v.CovenantQuorum) {
+			warnings = append(warnings, &Warning{
+				Version: v.Version,
+				Message: fmt.Sprintf("only %d of the %d covenant keys can sign, the covenant quorum %d cannot be reached",
+					signers, len(v.CovenantPks), v.CovenantQuorum),
+			})
+		}
+	}
+
+	return warnings
+}
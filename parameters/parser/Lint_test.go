@@ -0,0 +1,50 @@
+package parser_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/parser"
+)
+
+func TestLintBbnTest4Params(t *testing.T) {
+	globalParams, err := parser.NewParsedGlobalParamsFromFile("../../bbn-test-4/parameters/global-params.json")
+	require.NoError(t, err)
+	assert.Empty(t, parser.LintGlobalParams(globalParams))
+}
+
+func TestLintNumsCovenantKey(t *testing.T) {
+	var clonedParams parser.GlobalParams
+	deepCopy(&parser.GlobalParams{Versions: []*parser.VersionedGlobalParams{&defaultParam}}, &clonedParams)
+
+	// 5 covenants with a quorum of 3
+	clonedParams.Versions[0].CovenantPks[1] = "02" + btcstaking.NumsKeyHex
+	globalParams, err := parser.ParseGlobalParams(&clonedParams)
+	require.NoError(t, err)
+	warnings := parser.LintGlobalParams(globalParams)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "version 0: covenant key "+btcstaking.NumsKeyHex+" is the BIP341 NUMS point, it has no known private key and cannot sign",
+		warnings[0].String())
+
+	// the quorum cannot be reached anymore
+	clonedParams.Versions[0].CovenantPks[2] = "03" + btcstaking.NumsKeyHex
+	clonedParams.Versions[0].CovenantPks[3] = randomCompressedKey(t)
+	clonedParams.Versions[0].CovenantQuorum = 4
+	globalParams, err = parser.ParseGlobalParams(&clonedParams)
+	require.NoError(t, err)
+	warnings = parser.LintGlobalParams(globalParams)
+	require.Len(t, warnings, 3)
+	assert.Equal(t, "version 0: only 3 of the 5 covenant keys can sign, the covenant quorum 4 cannot be reached",
+		warnings[2].String())
+}
+
+func randomCompressedKey(t *testing.T) string {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	return fmt.Sprintf("%x", privKey.PubKey().SerializeCompressed())
+}
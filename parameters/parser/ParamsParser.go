@@ -327,6 +327,20 @@ func (g *ParsedGlobalParams) GetVersionedGlobalParamsByHeight(btcHeight uint64)
 	return nil
 }
 
+// SelectVersion returns the parsed versioned global params of the given
+// version, or of the last version if the version is negative.
+func (g *ParsedGlobalParams) SelectVersion(version int64) (*ParsedVersionedGlobalParams, error) {
+	if version < 0 {
+		return g.Versions[len(g.Versions)-1], nil
+	}
+	for _, v := range g.Versions {
+		if v.Version == uint64(version) {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("global params have no version %d", version)
+}
+
 // FindLastStakingCap finds the last staking cap that is not zero
 // it returns zero if not non-zero value is found
 func FindLastStakingCap(prevVersions []*VersionedGlobalParams) uint64 {
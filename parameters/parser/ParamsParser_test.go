@@ -198,6 +198,23 @@ func TestReadBbnTest4Params(t *testing.T) {
 	require.NotNil(t, globalParams)
 }
 
+func TestSelectVersion(t *testing.T) {
+	globalParams, err := parser.NewParsedGlobalParamsFromFile("../../bbn-test-4/parameters/global-params.json")
+	require.NoError(t, err)
+
+	last, err := globalParams.SelectVersion(-1)
+	require.NoError(t, err)
+	require.Equal(t, globalParams.Versions[len(globalParams.Versions)-1], last)
+
+	first, err := globalParams.SelectVersion(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), first.Version)
+
+	_, err = globalParams.SelectVersion(int64(len(globalParams.Versions)))
+	require.Error(t, err)
+	require.Equal(t, fmt.Sprintf("global params have no version %d", len(globalParams.Versions)), err.Error())
+}
+
 var defaultParam = parser.VersionedGlobalParams{
 	Version:          0,
 	ActivationHeight: 100,
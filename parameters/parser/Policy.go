@@ -0,0 +1,22 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+// StakingTxPolicy returns the policy staking transactions of this version of
+// the global params are inspected against
+func (p *ParsedVersionedGlobalParams) StakingTxPolicy() *btcstaking.StakingTxPolicy {
+	return &btcstaking.StakingTxPolicy{
+		Name:             fmt.Sprintf("global params version %d", p.Version),
+		Tag:              p.Tag,
+		CovenantKeys:     p.CovenantPks,
+		CovenantQuorum:   p.CovenantQuorum,
+		MinStakingTime:   p.MinStakingTime,
+		MaxStakingTime:   p.MaxStakingTime,
+		MinStakingAmount: p.MinStakingAmount,
+		MaxStakingAmount: p.MaxStakingAmount,
+	}
+}
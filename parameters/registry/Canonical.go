@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// marshalNoEscape marshals v without escaping HTML characters, which
+// json.Marshal does by default
+func marshalNoEscape(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	// Encode always terminates the value with a new line
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// CanonicalJSON encodes v as JSON with object keys sorted and without any
+// insignificant white space, so that two semantically equal values always
+// encode to the same bytes
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// decoding into a generic value and encoding it again sorts the object keys
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return marshalNoEscape(generic)
+}
+
+// Canonical returns a copy of the entry in canonical form: hex strings are
+// lower case and the commission is in its normalised sdk.Dec form
+func (fp *FinalityProvider) Canonical() (*FinalityProvider, error) {
+	commission, err := ParseCommission(fp.Commission)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commission: %w", err)
+	}
+
+	canonical := *fp
+	canonical.BtcPk = strings.ToLower(fp.BtcPk)
+	canonical.Commission = commission.String()
+	canonical.Deposit.TxHash = strings.ToLower(fp.Deposit.TxHash)
+	canonical.Deposit.SignedTx = strings.ToLower(fp.Deposit.SignedTx)
+
+	return &canonical, nil
+}
+
+// CanonicalBytes returns the canonical JSON encoding of the canonical form of
+// the entry. Reformatting the registry file does not change these bytes.
+func (fp *FinalityProvider) CanonicalBytes() ([]byte, error) {
+	canonical, err := fp.Canonical()
+	if err != nil {
+		return nil, err
+	}
+
+	return CanonicalJSON(canonical)
+}
@@ -0,0 +1,59 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	data, err := registry.CanonicalJSON(map[string]interface{}{
+		"b": "<b>&",
+		"a": []interface{}{1, "x", map[string]int{"z": 1, "y": 2}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":[1,"x",{"y":2,"z":1}],"b":"<b>&"}`, string(data))
+}
+
+func TestCanonicalBytesIgnoreFormatting(t *testing.T) {
+	original, err := registry.NewFinalityProviderFromBytes([]byte(`{
+  "description": {
+    "moniker": "my fp",
+    "identity": "",
+    "website": "https://fp.io",
+    "security_contact": "security@fp.io",
+    "details": "<3 & more"
+  },
+  "btc_pk": "A89E7CAF57360BC8B791DF72ABC3FB6D2DDC0E06E171C9F17C4EA1299E677565",
+  "commission": "0.1",
+  "deposit": {
+    "tx_hash": "f22b9a1892df0e50977455b85b65324b079a9f230c5a9dede5ac711b9415d15b",
+    "signed_tx": "02000000"
+  }
+}
+`))
+	require.NoError(t, err)
+
+	reformatted, err := registry.NewFinalityProviderFromBytes([]byte(`{"btc_pk":"a89e7caf57360bc8b791df72abc3fb6d2ddc0e06e171c9f17c4ea1299e677565",
+	"commission":"0.100","deposit":{"signed_tx":"02000000","tx_hash":"f22b9a1892df0e50977455b85b65324b079a9f230c5a9dede5ac711b9415d15b"},
+	"description":{"details":"<3 & more","identity":"","moniker":"my fp","security_contact":"security@fp.io","website":"https://fp.io"}}`))
+	require.NoError(t, err)
+
+	c1, err := original.CanonicalBytes()
+	require.NoError(t, err)
+	c2, err := reformatted.CanonicalBytes()
+	require.NoError(t, err)
+	assert.Equal(t, c1, c2)
+	assert.Equal(t, `{"btc_pk":"a89e7caf57360bc8b791df72abc3fb6d2ddc0e06e171c9f17c4ea1299e677565",`+
+		`"commission":"0.100000000000000000",`+
+		`"deposit":{"signed_tx":"02000000","tx_hash":"f22b9a1892df0e50977455b85b65324b079a9f230c5a9dede5ac711b9415d15b"},`+
+		`"description":{"details":"<3 & more","identity":"","moniker":"my fp","security_contact":"security@fp.io","website":"https://fp.io"}}`,
+		string(c1))
+
+	reformatted.Commission = "zero"
+	_, err = reformatted.CanonicalBytes()
+	assert.Equal(t, `invalid commission: invalid decimal "zero"`, err.Error())
+}
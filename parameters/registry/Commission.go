@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// CommissionPrecision is the number of decimal places of a commission, the
+	// same precision used by the Cosmos SDK sdk.Dec
+	CommissionPrecision = 18
+
+	// commissionUnit is the integer representation of a commission of 1 (100%)
+	commissionUnit uint64 = 1_000_000_000_000_000_000
+)
+
+// Commission is a fixed point decimal in the range [0, 1] with 18 decimal
+// places, following the parsing semantics of the Cosmos SDK sdk.Dec.
+// It is stored as an integer amount of 10^-18 units, which always fits in
+// an uint64 given the range of valid commissions.
+type Commission struct {
+	units uint64
+}
+
+var (
+	ZeroCommission = Commission{units: 0}
+	MaxCommission  = Commission{units: commissionUnit}
+)
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseCommission parses a decimal string such as "0.10" or "1.00". Same as
+// sdk.Dec, at most 18 decimal places are accepted, and the value should be
+// within 0 and 1 inclusive.
+func ParseCommission(str string) (Commission, error) {
+	if len(str) == 0 {
+		return Commission{}, fmt.Errorf("empty decimal string")
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(str, ".")
+	if hasDot && (len(intPart) == 0 || len(fracPart) == 0) {
+		return Commission{}, fmt.Errorf("invalid decimal %q", str)
+	}
+	if len(intPart) == 0 || !isDigits(intPart) || !isDigits(fracPart) {
+		return Commission{}, fmt.Errorf("invalid decimal %q", str)
+	}
+	if len(fracPart) > CommissionPrecision {
+		return Commission{}, fmt.Errorf("decimal %q has more than %d decimal places", str, CommissionPrecision)
+	}
+
+	// anything above 1 is out of range, we check it on the trimmed integer part
+	// to not overflow while parsing
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart != "" && intPart != "1" {
+		return Commission{}, fmt.Errorf("commission %s is larger than 1", str)
+	}
+
+	var units uint64
+	if intPart == "1" {
+		units = commissionUnit
+	}
+
+	fracPart += strings.Repeat("0", CommissionPrecision-len(fracPart))
+	var frac uint64
+	for _, c := range fracPart {
+		frac = frac*10 + uint64(c-'0')
+	}
+	units += frac
+
+	if units > commissionUnit {
+		return Commission{}, fmt.Errorf("commission %s is larger than 1", str)
+	}
+
+	return Commission{units: units}, nil
+}
+
+// String returns the normalised form of the commission, which is the same
+// as the string form of sdk.Dec, e.g. "0.100000000000000000"
+func (c Commission) String() string {
+	return fmt.Sprintf("%d.%018d", c.units/commissionUnit, c.units%commissionUnit)
+}
+
+// Cmp compares two commissions, returning -1, 0 or +1 if c is respectively
+// smaller, equal or larger than other
+func (c Commission) Cmp(other Commission) int {
+	switch {
+	case c.units < other.units:
+		return -1
+	case c.units > other.units:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (c Commission) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+func (c *Commission) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	parsed, err := ParseCommission(str)
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+	return nil
+}
@@ -0,0 +1,145 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func TestParseCommission(t *testing.T) {
+	tcs := []struct {
+		in         string
+		normalised string
+	}{
+		{"0", "0.000000000000000000"},
+		{"0.0", "0.000000000000000000"},
+		{"0.1", "0.100000000000000000"},
+		{"0.10", "0.100000000000000000"},
+		{"0.01", "0.010000000000000000"},
+		{"0.077", "0.077000000000000000"},
+		{"0.050000000000000000", "0.050000000000000000"},
+		{"0.000000000000000001", "0.000000000000000001"},
+		{"1", "1.000000000000000000"},
+		{"1.00", "1.000000000000000000"},
+		{"01.000000000000000000", "1.000000000000000000"},
+	}
+
+	for _, tc := range tcs {
+		c, err := registry.ParseCommission(tc.in)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.normalised, c.String(), tc.in)
+
+		// the normalised form parses back to the same commission
+		reparsed, err := registry.ParseCommission(c.String())
+		require.NoError(t, err)
+		assert.Equal(t, 0, c.Cmp(reparsed))
+	}
+}
+
+func TestFailCommissionParsing(t *testing.T) {
+	tcs := []struct {
+		in     string
+		errMsg string
+	}{
+		{"", "empty decimal string"},
+		{".1", `invalid decimal ".1"`},
+		{".5", `invalid decimal ".5"`},
+		{".", `invalid decimal "."`},
+		{"1.", `invalid decimal "1."`},
+		{"0.", `invalid decimal "0."`},
+		{"0.1.2", `invalid decimal "0.1.2"`},
+		{"-0.1", `invalid decimal "-0.1"`},
+		{"+0.1", `invalid decimal "+0.1"`},
+		{"0,1", `invalid decimal "0,1"`},
+		{"10%", `invalid decimal "10%"`},
+		{"0.0000000000000000001", `decimal "0.0000000000000000001" has more than 18 decimal places`},
+		{"1.000000000000000001", "commission 1.000000000000000001 is larger than 1"},
+		{"2", "commission 2 is larger than 1"},
+		{"100000000000000000000000", "commission 100000000000000000000000 is larger than 1"},
+	}
+
+	for _, tc := range tcs {
+		_, err := registry.ParseCommission(tc.in)
+		require.Error(t, err, tc.in)
+		assert.Equal(t, tc.errMsg, err.Error())
+	}
+}
+
+// PROPERTY: Every commission in range is parsed and its normalised string form
+// round trips through parsing
+func FuzzCommissionRoundTrip(f *testing.F) {
+	addRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		decimals := r.Intn(registry.CommissionPrecision) + 1
+		var frac string
+		for i := 0; i < decimals; i++ {
+			frac += fmt.Sprintf("%d", r.Intn(10))
+		}
+
+		c, err := registry.ParseCommission("0." + frac)
+		require.NoError(t, err)
+		require.Equal(t, -1, c.Cmp(registry.MaxCommission))
+		require.NotEqual(t, -1, c.Cmp(registry.ZeroCommission))
+
+		reparsed, err := registry.ParseCommission(c.String())
+		require.NoError(t, err)
+		require.Equal(t, 0, c.Cmp(reparsed))
+	})
+}
+
+func TestCommissionCmp(t *testing.T) {
+	low, err := registry.ParseCommission("0.05")
+	require.NoError(t, err)
+	high, err := registry.ParseCommission("0.1")
+	require.NoError(t, err)
+
+	assert.Equal(t, -1, low.Cmp(high))
+	assert.Equal(t, 1, high.Cmp(low))
+	assert.Equal(t, 0, low.Cmp(low))
+	assert.Equal(t, 1, registry.MaxCommission.Cmp(high))
+	assert.Equal(t, -1, registry.ZeroCommission.Cmp(low))
+}
+
+func TestCommissionJSON(t *testing.T) {
+	var v struct {
+		Commission registry.Commission `json:"commission"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"commission": "0.10"}`), &v))
+	assert.Equal(t, "0.100000000000000000", v.Commission.String())
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, `{"commission":"0.100000000000000000"}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"commission": "1.5"}`), &v)
+	assert.Equal(t, "commission 1.5 is larger than 1", err.Error())
+
+	err = json.Unmarshal([]byte(`{"commission": 0.1}`), &v)
+	assert.Error(t, err)
+}
+
+func TestBbnTest4RegistryCommissions(t *testing.T) {
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+
+	for _, e := range entries {
+		_, err := registry.ParseCommission(e.FinalityProvider.Commission)
+		require.NoError(t, err, e.Nickname)
+	}
+}
+
+func addRandomSeedsToFuzzer(f *testing.F, num uint) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	var idx uint
+	for idx = 0; idx < num; idx++ {
+		f.Add(r.Int63())
+	}
+}
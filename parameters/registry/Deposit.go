@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+const (
+	// DefaultDepositConfirmations is the number of confirmations a deposit
+	// should have before its finality provider is registered
+	DefaultDepositConfirmations = 6
+
+	// DepositTagHex is the tag of the deposit OP_RETURN output, "bbt4"
+	DepositTagHex = "62627434"
+	// DepositStakingTime is the number of blocks a deposit is locked for
+	DepositStakingTime = 52560
+	// DepositCovenantPkHex is the only covenant key of a deposit. It is the
+	// BIP341 NUMS point, so that the unbonding and slashing paths cannot be
+	// used and the deposit can only be withdrawn by the staker after the
+	// staking time.
+	DepositCovenantPkHex = btcstaking.NumsKeyHex
+	// DepositCovenantQuorum is the covenant quorum of a deposit
+	DepositCovenantQuorum = 1
+	// DepositMinAmount is the minimum value in satoshi of the staking output
+	// of a deposit
+	DepositMinAmount = 10000000
+)
+
+type ParsedDeposit struct {
+	TxHash chainhash.Hash
+	Tx     *wire.MsgTx
+}
+
+// ParseDeposit decodes the signed deposit transaction and checks that its
+// hash is the declared tx_hash
+func ParseDeposit(d *Deposit) (*ParsedDeposit, error) {
+	txHash, err := chainhash.NewHashFromStr(d.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx_hash %s: %w", d.TxHash, err)
+	}
+
+	txBytes, err := hex.DecodeString(d.SignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signed_tx: %w", err)
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, fmt.Errorf("invalid signed_tx: %w", err)
+	}
+
+	if tx.TxHash() != *txHash {
+		return nil, fmt.Errorf("signed_tx has hash %s, but tx_hash is %s", tx.TxHash(), txHash)
+	}
+
+	return &ParsedDeposit{
+		TxHash: *txHash,
+		Tx:     &tx,
+	}, nil
+}
+
+func depositCovenantKey() (*btcec.PublicKey, error) {
+	covenantPkBytes, err := hex.DecodeString(DepositCovenantPkHex)
+	if err != nil {
+		return nil, err
+	}
+	covenantPk, err := schnorr.ParsePubKey(covenantPkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit covenant key: %w", err)
+	}
+	if !btcstaking.IsNumsKey(covenantPk) {
+		return nil, fmt.Errorf("deposit covenant key %s is not the NUMS point, it could unbond or slash deposits", DepositCovenantPkHex)
+	}
+	return covenantPk, nil
+}
+
+// DepositPolicy returns the policy deposits are inspected against. The
+// finality provider key is only checked if fpBtcPk is not nil.
+func DepositPolicy(fpBtcPk *btcec.PublicKey) (*btcstaking.StakingTxPolicy, error) {
+	covenantPk, err := depositCovenantKey()
+	if err != nil {
+		return nil, err
+	}
+	tag, err := hex.DecodeString(DepositTagHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &btcstaking.StakingTxPolicy{
+		Name:             "deposit policy",
+		Tag:              tag,
+		CovenantKeys:     []*btcec.PublicKey{covenantPk},
+		CovenantQuorum:   DepositCovenantQuorum,
+		MinStakingTime:   DepositStakingTime,
+		MaxStakingTime:   DepositStakingTime,
+		MinStakingAmount: DepositMinAmount,
+		FpKey:            fpBtcPk,
+	}, nil
+}
+
+// VerifyDepositStakingTx checks that the deposit is a staking transaction to
+// the finality provider key, with the deposit tag and staking time, whose
+// only covenant key is the NUMS point
+func VerifyDepositStakingTx(d *ParsedDeposit, fpBtcPk *btcec.PublicKey) (*btcstaking.ParsedV0StakingTx, error) {
+	covenantPk, err := depositCovenantKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := hex.DecodeString(DepositTagHex)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := btcstaking.ParseV0StakingTx(d.Tx, tag, []*btcec.PublicKey{covenantPk}, DepositCovenantQuorum)
+	if err != nil {
+		return nil, fmt.Errorf("deposit tx %s is not a valid staking tx: %w", d.TxHash, err)
+	}
+
+	if parsed.OpReturnData.StakingTime != DepositStakingTime {
+		return nil, fmt.Errorf("deposit tx %s has staking time %d, it should be %d",
+			d.TxHash, parsed.OpReturnData.StakingTime, DepositStakingTime)
+	}
+
+	if !bytes.Equal(schnorr.SerializePubKey(parsed.OpReturnData.FpKey), schnorr.SerializePubKey(fpBtcPk)) {
+		return nil, fmt.Errorf("deposit tx %s is for finality provider %x, not %x", d.TxHash,
+			schnorr.SerializePubKey(parsed.OpReturnData.FpKey), schnorr.SerializePubKey(fpBtcPk))
+	}
+
+	return parsed, nil
+}
+
+func serializeNoWitness(tx *wire.MsgTx) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tx.SerializeNoWitness(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyDepositOnChain checks that the deposit transaction is included in the
+// chain known by the backend with at least minConfirmations confirmations.
+// The transaction is compared by txid and by its witness stripped bytes, as
+// the witness is not committed by the txid and may differ.
+func VerifyDepositOnChain(
+	ctx context.Context,
+	backend btcbackend.ChainBackend,
+	d *ParsedDeposit,
+	minConfirmations uint64,
+) error {
+	info, err := backend.GetTransaction(ctx, &d.TxHash)
+	if errors.Is(err, btcbackend.ErrTxNotFound) {
+		return fmt.Errorf("deposit tx %s is not on chain", d.TxHash)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get deposit tx %s: %w", d.TxHash, err)
+	}
+
+	if info.Tx.TxHash() != d.TxHash {
+		return fmt.Errorf("backend returned tx %s instead of deposit tx %s", info.Tx.TxHash(), d.TxHash)
+	}
+
+	onChainBytes, err := serializeNoWitness(info.Tx)
+	if err != nil {
+		return err
+	}
+	depositBytes, err := serializeNoWitness(d.Tx)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(onChainBytes, depositBytes) {
+		return fmt.Errorf("signed_tx is different than the on chain tx %s", d.TxHash)
+	}
+
+	tipHeight, err := backend.GetTipHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tip height: %w", err)
+	}
+
+	confirmations := info.Confirmations(tipHeight)
+	if confirmations < minConfirmations {
+		return fmt.Errorf("deposit tx %s has %d confirmations, it should have at least %d",
+			d.TxHash, confirmations, minConfirmations)
+	}
+
+	return nil
+}
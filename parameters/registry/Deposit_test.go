@@ -0,0 +1,216 @@
+package registry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/btcbackend"
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+// entries which were accepted with a malformed deposit before the registry
+// was verified in Go
+var knownInvalidDeposits = map[string]string{
+	"Blockdaemon": "invalid tx_hash 3d657a383bd24ef9ceifa6ec327f308f3462c323824d78e2fa87725745f1014dd: max hash string length is 64 bytes",
+}
+
+func TestParseBbnTest4Deposits(t *testing.T) {
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+
+	for _, e := range entries {
+		d, err := registry.ParseDeposit(&e.FinalityProvider.Deposit)
+		if errMsg, ok := knownInvalidDeposits[e.Nickname]; ok {
+			require.EqualError(t, err, errMsg)
+			continue
+		}
+		require.NoError(t, err, e.Nickname)
+		require.Equal(t, e.FinalityProvider.Deposit.TxHash, d.TxHash.String())
+	}
+}
+
+func TestFailDepositParsing(t *testing.T) {
+	deposit := newTestEntry(t).Deposit
+
+	d := deposit
+	d.TxHash = "zz"
+	_, err := registry.ParseDeposit(&d)
+	assert.Contains(t, err.Error(), "invalid tx_hash zz")
+
+	d = deposit
+	d.SignedTx = d.SignedTx[:len(d.SignedTx)-2]
+	_, err = registry.ParseDeposit(&d)
+	assert.Contains(t, err.Error(), "invalid signed_tx")
+
+	d = deposit
+	d.TxHash = "f22b9a1892df0e50977455b85b65324b079a9f230c5a9dede5ac711b9415d15b"
+	_, err = registry.ParseDeposit(&d)
+	assert.Equal(t, "signed_tx has hash "+deposit.TxHash+", but tx_hash is "+d.TxHash, err.Error())
+}
+
+func TestVerifyDepositOnChain(t *testing.T) {
+	ctx := context.Background()
+	deposit, err := registry.ParseDeposit(&newTestEntry(t).Deposit)
+	require.NoError(t, err)
+
+	backend := btcbackend.NewFakeBackend()
+	err = registry.VerifyDepositOnChain(ctx, backend, deposit, registry.DefaultDepositConfirmations)
+	assert.Equal(t, "deposit tx "+deposit.TxHash.String()+" is not on chain", err.Error())
+
+	// in mempool
+	backend.AddTransaction(deposit.Tx, 0)
+	backend.SetTipHeight(200000)
+	err = registry.VerifyDepositOnChain(ctx, backend, deposit, registry.DefaultDepositConfirmations)
+	assert.Equal(t, "deposit tx "+deposit.TxHash.String()+" has 0 confirmations, it should have at least 6", err.Error())
+
+	// 5 confirmations
+	backend.AddTransaction(deposit.Tx, 200000)
+	backend.SetTipHeight(200004)
+	err = registry.VerifyDepositOnChain(ctx, backend, deposit, registry.DefaultDepositConfirmations)
+	assert.Equal(t, "deposit tx "+deposit.TxHash.String()+" has 5 confirmations, it should have at least 6", err.Error())
+
+	// the threshold is configurable
+	require.NoError(t, registry.VerifyDepositOnChain(ctx, backend, deposit, 5))
+
+	backend.SetTipHeight(200005)
+	require.NoError(t, registry.VerifyDepositOnChain(ctx, backend, deposit, registry.DefaultDepositConfirmations))
+
+	// the on chain tx may carry a different witness, which is not committed by the txid
+	onChain := deposit.Tx.Copy()
+	onChain.TxIn[0].Witness = wire.TxWitness{[]byte{0x01}}
+	backend.AddTransaction(onChain, 200000)
+	require.NoError(t, registry.VerifyDepositOnChain(ctx, backend, deposit, registry.DefaultDepositConfirmations))
+}
+
+// mismatchBackend returns another transaction than the requested one
+type mismatchBackend struct {
+	*btcbackend.FakeBackend
+	tx *wire.MsgTx
+}
+
+func (m *mismatchBackend) GetTransaction(_ context.Context, _ *chainhash.Hash) (*btcbackend.TxInfo, error) {
+	return &btcbackend.TxInfo{Tx: m.tx, Confirmed: true, BlockHeight: 1}, nil
+}
+
+func TestFailVerifyDepositOnChainMismatch(t *testing.T) {
+	ctx := context.Background()
+	deposit, err := registry.ParseDeposit(&newTestEntry(t).Deposit)
+	require.NoError(t, err)
+
+	other := deposit.Tx.Copy()
+	other.LockTime++
+	backend := &mismatchBackend{FakeBackend: btcbackend.NewFakeBackend(), tx: other}
+	backend.SetTipHeight(100)
+
+	err = registry.VerifyDepositOnChain(ctx, backend, deposit, registry.DefaultDepositConfirmations)
+	assert.Equal(t, "backend returned tx "+other.TxHash().String()+" instead of deposit tx "+deposit.TxHash.String(), err.Error())
+}
+
+func TestParsedFinalityProviderDeposit(t *testing.T) {
+	fp := newTestEntry(t)
+	parsed, err := registry.ParseFinalityProvider(fp)
+	require.NoError(t, err)
+	assert.Equal(t, fp.Deposit.TxHash, parsed.Deposit.TxHash.String())
+	assert.Equal(t, fp.Deposit.SignedTx, hexTx(t, parsed.Deposit.Tx))
+}
+
+func TestVerifyBbnTest4DepositStakingTxs(t *testing.T) {
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+
+	for _, e := range entries {
+		if _, ok := knownInvalidDeposits[e.Nickname]; ok {
+			continue
+		}
+		d, err := registry.ParseDeposit(&e.FinalityProvider.Deposit)
+		require.NoError(t, err)
+		fpBtcPk, err := schnorr.ParsePubKey(mustDecodeHex(t, e.FinalityProvider.BtcPk))
+		require.NoError(t, err)
+
+		parsed, err := registry.VerifyDepositStakingTx(d, fpBtcPk)
+		require.NoError(t, err, e.Nickname)
+		require.True(t, btcstaking.IsNumsKey(parsed.StakingInfo.InternalKey))
+	}
+}
+
+func TestVerifyDepositStakingTx(t *testing.T) {
+	fp := newTestEntry(t)
+	deposit, err := registry.ParseDeposit(&fp.Deposit)
+	require.NoError(t, err)
+	fpBtcPk, err := schnorr.ParsePubKey(mustDecodeHex(t, fp.BtcPk))
+	require.NoError(t, err)
+
+	parsed, err := registry.VerifyDepositStakingTx(deposit, fpBtcPk)
+	require.NoError(t, err)
+	assert.Equal(t, "51207a9ff8118b22eb189030747d247bff3c6e9649e7169e319460e9f9d0f301e9b9",
+		hex.EncodeToString(parsed.StakingOutput.PkScript))
+	assert.Equal(t, uint16(registry.DepositStakingTime), parsed.OpReturnData.StakingTime)
+
+	// the deposit of another finality provider
+	other, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	_, err = registry.VerifyDepositStakingTx(deposit, other.PubKey())
+	assert.Equal(t, fmt.Sprintf("deposit tx %s is for finality provider %s, not %x",
+		deposit.TxHash, fp.BtcPk, schnorr.SerializePubKey(other.PubKey())), err.Error())
+
+	// a staking output with a covenant key which is not NUMS
+	tx := deposit.Tx.Copy()
+	parsed, err = registry.VerifyDepositStakingTx(deposit, fpBtcPk)
+	require.NoError(t, err)
+	info, err := btcstaking.BuildStakingInfo(parsed.OpReturnData.StakerKey, []*btcec.PublicKey{fpBtcPk},
+		[]*btcec.PublicKey{other.PubKey()}, 1, registry.DepositStakingTime, parsed.StakingAmount())
+	require.NoError(t, err)
+	tx.TxOut[parsed.StakingOutputIdx] = info.StakingOutput
+	_, err = registry.VerifyDepositStakingTx(&registry.ParsedDeposit{TxHash: tx.TxHash(), Tx: tx}, fpBtcPk)
+	assert.Equal(t, "deposit tx "+tx.TxHash().String()+" is not a valid staking tx: "+
+		"tx does not have a staking output matching the OP_RETURN data and the covenant committee", err.Error())
+}
+
+func TestInspectDeposit(t *testing.T) {
+	fp := newTestEntry(t)
+	deposit, err := registry.ParseDeposit(&fp.Deposit)
+	require.NoError(t, err)
+	fpBtcPk, err := schnorr.ParsePubKey(mustDecodeHex(t, fp.BtcPk))
+	require.NoError(t, err)
+
+	policy, err := registry.DepositPolicy(fpBtcPk)
+	require.NoError(t, err)
+	inspection := btcstaking.InspectStakingTx(deposit.Tx, policy, &chaincfg.SigNetParams)
+	require.True(t, inspection.Pass())
+	require.Equal(t, "bbt4 (62627434)", btcstaking.TagString(inspection.OpReturnData.Tag))
+
+	// the deposit of another finality provider
+	other, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	policy, err = registry.DepositPolicy(other.PubKey())
+	require.NoError(t, err)
+	inspection = btcstaking.InspectStakingTx(deposit.Tx, policy, &chaincfg.SigNetParams)
+	require.False(t, inspection.Pass())
+	for _, c := range inspection.Checks {
+		assert.Equal(t, c.Field != "fp_pk", c.Pass, c.Field)
+	}
+}
+
+func mustDecodeHex(t *testing.T, str string) []byte {
+	b, err := hex.DecodeString(str)
+	require.NoError(t, err)
+	return b
+}
+
+func hexTx(t *testing.T, tx *wire.MsgTx) string {
+	var buf bytes.Buffer
+	require.NoError(t, tx.Serialize(&buf))
+	return hex.EncodeToString(buf.Bytes())
+}
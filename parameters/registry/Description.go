@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Limits of the Cosmos SDK staking validator description. We define them here
+// to not bring the whole Cosmos SDK as dependency.
+const (
+	MaxMonikerLength         = 70
+	MaxIdentityLength        = 3000
+	MaxWebsiteLength         = 140
+	MaxSecurityContactLength = 140
+	MaxDetailsLength         = 280
+
+	// minimum lengths previously enforced by the registry verification scripts
+	MinMonikerLength         = 3
+	MinSecurityContactLength = 4
+)
+
+// keybase identities are the 64 bit key suffix of a PGP key, as 16 hex chars
+var keybaseIdentityRegex = regexp.MustCompile(`^[0-9A-Fa-f]{16}$`)
+
+// Description mirrors the Cosmos SDK validator description so that
+// the registry entries carry over to the phase-2 chain.
+type Description struct {
+	Moniker         string `json:"moniker"`
+	Identity        string `json:"identity"`
+	Website         string `json:"website"`
+	SecurityContact string `json:"security_contact"`
+	Details         string `json:"details"`
+}
+
+func checkLength(field, value string, minLen, maxLen int) error {
+	l := len(value)
+	if l < minLen {
+		return fmt.Errorf("%s has less than %d characters, got %d", field, minLen, l)
+	}
+	if l > maxLen {
+		return fmt.Errorf("%s has more than %d characters, got %d", field, maxLen, l)
+	}
+	return nil
+}
+
+func isHttpUrl(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isEmail only accepts a bare address with a dotted domain, display names
+// such as "Name <addr>" are not accepted
+func isEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return false
+	}
+
+	domain := value[strings.LastIndex(value, "@")+1:]
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// Validate checks the description against the Cosmos SDK limits and the
+// format of the identity, website and security contact
+func (d *Description) Validate() error {
+	if err := checkLength("moniker", strings.TrimSpace(d.Moniker), MinMonikerLength, MaxMonikerLength); err != nil {
+		return err
+	}
+
+	if err := checkLength("identity", d.Identity, 0, MaxIdentityLength); err != nil {
+		return err
+	}
+	if d.Identity != "" && !keybaseIdentityRegex.MatchString(d.Identity) {
+		return fmt.Errorf("identity %q is not a 16 hex characters keybase identity", d.Identity)
+	}
+
+	if err := checkLength("website", d.Website, 0, MaxWebsiteLength); err != nil {
+		return err
+	}
+	if d.Website != "" && !isHttpUrl(d.Website) {
+		return fmt.Errorf("website %q is not a http(s) url", d.Website)
+	}
+
+	if err := checkLength("security_contact", d.SecurityContact, MinSecurityContactLength, MaxSecurityContactLength); err != nil {
+		return err
+	}
+	if !isEmail(d.SecurityContact) && !isHttpUrl(d.SecurityContact) {
+		return fmt.Errorf("security_contact %q is neither an email nor a http(s) url", d.SecurityContact)
+	}
+
+	if err := checkLength("details", d.Details, 0, MaxDetailsLength); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package registry_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+var defaultDescription = registry.Description{
+	Moniker:         "GalaxyDigital",
+	Identity:        "1718CFB46A0EC7FA",
+	Website:         "https://galaxy.com",
+	SecurityContact: "bci@galaxy.com",
+	Details:         "Galaxy is a digital asset and blockchain leader.",
+}
+
+func TestValidDescription(t *testing.T) {
+	d := defaultDescription
+	require.NoError(t, d.Validate())
+
+	// optional fields can be left empty
+	d.Identity = ""
+	d.Website = ""
+	d.Details = ""
+	require.NoError(t, d.Validate())
+
+	// security contact can be a url
+	d.SecurityContact = "https://galaxy.com/security"
+	require.NoError(t, d.Validate())
+
+	// limits are inclusive
+	d.Moniker = strings.Repeat("m", registry.MaxMonikerLength)
+	d.Details = strings.Repeat("d", registry.MaxDetailsLength)
+	require.NoError(t, d.Validate())
+}
+
+func TestFailDescriptionValidation(t *testing.T) {
+	tcs := []struct {
+		name   string
+		mutate func(d *registry.Description)
+		errMsg string
+	}{
+		{
+			name:   "short moniker",
+			mutate: func(d *registry.Description) { d.Moniker = "ab" },
+			errMsg: "moniker has less than 3 characters, got 2",
+		},
+		{
+			name:   "long moniker",
+			mutate: func(d *registry.Description) { d.Moniker = strings.Repeat("m", registry.MaxMonikerLength+1) },
+			errMsg: "moniker has more than 70 characters, got 71",
+		},
+		{
+			name:   "non keybase identity",
+			mutate: func(d *registry.Description) { d.Identity = "stakefish" },
+			errMsg: `identity "stakefish" is not a 16 hex characters keybase identity`,
+		},
+		{
+			name:   "pgp fingerprint identity",
+			mutate: func(d *registry.Description) { d.Identity = "45CB4A44DDA954E351EF52DBAF9B41F5A7572821" },
+			errMsg: `identity "45CB4A44DDA954E351EF52DBAF9B41F5A7572821" is not a 16 hex characters keybase identity`,
+		},
+		{
+			name:   "website without scheme",
+			mutate: func(d *registry.Description) { d.Website = "dsrvlabs.com" },
+			errMsg: `website "dsrvlabs.com" is not a http(s) url`,
+		},
+		{
+			name:   "website with other scheme",
+			mutate: func(d *registry.Description) { d.Website = "ftp://galaxy.com" },
+			errMsg: `website "ftp://galaxy.com" is not a http(s) url`,
+		},
+		{
+			name:   "long website",
+			mutate: func(d *registry.Description) { d.Website = "https://" + strings.Repeat("w", registry.MaxWebsiteLength) },
+			errMsg: "website has more than 140 characters, got 148",
+		},
+		{
+			name:   "empty security contact",
+			mutate: func(d *registry.Description) { d.SecurityContact = "" },
+			errMsg: "security_contact has less than 4 characters, got 0",
+		},
+		{
+			name:   "email without domain",
+			mutate: func(d *registry.Description) { d.SecurityContact = "info@blockscape" },
+			errMsg: `security_contact "info@blockscape" is neither an email nor a http(s) url`,
+		},
+		{
+			name:   "email with display name",
+			mutate: func(d *registry.Description) { d.SecurityContact = "Galaxy <bci@galaxy.com>" },
+			errMsg: `security_contact "Galaxy <bci@galaxy.com>" is neither an email nor a http(s) url`,
+		},
+		{
+			name:   "placeholder security contact",
+			mutate: func(d *registry.Description) { d.SecurityContact = "SECURITY_CONTACT" },
+			errMsg: `security_contact "SECURITY_CONTACT" is neither an email nor a http(s) url`,
+		},
+		{
+			name:   "long details",
+			mutate: func(d *registry.Description) { d.Details = strings.Repeat("d", 347) },
+			errMsg: "details has more than 280 characters, got 347",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			d := defaultDescription
+			tc.mutate(&d)
+			err := d.Validate()
+			require.Error(t, err)
+			assert.Equal(t, tc.errMsg, err.Error())
+		})
+	}
+}
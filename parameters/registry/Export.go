@@ -0,0 +1,284 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type ExportField string
+
+const (
+	FieldNickname        ExportField = "nickname"
+	FieldMoniker         ExportField = "moniker"
+	FieldIdentity        ExportField = "identity"
+	FieldWebsite         ExportField = "website"
+	FieldSecurityContact ExportField = "security_contact"
+	FieldDetails         ExportField = "details"
+	FieldBtcPk           ExportField = "btc_pk"
+	FieldCommission      ExportField = "commission"
+	FieldDepositTxHash   ExportField = "deposit_tx_hash"
+)
+
+// AllExportFields lists every field that can be exported, in their default order
+var AllExportFields = []ExportField{
+	FieldNickname,
+	FieldMoniker,
+	FieldIdentity,
+	FieldWebsite,
+	FieldSecurityContact,
+	FieldDetails,
+	FieldBtcPk,
+	FieldCommission,
+	FieldDepositTxHash,
+}
+
+type ExportOptions struct {
+	// Fields to export, in order. All fields are exported if empty.
+	Fields []ExportField
+	// SortBy is the field the providers are sorted by, ties are broken by
+	// nickname. Providers are sorted by nickname if empty.
+	SortBy     ExportField
+	Descending bool
+}
+
+// ParseExportFields parses a comma separated list of field names
+func ParseExportFields(str string) ([]ExportField, error) {
+	var fields []ExportField
+	for _, name := range strings.Split(str, ",") {
+		field := ExportField(strings.TrimSpace(name))
+		if !isExportField(field) {
+			return nil, fmt.Errorf("unknown export field %q", field)
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func isExportField(field ExportField) bool {
+	for _, f := range AllExportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// exportRow is a single provider ready to be exported. Commissions are kept
+// parsed so that providers can be sorted by their numeric value.
+type exportRow struct {
+	values     map[ExportField]string
+	commission Commission
+}
+
+func newExportRow(e *Entry) (*exportRow, error) {
+	fp := e.FinalityProvider
+	commission, err := ParseCommission(fp.Commission)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commission of %s: %w", e.Nickname, err)
+	}
+
+	return &exportRow{
+		values: map[ExportField]string{
+			FieldNickname:        e.Nickname,
+			FieldMoniker:         fp.Description.Moniker,
+			FieldIdentity:        fp.Description.Identity,
+			FieldWebsite:         fp.Description.Website,
+			FieldSecurityContact: fp.Description.SecurityContact,
+			FieldDetails:         fp.Description.Details,
+			FieldBtcPk:           fp.BtcPk,
+			FieldCommission:      commission.String(),
+			FieldDepositTxHash:   fp.Deposit.TxHash,
+		},
+		commission: commission,
+	}, nil
+}
+
+func (r *exportRow) less(other *exportRow, sortBy ExportField) bool {
+	cmp := 0
+	switch sortBy {
+	case FieldCommission:
+		cmp = r.commission.Cmp(other.commission)
+	case FieldNickname:
+	default:
+		cmp = strings.Compare(r.values[sortBy], other.values[sortBy])
+	}
+
+	if cmp == 0 {
+		return r.values[FieldNickname] < other.values[FieldNickname]
+	}
+	return cmp < 0
+}
+
+// exportRows validates the options and returns the selected fields and the
+// sorted rows to export
+func exportRows(entries []*Entry, opts ExportOptions) ([]ExportField, []*exportRow, error) {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = AllExportFields
+	}
+	for _, f := range fields {
+		if !isExportField(f) {
+			return nil, nil, fmt.Errorf("unknown export field %q", f)
+		}
+	}
+
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = FieldNickname
+	}
+	if !isExportField(sortBy) {
+		return nil, nil, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+
+	rows := make([]*exportRow, 0, len(entries))
+	for _, e := range entries {
+		row, err := newExportRow(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if opts.Descending {
+			return rows[j].less(rows[i], sortBy)
+		}
+		return rows[i].less(rows[j], sortBy)
+	})
+
+	return fields, rows, nil
+}
+
+// ExportCSV writes the providers as CSV with a header line of the field names.
+// Values are quoted when needed, so monikers containing commas, quotes or new
+// lines are kept intact.
+func ExportCSV(w io.Writer, entries []*Entry, opts ExportOptions) error {
+	fields, rows, err := exportRows(entries, opts)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = string(f)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			record[i] = row.values[f]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportBundle is the single JSON document holding every exported provider,
+// meant to be served to the staking web app
+type ExportBundle struct {
+	FinalityProviders []map[ExportField]string `json:"finality_providers"`
+}
+
+// orderedRow encodes the values of a row as a JSON object with its keys in
+// the order of the fields, as a map would sort them
+type orderedRow struct {
+	fields []ExportField
+	values map[ExportField]string
+}
+
+func (r *orderedRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, f := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := enc.Encode(f); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		if err := enc.Encode(r.values[f]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ExportJSON writes the providers as a single JSON bundle, which decodes as
+// an ExportBundle. Each provider is an object keyed by the selected field
+// names, in the selected order.
+func ExportJSON(w io.Writer, entries []*Entry, opts ExportOptions) error {
+	fields, rows, err := exportRows(entries, opts)
+	if err != nil {
+		return err
+	}
+
+	bundle := struct {
+		FinalityProviders []*orderedRow `json:"finality_providers"`
+	}{
+		FinalityProviders: make([]*orderedRow, 0, len(rows)),
+	}
+	for _, row := range rows {
+		bundle.FinalityProviders = append(bundle.FinalityProviders, &orderedRow{fields: fields, values: row.values})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// ExportMarkdown writes the providers as a Markdown table
+func ExportMarkdown(w io.Writer, entries []*Entry, opts ExportOptions) error {
+	fields, rows, err := exportRows(entries, opts)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("|")
+	for _, f := range fields {
+		sb.WriteString(" " + string(f) + " |")
+	}
+	sb.WriteString("\n|")
+	for range fields {
+		sb.WriteString(" --- |")
+	}
+	sb.WriteString("\n")
+
+	for _, row := range rows {
+		sb.WriteString("|")
+		for _, f := range fields {
+			sb.WriteString(" " + markdownEscaper.Replace(row.values[f]) + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,154 @@
+package registry_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func newExportEntry(nickname, moniker, commission string) *registry.Entry {
+	return &registry.Entry{
+		Nickname: nickname,
+		FinalityProvider: &registry.FinalityProvider{
+			Description: registry.Description{
+				Moniker:         moniker,
+				Website:         "https://" + nickname + ".io",
+				SecurityContact: "security@" + nickname + ".io",
+				Details:         "line one\nline | two",
+			},
+			BtcPk:      strings.Repeat("ab", 32),
+			Commission: commission,
+			Deposit:    registry.Deposit{TxHash: strings.Repeat("cd", 32)},
+		},
+	}
+}
+
+func exportTestEntries() []*registry.Entry {
+	return []*registry.Entry{
+		newExportEntry("charlie", `Charlie, "the" validator`, "0.1"),
+		newExportEntry("alice", "Alice", "0.05"),
+		newExportEntry("bob", "Bob", "1.00"),
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := registry.ExportCSV(&buf, exportTestEntries(), registry.ExportOptions{
+		Fields: []registry.ExportField{registry.FieldNickname, registry.FieldMoniker, registry.FieldCommission},
+	})
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"nickname", "moniker", "commission"},
+		{"alice", "Alice", "0.050000000000000000"},
+		{"bob", "Bob", "1.000000000000000000"},
+		{"charlie", `Charlie, "the" validator`, "0.100000000000000000"},
+	}, records)
+}
+
+func TestExportSortByCommission(t *testing.T) {
+	var buf bytes.Buffer
+	err := registry.ExportCSV(&buf, exportTestEntries(), registry.ExportOptions{
+		Fields:     []registry.ExportField{registry.FieldNickname},
+		SortBy:     registry.FieldCommission,
+		Descending: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "nickname\nbob\ncharlie\nalice\n", buf.String())
+
+	buf.Reset()
+	err = registry.ExportCSV(&buf, exportTestEntries(), registry.ExportOptions{
+		Fields: []registry.ExportField{registry.FieldNickname},
+		SortBy: registry.FieldMoniker,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "nickname\nalice\nbob\ncharlie\n", buf.String())
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := registry.ExportJSON(&buf, exportTestEntries(), registry.ExportOptions{})
+	require.NoError(t, err)
+
+	var bundle registry.ExportBundle
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &bundle))
+	require.Len(t, bundle.FinalityProviders, 3)
+
+	alice := bundle.FinalityProviders[0]
+	assert.Len(t, alice, len(registry.AllExportFields))
+	assert.Equal(t, "alice", alice[registry.FieldNickname])
+	assert.Equal(t, "https://alice.io", alice[registry.FieldWebsite])
+	assert.Equal(t, "0.050000000000000000", alice[registry.FieldCommission])
+	assert.Equal(t, `Charlie, "the" validator`, bundle.FinalityProviders[2][registry.FieldMoniker])
+}
+
+func TestExportJSONFieldOrder(t *testing.T) {
+	var buf bytes.Buffer
+	err := registry.ExportJSON(&buf, exportTestEntries()[:1], registry.ExportOptions{
+		Fields: []registry.ExportField{registry.FieldWebsite, registry.FieldNickname, registry.FieldDetails},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "finality_providers": [
+    {
+      "website": "https://charlie.io",
+      "nickname": "charlie",
+      "details": "line one\nline | two"
+    }
+  ]
+}
+`, buf.String())
+}
+
+func TestExportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	err := registry.ExportMarkdown(&buf, exportTestEntries()[:1], registry.ExportOptions{
+		Fields: []registry.ExportField{registry.FieldMoniker, registry.FieldDetails},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "| moniker | details |\n"+
+		"| --- | --- |\n"+
+		`| Charlie, "the" validator | line one line \| two |`+"\n", buf.String())
+}
+
+func TestFailExport(t *testing.T) {
+	var buf bytes.Buffer
+	err := registry.ExportCSV(&buf, exportTestEntries(), registry.ExportOptions{
+		Fields: []registry.ExportField{"name"},
+	})
+	assert.Equal(t, `unknown export field "name"`, err.Error())
+
+	err = registry.ExportCSV(&buf, exportTestEntries(), registry.ExportOptions{
+		SortBy: "name",
+	})
+	assert.Equal(t, `unknown sort field "name"`, err.Error())
+
+	entries := exportTestEntries()
+	entries[0].FinalityProvider.Commission = "10%"
+	err = registry.ExportCSV(&buf, entries, registry.ExportOptions{})
+	assert.Equal(t, `invalid commission of charlie: invalid decimal "10%"`, err.Error())
+
+	_, err = registry.ParseExportFields("moniker,name")
+	assert.Equal(t, `unknown export field "name"`, err.Error())
+}
+
+func TestExportBbnTest4Registry(t *testing.T) {
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, registry.ExportCSV(&buf, entries, registry.ExportOptions{}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(entries)+1)
+}
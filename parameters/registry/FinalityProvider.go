@@ -0,0 +1,196 @@
+package registry
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+const (
+	// RegistryDirName is the directory, relative to the finality providers
+	// directory of a network, holding one JSON file per finality provider
+	RegistryDirName = "registry"
+
+	// SigsDirName is the directory, relative to the finality providers
+	// directory of a network, holding the signature of each registry entry
+	SigsDirName = "sigs"
+
+	// SpvDirName is the directory, relative to the finality providers
+	// directory of a network, holding the optional SPV proof of the deposit
+	// of each registry entry
+	SpvDirName = "spv"
+
+	entryFileExt = ".json"
+)
+
+type Deposit struct {
+	TxHash   string `json:"tx_hash"`
+	SignedTx string `json:"signed_tx"`
+	// SpvProof optionally proves the confirmation of the deposit, it can also
+	// be provided in a sidecar file under the spv directory
+	SpvProof *spv.Proof `json:"spv_proof,omitempty"`
+}
+
+type FinalityProvider struct {
+	Description Description `json:"description"`
+	BtcPk       string      `json:"btc_pk"`
+	Commission  string      `json:"commission"`
+	Deposit     Deposit     `json:"deposit"`
+}
+
+type ParsedFinalityProvider struct {
+	Description Description
+	BtcPk       *btcec.PublicKey
+	Commission  Commission
+	Deposit     *ParsedDeposit
+}
+
+// Entry is a registry file loaded from disk. Raw keeps the exact bytes of the
+// file as those are the bytes covered by the signature of the entry.
+type Entry struct {
+	Nickname         string
+	Path             string
+	Raw              []byte
+	FinalityProvider *FinalityProvider
+}
+
+// parseBtcPkFromHex parses the finality provider BTC public key, which is
+// the 32 bytes x-only (BIP340) encoding of the key
+func parseBtcPkFromHex(pkStr string) (*btcec.PublicKey, error) {
+	pkBytes, err := hex.DecodeString(pkStr)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := schnorr.ParsePubKey(pkBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return pk, nil
+}
+
+// ParseFinalityProvider validates the registry entry and returns its parsed
+// form. The description is checked against the Cosmos SDK validator
+// description rules so that the entry can be carried over to the chain, and
+// the deposit should be locked with the NUMS covenant key.
+func ParseFinalityProvider(fp *FinalityProvider) (*ParsedFinalityProvider, error) {
+	if err := fp.Description.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid description: %w", err)
+	}
+
+	btcPk, err := parseBtcPkFromHex(fp.BtcPk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid btc_pk %s: %w", fp.BtcPk, err)
+	}
+
+	commission, err := ParseCommission(fp.Commission)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commission: %w", err)
+	}
+
+	deposit, err := ParseDeposit(&fp.Deposit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit: %w", err)
+	}
+	if _, err := VerifyDepositStakingTx(deposit, btcPk); err != nil {
+		return nil, fmt.Errorf("invalid deposit: %w", err)
+	}
+
+	return &ParsedFinalityProvider{
+		Description: fp.Description,
+		BtcPk:       btcPk,
+		Commission:  commission,
+		Deposit:     deposit,
+	}, nil
+}
+
+func NewFinalityProviderFromFile(filePath string) (*FinalityProvider, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFinalityProviderFromBytes(data)
+}
+
+func NewFinalityProviderFromBytes(data []byte) (*FinalityProvider, error) {
+	var fp FinalityProvider
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return nil, err
+	}
+
+	return &fp, nil
+}
+
+// NicknameFromPath returns the nickname of the finality provider stored at
+// the given registry file path, i.e. its file name without the extension
+func NicknameFromPath(filePath string) string {
+	return strings.TrimSuffix(filepath.Base(filePath), entryFileExt)
+}
+
+var nicknameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateNickname checks that the nickname is a valid file name without
+// white spaces or unusual characters
+func ValidateNickname(nickname string) error {
+	if !nicknameRegex.MatchString(nickname) {
+		return fmt.Errorf("nickname %q should only contain letters, digits, '_', '.' and '-'", nickname)
+	}
+	return nil
+}
+
+// NewEntryFromFile loads a single registry file keeping its raw bytes
+func NewEntryFromFile(filePath string) (*Entry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := NewFinalityProviderFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry file %s: %w", filePath, err)
+	}
+
+	return &Entry{
+		Nickname:         NicknameFromPath(filePath),
+		Path:             filePath,
+		Raw:              data,
+		FinalityProvider: fp,
+	}, nil
+}
+
+// LoadRegistry loads every entry under the registry directory of the given
+// finality providers directory (e.g. bbn-test-4/finality-providers). Entries
+// are only decoded, not validated, and are returned sorted by nickname.
+func LoadRegistry(fpDir string) ([]*Entry, error) {
+	files, err := filepath.Glob(filepath.Join(fpDir, RegistryDirName, "*"+entryFileExt))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(files))
+	for _, f := range files {
+		entry, err := NewEntryFromFile(f)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Nickname < entries[j].Nickname
+	})
+
+	return entries, nil
+}
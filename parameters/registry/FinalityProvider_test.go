@@ -0,0 +1,91 @@
+package registry_test
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+const bbnTest4FpDir = "../../bbn-test-4/finality-providers"
+
+func TestLoadBbnTest4Registry(t *testing.T) {
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	for i, e := range entries {
+		require.NotEmpty(t, e.Raw)
+		require.NotEmpty(t, e.FinalityProvider.BtcPk)
+		if i > 0 {
+			require.Less(t, entries[i-1].Nickname, e.Nickname)
+		}
+	}
+}
+
+func TestParseFinalityProvider(t *testing.T) {
+	fp := newTestEntry(t)
+
+	parsed, err := registry.ParseFinalityProvider(fp)
+	require.NoError(t, err)
+	assert.Equal(t, "GalaxyDigital", parsed.Description.Moniker)
+	assert.Equal(t, "0.100000000000000000", parsed.Commission.String())
+	assert.Equal(t, fp.BtcPk, hexXOnly(parsed))
+}
+
+func TestFailFinalityProviderParsing(t *testing.T) {
+	entry := newTestEntry(t)
+
+	fp := *entry
+	fp.BtcPk = "030bd6622049385a958057774d4a95af246d17cd69146bda2021a12a422f37"
+	_, err := registry.ParseFinalityProvider(&fp)
+	assert.Contains(t, err.Error(), "invalid btc_pk")
+
+	fp = *entry
+	fp.Description.SecurityContact = ""
+	_, err = registry.ParseFinalityProvider(&fp)
+	assert.Equal(t, "invalid description: security_contact has less than 4 characters, got 0", err.Error())
+
+	fp = *entry
+	fp.Commission = ""
+	_, err = registry.ParseFinalityProvider(&fp)
+	assert.Equal(t, "invalid commission: empty decimal string", err.Error())
+
+	fp = *entry
+	fp.Commission = "1.01"
+	_, err = registry.ParseFinalityProvider(&fp)
+	assert.Equal(t, "invalid commission: commission 1.01 is larger than 1", err.Error())
+}
+
+func TestNewEntryFromFile(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte(`{"description": {"moniker": "my fp"}, "btc_pk": "aa"}`)
+	filePath := filepath.Join(dir, "my_nickname.json")
+	require.NoError(t, os.WriteFile(filePath, data, 0o600))
+
+	entry, err := registry.NewEntryFromFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "my_nickname", entry.Nickname)
+	assert.Equal(t, data, entry.Raw)
+	assert.Equal(t, "my fp", entry.FinalityProvider.Description.Moniker)
+
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"description":`), 0o600))
+	_, err = registry.NewEntryFromFile(filePath)
+	assert.Contains(t, err.Error(), "invalid registry file")
+}
+
+func newTestEntry(t *testing.T) *registry.FinalityProvider {
+	fp, err := registry.NewFinalityProviderFromFile(filepath.Join(bbnTest4FpDir, registry.RegistryDirName, "GalaxyDigital.json"))
+	require.NoError(t, err)
+	return fp
+}
+
+func hexXOnly(fp *registry.ParsedFinalityProvider) string {
+	return hex.EncodeToString(schnorr.SerializePubKey(fp.BtcPk))
+}
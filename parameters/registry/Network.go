@@ -0,0 +1,22 @@
+package registry
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/babylonchain/networks/parameters/networks"
+)
+
+// LoadFpNetwork loads the descriptor of the network of a finality providers
+// directory, which is in its parent directory (e.g. bbn-test-4/network.json)
+func LoadFpNetwork(fpDir string) (*networks.ParsedNetwork, error) {
+	abs, err := filepath.Abs(fpDir)
+	if err != nil {
+		return nil, err
+	}
+	n, err := networks.NewNetworkFromFile(filepath.Join(filepath.Dir(abs), networks.NetworkFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the network of %s: %w", fpDir, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RegistrationPolicyFileName is the file, relative to the finality providers
+// directory of a network, holding its registration policy
+const RegistrationPolicyFileName = "registration.json"
+
+// RegistrationPolicy bounds when new entries can be added to the registry of
+// a network and how many. Every bound is optional. Registration is open from
+// the open time or height included, to the close time or height excluded.
+type RegistrationPolicy struct {
+	OpenTime       *time.Time `json:"open_time,omitempty"`
+	CloseTime      *time.Time `json:"close_time,omitempty"`
+	OpenBtcHeight  uint64     `json:"open_btc_height,omitempty"`
+	CloseBtcHeight uint64     `json:"close_btc_height,omitempty"`
+	// MaxProviders is the maximum number of entries of the registry
+	MaxProviders int `json:"max_providers,omitempty"`
+}
+
+// RegistrationClock is the point in time a registration is checked at. A zero
+// BtcHeight means that the height is unknown.
+type RegistrationClock struct {
+	Time      time.Time
+	BtcHeight uint64
+}
+
+// RegistrationPolicyPath returns the path of the registration policy of the
+// given finality providers directory
+func RegistrationPolicyPath(fpDir string) string {
+	return filepath.Join(fpDir, RegistrationPolicyFileName)
+}
+
+func NewRegistrationPolicyFromFile(filePath string) (*RegistrationPolicy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistrationPolicyFromBytes(data)
+}
+
+func NewRegistrationPolicyFromBytes(data []byte) (*RegistrationPolicy, error) {
+	var p RegistrationPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid registration policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid registration policy: %w", err)
+	}
+
+	return &p, nil
+}
+
+// Validate checks that the bounds of the policy are consistent
+func (p *RegistrationPolicy) Validate() error {
+	if p.OpenTime != nil && p.CloseTime != nil && !p.OpenTime.Before(*p.CloseTime) {
+		return fmt.Errorf("open time %s should be before close time %s",
+			p.OpenTime.Format(time.RFC3339), p.CloseTime.Format(time.RFC3339))
+	}
+	if p.OpenBtcHeight > 0 && p.CloseBtcHeight > 0 && p.OpenBtcHeight >= p.CloseBtcHeight {
+		return fmt.Errorf("open btc height %d should be lower than close btc height %d", p.OpenBtcHeight, p.CloseBtcHeight)
+	}
+	if p.MaxProviders < 0 {
+		return fmt.Errorf("max providers should not be negative")
+	}
+	return nil
+}
+
+// HasHeightBounds returns true if the policy bounds registration with BTC
+// heights, which then need to be known to check a registration
+func (p *RegistrationPolicy) HasHeightBounds() bool {
+	return p.OpenBtcHeight > 0 || p.CloseBtcHeight > 0
+}
+
+// CheckOpen returns an error if registration is not open at the clock
+func (p *RegistrationPolicy) CheckOpen(clock *RegistrationClock) error {
+	if p.OpenTime != nil && clock.Time.Before(*p.OpenTime) {
+		return fmt.Errorf("registration opens at %s", p.OpenTime.Format(time.RFC3339))
+	}
+	if p.CloseTime != nil && !clock.Time.Before(*p.CloseTime) {
+		return fmt.Errorf("registration closed at %s", p.CloseTime.Format(time.RFC3339))
+	}
+
+	if !p.HasHeightBounds() {
+		return nil
+	}
+	if clock.BtcHeight == 0 {
+		return fmt.Errorf("registration is bounded by btc heights but the btc height is unknown")
+	}
+	if p.OpenBtcHeight > 0 && clock.BtcHeight < p.OpenBtcHeight {
+		return fmt.Errorf("registration opens at btc height %d", p.OpenBtcHeight)
+	}
+	if p.CloseBtcHeight > 0 && clock.BtcHeight >= p.CloseBtcHeight {
+		return fmt.Errorf("registration closed at btc height %d", p.CloseBtcHeight)
+	}
+
+	return nil
+}
+
+// CheckQuota returns an error if the registry has more entries than allowed
+func (p *RegistrationPolicy) CheckQuota(numProviders int) error {
+	if p.MaxProviders > 0 && numProviders > p.MaxProviders {
+		return fmt.Errorf("registration quota of %d finality providers exceeded with %d entries", p.MaxProviders, numProviders)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package registry_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func TestBbnTest4RegistrationPolicy(t *testing.T) {
+	policy, err := registry.NewRegistrationPolicyFromFile(registry.RegistrationPolicyPath(bbnTest4FpDir))
+	require.NoError(t, err)
+
+	// registration closed on 24 June 2024 end of day anywhere on earth
+	lastMinute := time.Date(2024, 6, 25, 11, 59, 0, 0, time.UTC)
+	require.NoError(t, policy.CheckOpen(&registry.RegistrationClock{Time: lastMinute}))
+	err = policy.CheckOpen(&registry.RegistrationClock{Time: lastMinute.Add(time.Minute)})
+	assert.Equal(t, "registration closed at 2024-06-25T00:00:00-12:00", err.Error())
+
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+	require.NoError(t, policy.CheckQuota(len(entries)))
+	err = policy.CheckQuota(len(entries) + 1)
+	assert.Equal(t, "registration quota of 234 finality providers exceeded with 235 entries", err.Error())
+}
+
+func TestRegistrationPolicyHeights(t *testing.T) {
+	policy, err := registry.NewRegistrationPolicyFromBytes([]byte(
+		`{"open_time": "2024-01-01T00:00:00Z", "open_btc_height": 100, "close_btc_height": 200}`))
+	require.NoError(t, err)
+	assert.True(t, policy.HasHeightBounds())
+
+	now := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		clock *registry.RegistrationClock
+		err   string
+	}{
+		{&registry.RegistrationClock{Time: now, BtcHeight: 100}, ""},
+		{&registry.RegistrationClock{Time: now, BtcHeight: 199}, ""},
+		{&registry.RegistrationClock{Time: now, BtcHeight: 99}, "registration opens at btc height 100"},
+		{&registry.RegistrationClock{Time: now, BtcHeight: 200}, "registration closed at btc height 200"},
+		{&registry.RegistrationClock{Time: now}, "registration is bounded by btc heights but the btc height is unknown"},
+		{
+			&registry.RegistrationClock{Time: now.AddDate(-1, 0, 0), BtcHeight: 150},
+			"registration opens at 2024-01-01T00:00:00Z",
+		},
+	}
+	for _, tc := range tests {
+		err := policy.CheckOpen(tc.clock)
+		if tc.err == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		require.Error(t, err)
+		assert.Equal(t, tc.err, err.Error())
+	}
+
+	// without bounds, registration is always open
+	var open registry.RegistrationPolicy
+	require.NoError(t, open.CheckOpen(&registry.RegistrationClock{}))
+	require.NoError(t, open.CheckQuota(1000))
+}
+
+func TestFailRegistrationPolicyParsing(t *testing.T) {
+	for content, expected := range map[string]string{
+		`{"open_time": "2024-06-25T00:00:00Z", "close_time": "2024-06-24T00:00:00Z"}`: "invalid registration policy: open time 2024-06-25T00:00:00Z should be before close time 2024-06-24T00:00:00Z",
+		`{"open_btc_height": 10, "close_btc_height": 10}`:                             "invalid registration policy: open btc height 10 should be lower than close btc height 10",
+		`{"max_providers": -1}`: "invalid registration policy: max providers should not be negative",
+	} {
+		_, err := registry.NewRegistrationPolicyFromBytes([]byte(content))
+		require.Error(t, err, content)
+		assert.Equal(t, expected, err.Error())
+	}
+
+	_, err := registry.NewRegistrationPolicyFromBytes([]byte(`{"close_time": "24 June"}`))
+	assert.Contains(t, err.Error(), "invalid registration policy")
+
+	_, err = registry.NewRegistrationPolicyFromFile(filepath.Join(t.TempDir(), registry.RegistrationPolicyFileName))
+	assert.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/networks/parameters/bip322"
+)
+
+const sigFileExt = ".sig"
+
+// SignatureScheme is what the signature of an entry covers. It is written as
+// a prefix of the signature file, e.g. "canonical-v1:<hex signature>", except
+// for the legacy raw scheme whose files only hold the hex signature.
+type SignatureScheme string
+
+const (
+	// RawScheme signs the exact bytes of the registry file, so reformatting
+	// the file invalidates the signature
+	RawScheme SignatureScheme = "raw"
+	// CanonicalV1Scheme signs the canonical JSON encoding of the entry after
+	// a domain separation tag of the network, so the file can be reformatted
+	// but the signature cannot be replayed on another network
+	CanonicalV1Scheme SignatureScheme = "canonical-v1"
+	// Bip322SimpleScheme is a BIP322 simple signature of the canonical-v1
+	// data by the BIP86 taproot address of btc_pk, as produced by wallets
+	// which cannot sign a hash with BIP340. The signature is base64 encoded.
+	Bip322SimpleScheme SignatureScheme = "bip322-simple"
+
+	canonicalV1DomainPrefix = "babylon-fp-registry:canonical-v1:"
+	schemeSeparator         = ":"
+)
+
+// EntrySignature is the content of a signature file: a BIP340 signature for
+// the raw and canonical-v1 schemes, or the witness of a BIP322 signature
+type EntrySignature struct {
+	Scheme  SignatureScheme
+	Sig     *schnorr.Signature
+	Witness wire.TxWitness
+}
+
+// SignaturePath returns the path of the signature of the entry with the given
+// nickname
+func SignaturePath(fpDir, nickname string) string {
+	return filepath.Join(fpDir, SigsDirName, nickname+sigFileExt)
+}
+
+// NetworkFromFpDir returns the network of a finality providers directory,
+// i.e. the chain id of the descriptor of its network (e.g. bbn-test-4). It
+// does not depend on the name of the directories, so a renamed copy of the
+// registry signs and verifies for the same network, and a directory without
+// a descriptor is an error.
+func NetworkFromFpDir(fpDir string) (string, error) {
+	n, err := LoadFpNetwork(fpDir)
+	if err != nil {
+		return "", err
+	}
+	return n.ChainID, nil
+}
+
+// schemeNetwork returns the network of the finality providers directory if
+// the scheme signs for a network, raw signatures not depending on it
+func schemeNetwork(scheme SignatureScheme, fpDir string) (string, error) {
+	if scheme == RawScheme {
+		return "", nil
+	}
+	return NetworkFromFpDir(fpDir)
+}
+
+// SignatureHash is the hash signed by the finality provider key: the sha256
+// of the signed data, as signed by eotsd sign-schnorr
+func SignatureHash(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// CanonicalV1Domain returns the domain separation tag of the canonical-v1
+// scheme for the network, which ends with a new line
+func CanonicalV1Domain(network string) []byte {
+	return []byte(canonicalV1DomainPrefix + network + "\n")
+}
+
+// signedData returns the data of a signed file under the given scheme, from
+// its raw bytes or its canonical encoding
+func signedData(scheme SignatureScheme, network string, raw []byte, canonicalBytes func() ([]byte, error)) ([]byte, error) {
+	switch scheme {
+	case RawScheme:
+		return raw, nil
+	case CanonicalV1Scheme, Bip322SimpleScheme:
+		if network == "" {
+			return nil, fmt.Errorf("the %s scheme needs a network", scheme)
+		}
+		canonical, err := canonicalBytes()
+		if err != nil {
+			return nil, err
+		}
+		return append(CanonicalV1Domain(network), canonical...), nil
+	default:
+		return nil, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+}
+
+// SignedData returns the data of the entry signed under the given scheme. Raw
+// signatures cover the file bytes, which as JSON never start with the domain
+// tag of the canonical scheme.
+func SignedData(scheme SignatureScheme, network string, e *Entry) ([]byte, error) {
+	return signedData(scheme, network, e.Raw, e.FinalityProvider.CanonicalBytes)
+}
+
+func parseSchnorrSignatureHex(sigHex string) (*schnorr.Signature, error) {
+	sigBytes, err := hex.DecodeString(sigHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	sig, err := schnorr.ParseSignature(sigBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return sig, nil
+}
+
+// ParseSignature parses the content of a signature file: the hex encoded
+// BIP340 signature, prefixed by its scheme unless it is a raw signature.
+// Surrounding white space and quotes are ignored, as they were by the
+// verification script.
+func ParseSignature(data []byte) (*EntrySignature, error) {
+	content := strings.TrimSpace(string(data))
+	if len(content) >= 2 && strings.HasPrefix(content, `"`) && strings.HasSuffix(content, `"`) {
+		content = content[1 : len(content)-1]
+	}
+
+	scheme := RawScheme
+	if prefix, encoded, ok := strings.Cut(content, schemeSeparator); ok {
+		scheme = SignatureScheme(prefix)
+		content = encoded
+	}
+
+	switch scheme {
+	case RawScheme, CanonicalV1Scheme:
+	case Bip322SimpleScheme:
+		witness, err := bip322.ParseSimpleSignature(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature: %w", err)
+		}
+		return &EntrySignature{Scheme: scheme, Witness: witness}, nil
+	default:
+		return nil, fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+
+	sig, err := parseSchnorrSignatureHex(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EntrySignature{Scheme: scheme, Sig: sig}, nil
+}
+
+func NewSignatureFromFile(filePath string) (*EntrySignature, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSignature(data)
+}
+
+// FormatSignature returns the content of the signature file of sig. Raw
+// signatures are written without prefix, as legacy signature files.
+func FormatSignature(sig *EntrySignature) []byte {
+	if sig.Scheme == Bip322SimpleScheme {
+		return []byte(string(sig.Scheme) + schemeSeparator + bip322.FormatSimpleSignature(sig.Witness) + "\n")
+	}
+
+	sigHex := hex.EncodeToString(sig.Sig.Serialize())
+	if sig.Scheme == RawScheme {
+		return []byte(sigHex + "\n")
+	}
+	return []byte(string(sig.Scheme) + schemeSeparator + sigHex + "\n")
+}
+
+// verifySignature checks that sig is a signature of data by the key btcPkHex,
+// or its taproot address for BIP322. The name of the signed file is only used
+// in errors.
+func verifySignature(name, btcPkHex string, data []byte, sig *EntrySignature) error {
+	btcPk, err := parseBtcPkFromHex(btcPkHex)
+	if err != nil {
+		return fmt.Errorf("invalid btc_pk %s: %w", btcPkHex, err)
+	}
+
+	if sig.Scheme == Bip322SimpleScheme {
+		pkScript, err := bip322.TaprootPkScript(btcPk)
+		if err != nil {
+			return err
+		}
+		if err := bip322.VerifySimple(data, pkScript, sig.Witness); err != nil {
+			return fmt.Errorf("%s signature of %s is not valid for the taproot address of btc_pk %s: %w",
+				sig.Scheme, name, btcPkHex, err)
+		}
+		return nil
+	}
+
+	if !sig.Sig.Verify(SignatureHash(data), btcPk) {
+		return fmt.Errorf("%s signature of %s is not valid for btc_pk %s", sig.Scheme, name, btcPkHex)
+	}
+
+	return nil
+}
+
+// VerifySignature checks that the signature of the entry is a signature by
+// its btc_pk, or its taproot address for BIP322, of the data covered by the
+// signature scheme
+func VerifySignature(e *Entry, sig *EntrySignature, network string) error {
+	data, err := SignedData(sig.Scheme, network, e)
+	if err != nil {
+		return fmt.Errorf("'%s': %w", e.Nickname, err)
+	}
+
+	return verifySignature(fmt.Sprintf("'%s'", e.Nickname), e.FinalityProvider.BtcPk, data, sig)
+}
+
+// VerifyEntrySignature loads the signature of the entry from the sigs
+// directory and verifies it for the network of the directory
+func VerifyEntrySignature(fpDir string, e *Entry) error {
+	sig, err := NewSignatureFromFile(SignaturePath(fpDir, e.Nickname))
+	if err != nil {
+		return fmt.Errorf("'%s': %w", e.Nickname, err)
+	}
+
+	network, err := schemeNetwork(sig.Scheme, fpDir)
+	if err != nil {
+		return err
+	}
+
+	return VerifySignature(e, sig, network)
+}
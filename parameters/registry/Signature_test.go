@@ -0,0 +1,342 @@
+package registry_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/bip322"
+	"github.com/babylonchain/networks/parameters/btcstaking"
+	"github.com/babylonchain/networks/parameters/networks"
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+// the private key of the helper signer process
+const helperSignerKeyHex = "0101010101010101010101010101010101010101010101010101010101010101"
+
+func TestMain(m *testing.M) {
+	// the test binary acts as an eotsd sign-schnorr stand-in
+	if os.Getenv("FPREGISTRY_HELPER_SIGNER") == "1" {
+		os.Exit(runHelperSigner(os.Args[len(os.Args)-1]))
+	}
+	os.Exit(m.Run())
+}
+
+func runHelperSigner(filePath string) int {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	keyBytes, _ := hex.DecodeString(helperSignerKeyHex)
+	privKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+	sig, err := schnorr.Sign(privKey, registry.SignatureHash(data))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out, _ := json.Marshal(map[string]string{
+		"key_name":              "helper",
+		"pub_key_hex":           hex.EncodeToString(schnorr.SerializePubKey(privKey.PubKey())),
+		"signed_data_hash_hex":  hex.EncodeToString(registry.SignatureHash(data)),
+		"schnorr_signature_hex": hex.EncodeToString(sig.Serialize()),
+	})
+	fmt.Println(string(out))
+	return 0
+}
+
+func TestVerifyBbnTest4Signatures(t *testing.T) {
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+
+	for _, e := range entries {
+		require.NoError(t, registry.VerifyEntrySignature(bbnTest4FpDir, e), e.Nickname)
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	sigHex := "5e39939ccf68b8d30e134e132fe0e234b0840db3f380e17c57a0170c77235af3a555d8ea59eaacfaf43eaaa55d740549ee7f74cf844ed10dda2c81303006c348"
+	for _, content := range []string{sigHex, sigHex + "\n", "  " + sigHex + " \r\n", `"` + sigHex + `"` + "\n"} {
+		sig, err := registry.ParseSignature([]byte(content))
+		require.NoError(t, err)
+		assert.Equal(t, registry.RawScheme, sig.Scheme)
+		assert.Equal(t, sigHex, hex.EncodeToString(sig.Sig.Serialize()))
+		assert.Equal(t, sigHex+"\n", string(registry.FormatSignature(sig)))
+	}
+
+	sig, err := registry.ParseSignature([]byte("raw:" + sigHex))
+	require.NoError(t, err)
+	assert.Equal(t, registry.RawScheme, sig.Scheme)
+
+	sig, err = registry.ParseSignature([]byte("canonical-v1:" + sigHex + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, registry.CanonicalV1Scheme, sig.Scheme)
+	assert.Equal(t, "canonical-v1:"+sigHex+"\n", string(registry.FormatSignature(sig)))
+
+	_, err = registry.ParseSignature([]byte(sigHex[2:]))
+	assert.Contains(t, err.Error(), "invalid signature")
+	_, err = registry.ParseSignature([]byte("zz"))
+	assert.Contains(t, err.Error(), "invalid signature")
+	_, err = registry.ParseSignature([]byte("canonical-v9:" + sigHex))
+	assert.Equal(t, `unknown signature scheme "canonical-v9"`, err.Error())
+}
+
+func TestWriteSignedEntry(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	signer := registry.NewKeySigner(privKey)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+
+	fpDir := t.TempDir()
+	entry, err := registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.RawScheme, signer)
+	require.NoError(t, err)
+	assert.Equal(t, "my_fp", entry.Nickname)
+	assert.Equal(t, filepath.Join(fpDir, registry.RegistryDirName, "my_fp.json"), entry.Path)
+	assert.True(t, strings.HasSuffix(string(entry.Raw), "}\n"))
+	assert.Equal(t, fp, entry.FinalityProvider)
+	require.NoError(t, registry.VerifyEntrySignature(fpDir, entry))
+
+	// a white space edit after signing breaks the signature
+	require.NoError(t, os.WriteFile(entry.Path, append(entry.Raw, '\n'), 0o644))
+	edited, err := registry.NewEntryFromFile(entry.Path)
+	require.NoError(t, err)
+	err = registry.VerifyEntrySignature(fpDir, edited)
+	assert.Equal(t, "raw signature of 'my_fp' is not valid for btc_pk "+fp.BtcPk, err.Error())
+}
+
+func TestCanonicalSignature(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	signer := registry.NewKeySigner(privKey)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+
+	fpDir := newTestFpDir(t)
+	entry, err := registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.CanonicalV1Scheme, signer)
+	require.NoError(t, err)
+	sigData, err := os.ReadFile(registry.SignaturePath(fpDir, "my_fp"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(sigData), "canonical-v1:"))
+
+	// reformatting the file keeps the signature valid
+	var compact bytes.Buffer
+	require.NoError(t, json.Compact(&compact, entry.Raw))
+	require.NoError(t, os.WriteFile(entry.Path, compact.Bytes(), 0o644))
+	reformatted, err := registry.NewEntryFromFile(entry.Path)
+	require.NoError(t, err)
+	require.NoError(t, registry.VerifyEntrySignature(fpDir, reformatted))
+
+	sig, err := registry.NewSignatureFromFile(registry.SignaturePath(fpDir, "my_fp"))
+	require.NoError(t, err)
+
+	// the network is the chain id of the descriptor, whatever the name of
+	// the directories
+	network, err := registry.NetworkFromFpDir(fpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "bbn-test-4", network)
+
+	// the signature does not verify for another network
+	err = registry.VerifySignature(reformatted, sig, "bbn-test-5")
+	assert.Equal(t, "canonical-v1 signature of 'my_fp' is not valid for btc_pk "+fp.BtcPk, err.Error())
+
+	// nor once the content changed
+	edited := *reformatted
+	editedFp := *fp
+	editedFp.Commission = "0.06"
+	edited.FinalityProvider = &editedFp
+	err = registry.VerifySignature(&edited, sig, "bbn-test-4")
+	assert.Equal(t, "canonical-v1 signature of 'my_fp' is not valid for btc_pk "+fp.BtcPk, err.Error())
+
+	// a canonical signature is not a raw signature of the file
+	err = registry.VerifySignature(reformatted, &registry.EntrySignature{Scheme: registry.RawScheme, Sig: sig.Sig}, "bbn-test-4")
+	assert.Equal(t, "raw signature of 'my_fp' is not valid for btc_pk "+fp.BtcPk, err.Error())
+}
+
+func TestBip322Signature(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+
+	// the entry is written with a raw signature, which is replaced by a
+	// wallet signature
+	fpDir := newTestFpDir(t)
+	entry, err := registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.RawScheme, registry.NewKeySigner(privKey))
+	require.NoError(t, err)
+	message, err := registry.SignedData(registry.Bip322SimpleScheme, "bbn-test-4", entry)
+	require.NoError(t, err)
+	witness, err := bip322.SignSimpleTaproot(message, privKey)
+	require.NoError(t, err)
+	sig := &registry.EntrySignature{Scheme: registry.Bip322SimpleScheme, Witness: witness}
+	sigContent := registry.FormatSignature(sig)
+	assert.True(t, strings.HasPrefix(string(sigContent), "bip322-simple:"))
+	require.NoError(t, os.WriteFile(registry.SignaturePath(fpDir, "my_fp"), sigContent, 0o644))
+	require.NoError(t, registry.VerifyEntrySignature(fpDir, entry))
+
+	parsed, err := registry.ParseSignature(sigContent)
+	require.NoError(t, err)
+	assert.Equal(t, sig, parsed)
+
+	// as the canonical-v1 scheme, the message commits to the network
+	err = registry.VerifySignature(entry, sig, "bbn-test-5")
+	assert.Contains(t, err.Error(), "bip322-simple signature of 'my_fp' is not valid for the taproot address of btc_pk "+fp.BtcPk)
+
+	// signed by another key
+	other, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	witness, err = bip322.SignSimpleTaproot(message, other)
+	require.NoError(t, err)
+	err = registry.VerifySignature(entry, &registry.EntrySignature{Scheme: registry.Bip322SimpleScheme, Witness: witness}, "bbn-test-4")
+	assert.Contains(t, err.Error(), "bip322-simple signature of 'my_fp' is not valid")
+
+	_, err = registry.ParseSignature([]byte("bip322-simple:AA=="))
+	assert.Equal(t, "invalid signature: invalid witness: no items", err.Error())
+
+	_, err = registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.Bip322SimpleScheme, registry.NewKeySigner(privKey))
+	assert.Equal(t, "bip322-simple signatures are made by a wallet, not by a signer", err.Error())
+}
+
+func TestFailWriteSignedEntry(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+	fpDir := t.TempDir()
+
+	_, err = registry.WriteSignedEntry(fpDir, "my fp", fp, registry.RawScheme, registry.NewKeySigner(privKey))
+	assert.Equal(t, `nickname "my fp" should only contain letters, digits, '_', '.' and '-'`, err.Error())
+
+	invalid := *fp
+	invalid.Commission = "2"
+	_, err = registry.WriteSignedEntry(fpDir, "my_fp", &invalid, registry.RawScheme, registry.NewKeySigner(privKey))
+	assert.Equal(t, "invalid commission: commission 2 is larger than 1", err.Error())
+
+	_, err = registry.WriteSignedEntry(newTestFpDir(t), "my_fp", fp, registry.SignatureScheme("v2"), registry.NewKeySigner(privKey))
+	assert.Equal(t, `unknown signature scheme "v2"`, err.Error())
+
+	// the network of a canonical signature is the one of the descriptor of
+	// the network, not the name of the directory
+	_, err = registry.WriteSignedEntry(filepath.Join(fpDir, "bbn-test-4", "finality-providers"), "my_fp", fp,
+		registry.CanonicalV1Scheme, registry.NewKeySigner(privKey))
+	assert.Contains(t, err.Error(), "failed to load the network of")
+
+	// signed by another key
+	other, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	_, err = registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.RawScheme, registry.NewKeySigner(other))
+	assert.Equal(t, "written entry does not verify: raw signature of 'my_fp' is not valid for btc_pk "+fp.BtcPk, err.Error())
+	assert.NoFileExists(t, registry.SignaturePath(fpDir, "my_fp"))
+}
+
+func TestCommandSigner(t *testing.T) {
+	t.Setenv("FPREGISTRY_HELPER_SIGNER", "1")
+	keyBytes, err := hex.DecodeString(helperSignerKeyHex)
+	require.NoError(t, err)
+	privKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+
+	signer := registry.NewCommandSigner(os.Args[0], "sign-schnorr", "--key-name", "helper")
+	for _, scheme := range []registry.SignatureScheme{registry.RawScheme, registry.CanonicalV1Scheme} {
+		fpDir := newTestFpDir(t)
+		entry, err := registry.WriteSignedEntry(fpDir, "my_fp", fp, scheme, signer)
+		require.NoError(t, err, scheme)
+		assert.Equal(t, fp, entry.FinalityProvider)
+	}
+
+	_, err = registry.NewCommandSigner("false").Sign([]byte("{}"))
+	assert.Contains(t, err.Error(), "signer false")
+}
+
+func TestNewKeySignerFromFile(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.SigNetParams, true)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"hex": hex.EncodeToString(privKey.Serialize()) + "\n",
+		"wif": wif.String(),
+	} {
+		filePath := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+		signer, err := registry.NewKeySignerFromFile(filePath)
+		require.NoError(t, err)
+		assert.True(t, privKey.PubKey().IsEqual(signer.PublicKey()), name)
+	}
+
+	filePath := filepath.Join(dir, "short")
+	require.NoError(t, os.WriteFile(filePath, []byte("0102"), 0o600))
+	_, err = registry.NewKeySignerFromFile(filePath)
+	assert.Contains(t, err.Error(), "expected 32 bytes, got 2")
+
+	require.NoError(t, os.WriteFile(filePath, []byte("not a key"), 0o600))
+	_, err = registry.NewKeySignerFromFile(filePath)
+	assert.Contains(t, err.Error(), "neither hex nor WIF")
+}
+
+// newSignedTestEntry returns a valid entry of the given finality provider key,
+// with a deposit to it
+func newSignedTestEntry(t *testing.T, fpPk *btcec.PublicKey) *registry.FinalityProvider {
+	stakerKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	covenantPkBytes, err := hex.DecodeString(registry.DepositCovenantPkHex)
+	require.NoError(t, err)
+	covenantPk, err := schnorr.ParsePubKey(covenantPkBytes)
+	require.NoError(t, err)
+	info, err := btcstaking.BuildStakingInfo(stakerKey.PubKey(), []*btcec.PublicKey{fpPk},
+		[]*btcec.PublicKey{covenantPk}, registry.DepositCovenantQuorum, registry.DepositStakingTime, 10000000)
+	require.NoError(t, err)
+
+	tag, err := hex.DecodeString(registry.DepositTagHex)
+	require.NoError(t, err)
+	data := append(tag, 0)
+	data = append(data, schnorr.SerializePubKey(stakerKey.PubKey())...)
+	data = append(data, schnorr.SerializePubKey(fpPk)...)
+	data = append(data, byte(registry.DepositStakingTime>>8), byte(registry.DepositStakingTime&0xff))
+	opReturn, err := txscript.NullDataScript(data)
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0), nil, nil))
+	tx.AddTxOut(info.StakingOutput)
+	tx.AddTxOut(wire.NewTxOut(0, opReturn))
+
+	return &registry.FinalityProvider{
+		Description: registry.Description{
+			Moniker:         "my fp",
+			Website:         "https://my-fp.com",
+			SecurityContact: "security@my-fp.com",
+			Details:         "<html> & \"quotes\"",
+		},
+		BtcPk:      hex.EncodeToString(schnorr.SerializePubKey(fpPk)),
+		Commission: "0.05",
+		Deposit: registry.Deposit{
+			TxHash:   tx.TxHash().String(),
+			SignedTx: hexTx(t, tx),
+		},
+	}
+}
+
+// newTestFpDir returns a finality providers directory whose network
+// descriptor is for bbn-test-4, in a directory of another name
+func newTestFpDir(t *testing.T) string {
+	networkDir := filepath.Join(t.TempDir(), "network")
+	require.NoError(t, os.MkdirAll(networkDir, 0o755))
+	descriptor := `{"chain_id": "bbn-test-4", "btc_network": "signet", "lock_only": true}`
+	require.NoError(t, os.WriteFile(filepath.Join(networkDir, networks.NetworkFileName), []byte(descriptor), 0o644))
+	return filepath.Join(networkDir, "finality-providers")
+}
@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// Signer signs the data covered by a signature scheme with the finality
+// provider key
+type Signer interface {
+	Sign(data []byte) (*schnorr.Signature, error)
+}
+
+// KeySigner signs with a private key held in memory
+type KeySigner struct {
+	privKey *btcec.PrivateKey
+}
+
+func NewKeySigner(privKey *btcec.PrivateKey) *KeySigner {
+	return &KeySigner{privKey: privKey}
+}
+
+// NewKeySignerFromFile reads a private key file, holding either the hex
+// encoded 32 bytes private key or its WIF encoding
+func NewKeySignerFromFile(filePath string) (*KeySigner, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	keyStr := strings.TrimSpace(string(data))
+
+	if keyBytes, err := hex.DecodeString(keyStr); err == nil {
+		if len(keyBytes) != btcec.PrivKeyBytesLen {
+			return nil, fmt.Errorf("invalid private key file %s: expected %d bytes, got %d", filePath, btcec.PrivKeyBytesLen, len(keyBytes))
+		}
+		privKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+		return NewKeySigner(privKey), nil
+	}
+
+	wif, err := btcutil.DecodeWIF(keyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key file %s: neither hex nor WIF", filePath)
+	}
+	return NewKeySigner(wif.PrivKey), nil
+}
+
+func (s *KeySigner) PublicKey() *btcec.PublicKey {
+	return s.privKey.PubKey()
+}
+
+func (s *KeySigner) Sign(data []byte) (*schnorr.Signature, error) {
+	return schnorr.Sign(s.privKey, SignatureHash(data))
+}
+
+// CommandSigner runs an external signer with the path of a file holding the
+// data to sign as last argument, e.g. eotsd sign-schnorr --key-name <name>.
+// The command should print the JSON output of eotsd sign-schnorr.
+type CommandSigner struct {
+	Name string
+	Args []string
+}
+
+func NewCommandSigner(name string, args ...string) *CommandSigner {
+	return &CommandSigner{Name: name, Args: args}
+}
+
+type commandSignerOutput struct {
+	PubKeyHex           string `json:"pub_key_hex"`
+	SchnorrSignatureHex string `json:"schnorr_signature_hex"`
+}
+
+func (s *CommandSigner) Sign(data []byte) (*schnorr.Signature, error) {
+	dir, err := os.MkdirTemp("", "fpregistry-sign")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	dataPath := filepath.Join(dir, "entry"+entryFileExt)
+	if err := os.WriteFile(dataPath, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(s.Name, append(append([]string{}, s.Args...), dataPath)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: %w: %s", s.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var output commandSignerOutput
+	if err := json.Unmarshal(out, &output); err != nil {
+		return nil, fmt.Errorf("invalid output of signer %s: %w", s.Name, err)
+	}
+
+	return parseSchnorrSignatureHex(strings.TrimSpace(output.SchnorrSignatureHex))
+}
+
+// marshalIndented encodes v as registry files are formatted: indented with
+// two spaces and terminated by a new line
+func marshalIndented(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalEntry encodes the entry as registry files are formatted
+func MarshalEntry(fp *FinalityProvider) ([]byte, error) {
+	return marshalIndented(fp)
+}
+
+// writeFile writes the file, creating its directory if needed
+func writeFile(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o644)
+}
+
+// WriteSignedEntry writes the entry to the registry directory, signs it under
+// the given scheme for the network of the directory, writes the signature to
+// the sigs directory, and verifies both files as read back from disk
+func WriteSignedEntry(
+	fpDir, nickname string,
+	fp *FinalityProvider,
+	scheme SignatureScheme,
+	signer Signer,
+) (*Entry, error) {
+	if err := ValidateNickname(nickname); err != nil {
+		return nil, err
+	}
+	if _, err := ParseFinalityProvider(fp); err != nil {
+		return nil, err
+	}
+	if scheme == Bip322SimpleScheme {
+		return nil, fmt.Errorf("%s signatures are made by a wallet, not by a signer", scheme)
+	}
+	network, err := schemeNetwork(scheme, fpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := MarshalEntry(fp)
+	if err != nil {
+		return nil, err
+	}
+	entryPath := filepath.Join(fpDir, RegistryDirName, nickname+entryFileExt)
+	signedData, err := SignedData(scheme, network, &Entry{
+		Nickname:         nickname,
+		Path:             entryPath,
+		Raw:              data,
+		FinalityProvider: fp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign '%s': %w", nickname, err)
+	}
+
+	if err := writeFile(entryPath, data); err != nil {
+		return nil, err
+	}
+	sigPath := SignaturePath(fpDir, nickname)
+	if err := writeFile(sigPath, FormatSignature(&EntrySignature{Scheme: scheme, Sig: sig})); err != nil {
+		return nil, err
+	}
+
+	entry, err := NewEntryFromFile(entryPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyEntrySignature(fpDir, entry); err != nil {
+		// a signature which does not verify should not be submitted
+		_ = os.Remove(sigPath)
+		return nil, fmt.Errorf("written entry does not verify: %w", err)
+	}
+
+	return entry, nil
+}
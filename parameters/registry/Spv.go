@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+// SpvProofPath returns the path of the sidecar SPV proof of the entry with
+// the given nickname
+func SpvProofPath(fpDir, nickname string) string {
+	return filepath.Join(fpDir, SpvDirName, nickname+entryFileExt)
+}
+
+func NewSpvProofFromFile(filePath string) (*spv.Proof, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof spv.Proof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return nil, fmt.Errorf("invalid spv proof file %s: %w", filePath, err)
+	}
+
+	return &proof, nil
+}
+
+// LoadSpvProof returns the SPV proof of the deposit of the entry, either
+// embedded in the entry or from its sidecar file. It returns nil if the entry
+// has no proof, and fails if both are provided.
+func LoadSpvProof(fpDir string, e *Entry) (*spv.Proof, error) {
+	sidecar, err := NewSpvProofFromFile(SpvProofPath(fpDir, e.Nickname))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	embedded := e.FinalityProvider.Deposit.SpvProof
+	if embedded != nil && sidecar != nil {
+		return nil, fmt.Errorf("'%s' has an spv proof both in its entry and in %s", e.Nickname, SpvDirName)
+	}
+	if embedded != nil {
+		return embedded, nil
+	}
+
+	return sidecar, nil
+}
+
+// VerifyDepositSpv checks with the SPV proof that the deposit transaction is
+// included in a chain of headers building on the trusted anchor and valid
+// under the given network rules, and that it has at least minConfirmations
+// confirmations. No network access is needed.
+func VerifyDepositSpv(
+	d *ParsedDeposit,
+	proof *spv.Proof,
+	anchor *spv.ParsedAnchor,
+	params *chaincfg.Params,
+	minConfirmations uint64,
+) (*spv.VerifiedProof, error) {
+	if anchor == nil {
+		return nil, fmt.Errorf("no btc anchor is pinned for the network, the spv proof of deposit tx %s cannot be trusted", d.TxHash)
+	}
+	verified, err := proof.Verify(&d.TxHash, anchor, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spv proof of deposit tx %s: %w", d.TxHash, err)
+	}
+
+	if verified.Confirmations < minConfirmations {
+		return nil, fmt.Errorf("deposit tx %s has %d confirmations, it should have at least %d",
+			d.TxHash, verified.Confirmations, minConfirmations)
+	}
+
+	return verified, nil
+}
@@ -0,0 +1,168 @@
+package registry_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+func TestVerifyDepositSpv(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	deposit, err := registry.ParseDeposit(&newTestEntry(t).Deposit)
+	require.NoError(t, err)
+
+	anchor := testSpvAnchor(params)
+	proof := newDepositSpvProof(t, params, deposit, 6)
+	verified, err := registry.VerifyDepositSpv(deposit, proof, anchor, params, registry.DefaultDepositConfirmations)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), verified.Confirmations)
+	assert.Equal(t, uint64(200000), verified.BlockHeight)
+
+	// 5 confirmations
+	proof.Headers = proof.Headers[:5]
+	_, err = registry.VerifyDepositSpv(deposit, proof, anchor, params, registry.DefaultDepositConfirmations)
+	assert.Equal(t, "deposit tx "+deposit.TxHash.String()+" has 5 confirmations, it should have at least 6", err.Error())
+
+	// regtest headers are not valid signet headers
+	_, err = registry.VerifyDepositSpv(deposit, proof, anchor, &chaincfg.SigNetParams, registry.DefaultDepositConfirmations)
+	assert.Contains(t, err.Error(), "invalid spv proof of deposit tx "+deposit.TxHash.String())
+
+	// the headers have to build on the anchor, which sets their height
+	_, err = registry.VerifyDepositSpv(deposit, proof, spv.GenesisAnchor(params), params, 1)
+	assert.Contains(t, err.Error(), "first header does not build on the anchor")
+	_, err = registry.VerifyDepositSpv(deposit, proof, nil, params, 1)
+	assert.Equal(t, "no btc anchor is pinned for the network, the spv proof of deposit tx "+
+		deposit.TxHash.String()+" cannot be trusted", err.Error())
+}
+
+func TestLoadFpNetwork(t *testing.T) {
+	n, err := registry.LoadFpNetwork(bbnTest4FpDir)
+	require.NoError(t, err)
+	assert.Equal(t, "bbn-test-4", n.ChainID)
+	assert.Equal(t, &chaincfg.SigNetParams, n.BtcNetwork)
+	assert.Equal(t, spv.GenesisAnchor(&chaincfg.SigNetParams), n.BtcAnchor)
+
+	_, err = registry.LoadFpNetwork(filepath.Join(t.TempDir(), "finality-providers"))
+	assert.Contains(t, err.Error(), "failed to load the network of")
+}
+
+func TestLoadSpvProof(t *testing.T) {
+	fpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(fpDir, registry.RegistryDirName), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(fpDir, registry.SpvDirName), 0o700))
+
+	fp := newTestEntry(t)
+	deposit, err := registry.ParseDeposit(&fp.Deposit)
+	require.NoError(t, err)
+	proof := newDepositSpvProof(t, &chaincfg.RegressionNetParams, deposit, 1)
+
+	writeEntry := func(fp *registry.FinalityProvider) *registry.Entry {
+		filePath := filepath.Join(fpDir, registry.RegistryDirName, "GalaxyDigital.json")
+		data, err := json.MarshalIndent(fp, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filePath, data, 0o600))
+		entry, err := registry.NewEntryFromFile(filePath)
+		require.NoError(t, err)
+		return entry
+	}
+
+	// no proof
+	entry := writeEntry(fp)
+	loaded, err := registry.LoadSpvProof(fpDir, entry)
+	require.NoError(t, err)
+	assert.Nil(t, loaded)
+
+	// embedded in the entry
+	withProof := *fp
+	withProof.Deposit.SpvProof = proof
+	entry = writeEntry(&withProof)
+	loaded, err = registry.LoadSpvProof(fpDir, entry)
+	require.NoError(t, err)
+	assert.Equal(t, proof, loaded)
+
+	// the embedded proof is part of the canonical entry
+	canonical, err := withProof.CanonicalBytes()
+	require.NoError(t, err)
+	assert.Contains(t, string(canonical), `"spv_proof":{"headers":[`)
+
+	// sidecar file
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(registry.SpvProofPath(fpDir, "GalaxyDigital"), data, 0o600))
+	entry = writeEntry(fp)
+	loaded, err = registry.LoadSpvProof(fpDir, entry)
+	require.NoError(t, err)
+	assert.Equal(t, proof, loaded)
+
+	// both
+	entry = writeEntry(&withProof)
+	_, err = registry.LoadSpvProof(fpDir, entry)
+	assert.Equal(t, "'GalaxyDigital' has an spv proof both in its entry and in spv", err.Error())
+
+	require.NoError(t, os.WriteFile(registry.SpvProofPath(fpDir, "GalaxyDigital"), []byte("{"), 0o600))
+	entry = writeEntry(fp)
+	_, err = registry.LoadSpvProof(fpDir, entry)
+	assert.Contains(t, err.Error(), "invalid spv proof file")
+}
+
+// testSpvAnchor returns an anchor at height 199999 at the minimum difficulty
+// of the network
+func testSpvAnchor(params *chaincfg.Params) *spv.ParsedAnchor {
+	return &spv.ParsedAnchor{
+		Height: 199999,
+		Hash:   chainhash.Hash{0xaa},
+		Bits:   blockchain.BigToCompact(params.PowLimit),
+	}
+}
+
+// newDepositSpvProof mines on the test anchor a regtest block at height
+// 200000 including the deposit, followed by confirmations-1 blocks, and
+// returns the proof of the deposit
+func newDepositSpvProof(t *testing.T, params *chaincfg.Params, d *registry.ParsedDeposit, confirmations int) *spv.Proof {
+	txHashes := []chainhash.Hash{{0x01}, d.TxHash, {0x02}}
+	merkleRoot := spv.MerkleRootFromBranch(&d.TxHash, 1, mustMerkleBranch(t, txHashes, 1))
+	anchor := testSpvAnchor(params)
+	bits := anchor.Bits
+
+	headers := make([]*wire.BlockHeader, confirmations)
+	prevHash := anchor.Hash
+	for i := range headers {
+		root := chainhash.Hash{byte(i)}
+		if i == 0 {
+			root = merkleRoot
+		}
+		header := wire.NewBlockHeader(4, &prevHash, &root, bits, 0)
+		header.Timestamp = time.Unix(1717000000, 0).Add(time.Duration(i) * params.TargetTimePerBlock)
+		for {
+			hash := header.BlockHash()
+			if blockchain.HashToBig(&hash).Cmp(params.PowLimit) <= 0 {
+				break
+			}
+			header.Nonce++
+		}
+		headers[i] = header
+		prevHash = header.BlockHash()
+	}
+
+	proof, err := spv.NewProof(headers, txHashes, 1)
+	require.NoError(t, err)
+	return proof
+}
+
+func mustMerkleBranch(t *testing.T, txHashes []chainhash.Hash, pos uint32) []chainhash.Hash {
+	branch, err := spv.BuildMerkleBranch(txHashes, pos)
+	require.NoError(t, err)
+	return branch
+}
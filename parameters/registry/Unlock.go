@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/babylonchain/networks/parameters/btcstaking"
+)
+
+// DepositUnlock is the unlock schedule of a deposit
+type DepositUnlock struct {
+	Nickname string
+	TxHash   chainhash.Hash
+	// ConfirmationHeight is the height of the block including the deposit
+	ConfirmationHeight uint64
+	// UnlockHeight is the height of the first block which can include the
+	// withdrawal of the deposit through its time lock path
+	UnlockHeight uint64
+	// Retired is set if the entry was retired, its deposit still being
+	// locked until the unlock height
+	Retired bool
+}
+
+// DepositUnlockHeight returns the height of the first block which can include
+// a withdrawal of a deposit included at the given height. The time lock path
+// is a relative time lock of DepositStakingTime blocks.
+func DepositUnlockHeight(confirmationHeight uint64) uint64 {
+	return confirmationHeight + DepositStakingTime
+}
+
+func NewDepositUnlock(nickname string, txHash chainhash.Hash, confirmationHeight uint64) *DepositUnlock {
+	return &DepositUnlock{
+		Nickname:           nickname,
+		TxHash:             txHash,
+		ConfirmationHeight: confirmationHeight,
+		UnlockHeight:       DepositUnlockHeight(confirmationHeight),
+	}
+}
+
+// BlocksRemaining returns the number of blocks to be mined on top of the tip
+// before the deposit can be withdrawn, zero if it can be withdrawn in the
+// next block
+func (u *DepositUnlock) BlocksRemaining(tipHeight uint64) uint64 {
+	if tipHeight+1 >= u.UnlockHeight {
+		return 0
+	}
+	return u.UnlockHeight - tipHeight - 1
+}
+
+// IsUnlocked returns whether the withdrawal can be included in the block
+// following the tip
+func (u *DepositUnlock) IsUnlocked(tipHeight uint64) bool {
+	return u.BlocksRemaining(tipHeight) == 0
+}
+
+// SortDepositUnlocks sorts the deposits by unlock height, so that the ones
+// with the least time remaining come first. Ties are broken by nickname.
+func SortDepositUnlocks(unlocks []*DepositUnlock) {
+	sort.SliceStable(unlocks, func(i, j int) bool {
+		if unlocks[i].UnlockHeight != unlocks[j].UnlockHeight {
+			return unlocks[i].UnlockHeight < unlocks[j].UnlockHeight
+		}
+		return unlocks[i].Nickname < unlocks[j].Nickname
+	})
+}
+
+// ParseDepositStakingTx parses the deposit of the entry and verifies its
+// staking transaction, without validating the rest of the entry, so that
+// every deposit can be withdrawn
+func ParseDepositStakingTx(fp *FinalityProvider) (*ParsedDeposit, *btcstaking.ParsedV0StakingTx, error) {
+	btcPk, err := parseBtcPkFromHex(fp.BtcPk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid btc_pk %s: %w", fp.BtcPk, err)
+	}
+
+	deposit, err := ParseDeposit(&fp.Deposit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid deposit: %w", err)
+	}
+
+	parsed, err := VerifyDepositStakingTx(deposit, btcPk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid deposit: %w", err)
+	}
+
+	return deposit, parsed, nil
+}
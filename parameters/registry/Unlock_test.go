@@ -0,0 +1,59 @@
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+func TestDepositUnlock(t *testing.T) {
+	u := registry.NewDepositUnlock("fp", chainhash.Hash{1}, 200000)
+	assert.Equal(t, uint64(252560), u.UnlockHeight)
+
+	// the withdrawal can be included in block 252560, i.e. once the tip is
+	// at 252559
+	assert.Equal(t, uint64(52559), u.BlocksRemaining(200000))
+	assert.Equal(t, uint64(1), u.BlocksRemaining(252558))
+	assert.False(t, u.IsUnlocked(252558))
+	assert.Equal(t, uint64(0), u.BlocksRemaining(252559))
+	assert.True(t, u.IsUnlocked(252559))
+	assert.True(t, u.IsUnlocked(300000))
+	// a tip below the confirmation height is a stale tip
+	assert.Equal(t, uint64(52660), u.BlocksRemaining(199899))
+}
+
+func TestSortDepositUnlocks(t *testing.T) {
+	unlocks := []*registry.DepositUnlock{
+		registry.NewDepositUnlock("c", chainhash.Hash{3}, 200010),
+		registry.NewDepositUnlock("b", chainhash.Hash{2}, 200000),
+		registry.NewDepositUnlock("a", chainhash.Hash{1}, 200010),
+	}
+	registry.SortDepositUnlocks(unlocks)
+
+	var nicknames []string
+	for _, u := range unlocks {
+		nicknames = append(nicknames, u.Nickname)
+	}
+	assert.Equal(t, []string{"b", "a", "c"}, nicknames)
+}
+
+func TestParseDepositStakingTx(t *testing.T) {
+	fp := newTestEntry(t)
+	deposit, parsed, err := registry.ParseDepositStakingTx(fp)
+	require.NoError(t, err)
+	assert.Equal(t, fp.Deposit.TxHash, deposit.TxHash.String())
+	assert.Equal(t, int64(10000000), parsed.StakingOutput.Value)
+
+	// the rest of the entry is not validated
+	fp.Description.Moniker = ""
+	_, _, err = registry.ParseDepositStakingTx(fp)
+	require.NoError(t, err)
+
+	fp.BtcPk = "aa"
+	_, _, err = registry.ParseDepositStakingTx(fp)
+	assert.Contains(t, err.Error(), "invalid btc_pk aa")
+}
@@ -0,0 +1,432 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// UpdatesDirName is the directory, relative to the finality providers
+// directory of a network, holding the update records of each registry entry
+// under a directory named after its nickname
+const UpdatesDirName = "updates"
+
+type RecordType string
+
+const (
+	// UpdateRecordType changes the description or the commission of an entry
+	UpdateRecordType RecordType = "update"
+	// TombstoneRecordType retires an entry, no record can follow it
+	TombstoneRecordType RecordType = "tombstone"
+)
+
+// UpdateRecord changes a registry entry once it is merged, as the registry
+// files themselves are write-once. Records are signed by the btc_pk of the
+// entry and applied in increasing sequence order, the registry file being
+// sequence 0. An update replaces the whole description if set, and the
+// commission if set. The BTC key and the deposit of an entry cannot change.
+type UpdateRecord struct {
+	Type        RecordType   `json:"type"`
+	BtcPk       string       `json:"btc_pk"`
+	Sequence    uint64       `json:"sequence"`
+	Description *Description `json:"description,omitempty"`
+	Commission  string       `json:"commission,omitempty"`
+}
+
+// RecordEntry is an update record file loaded from disk, keeping its raw
+// bytes for its signature
+type RecordEntry struct {
+	Nickname string
+	Path     string
+	Raw      []byte
+	Record   *UpdateRecord
+}
+
+// RejectedRecord is a record which is not applied to its entry
+type RejectedRecord struct {
+	Record *RecordEntry
+	Err    error
+}
+
+// ResolvedEntry is a registry entry with its update records applied
+type ResolvedEntry struct {
+	// Entry is the registry file as submitted
+	Entry *Entry
+	// State is the entry after its last valid update
+	State *FinalityProvider
+	// Sequence is the sequence of the last applied record, 0 if none
+	Sequence uint64
+	// Retired is true once a tombstone was applied
+	Retired bool
+	// History holds the applied records in sequence order
+	History []*RecordEntry
+	// Rejected holds the records which are not valid
+	Rejected []*RejectedRecord
+}
+
+// UpdateRecordPath returns the path of the update record of the entry with
+// the given nickname and sequence
+func UpdateRecordPath(fpDir, nickname string, sequence uint64) string {
+	return filepath.Join(fpDir, UpdatesDirName, nickname, strconv.FormatUint(sequence, 10)+entryFileExt)
+}
+
+// UpdateSignaturePath returns the path of the signature of an update record
+func UpdateSignaturePath(fpDir, nickname string, sequence uint64) string {
+	return filepath.Join(fpDir, UpdatesDirName, nickname, strconv.FormatUint(sequence, 10)+sigFileExt)
+}
+
+// Validate checks the fields of the record, independently of its entry
+func (r *UpdateRecord) Validate() error {
+	if _, err := parseBtcPkFromHex(r.BtcPk); err != nil {
+		return fmt.Errorf("invalid btc_pk %s: %w", r.BtcPk, err)
+	}
+	if r.Sequence == 0 {
+		return fmt.Errorf("sequence should be positive")
+	}
+
+	switch r.Type {
+	case UpdateRecordType:
+		if r.Description == nil && r.Commission == "" {
+			return fmt.Errorf("update record changes neither the description nor the commission")
+		}
+		if r.Description != nil {
+			if err := r.Description.Validate(); err != nil {
+				return fmt.Errorf("invalid description: %w", err)
+			}
+		}
+		if r.Commission != "" {
+			if _, err := ParseCommission(r.Commission); err != nil {
+				return fmt.Errorf("invalid commission: %w", err)
+			}
+		}
+	case TombstoneRecordType:
+		if r.Description != nil || r.Commission != "" {
+			return fmt.Errorf("tombstone record should not change the entry")
+		}
+	default:
+		return fmt.Errorf("unknown record type %q", r.Type)
+	}
+
+	return nil
+}
+
+// CanonicalBytes returns the canonical JSON encoding of the record, with
+// lower case hex and a normalised commission
+func (r *UpdateRecord) CanonicalBytes() ([]byte, error) {
+	canonical := *r
+	canonical.BtcPk = strings.ToLower(r.BtcPk)
+	if r.Commission != "" {
+		commission, err := ParseCommission(r.Commission)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commission: %w", err)
+		}
+		canonical.Commission = commission.String()
+	}
+
+	return CanonicalJSON(&canonical)
+}
+
+// Apply returns the entry state after the record, which should be valid
+func (r *UpdateRecord) Apply(fp *FinalityProvider) *FinalityProvider {
+	next := *fp
+	if r.Description != nil {
+		next.Description = *r.Description
+	}
+	if r.Commission != "" {
+		next.Commission = r.Commission
+	}
+	return &next
+}
+
+func NewUpdateRecordFromBytes(data []byte) (*UpdateRecord, error) {
+	var r UpdateRecord
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// NewRecordEntryFromFile loads an update record file keeping its raw bytes.
+// The nickname is the name of the directory of the file.
+func NewRecordEntryFromFile(filePath string) (*RecordEntry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewUpdateRecordFromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid update record %s: %w", filePath, err)
+	}
+
+	return &RecordEntry{
+		Nickname: filepath.Base(filepath.Dir(filePath)),
+		Path:     filePath,
+		Raw:      data,
+		Record:   r,
+	}, nil
+}
+
+// LoadUpdateRecords loads the update records of the entry with the given
+// nickname, sorted by sequence. File names should be their sequence.
+func LoadUpdateRecords(fpDir, nickname string) ([]*RecordEntry, error) {
+	files, err := filepath.Glob(filepath.Join(fpDir, UpdatesDirName, nickname, "*"+entryFileExt))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*RecordEntry, 0, len(files))
+	for _, f := range files {
+		record, err := NewRecordEntryFromFile(f)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(f), entryFileExt)
+		if name != strconv.FormatUint(record.Record.Sequence, 10) {
+			return nil, fmt.Errorf("update record %s should be named after its sequence %d", f, record.Record.Sequence)
+		}
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Record.Sequence < records[j].Record.Sequence
+	})
+
+	return records, nil
+}
+
+// SignedRecordData returns the data of the record signed under the scheme
+func SignedRecordData(scheme SignatureScheme, network string, r *RecordEntry) ([]byte, error) {
+	return signedData(scheme, network, r.Raw, r.Record.CanonicalBytes)
+}
+
+func (r *RecordEntry) name() string {
+	return fmt.Sprintf("'%s' %s %d", r.Nickname, r.Record.Type, r.Record.Sequence)
+}
+
+// VerifyRecordSignature checks that sig is a signature of the record by its
+// btc_pk under the signature scheme. Raw signatures are not bound to a
+// network, so they are not accepted for update records.
+func VerifyRecordSignature(r *RecordEntry, sig *EntrySignature, network string) error {
+	if err := checkRecordScheme(sig.Scheme); err != nil {
+		return fmt.Errorf("%s: %w", r.name(), err)
+	}
+	data, err := SignedRecordData(sig.Scheme, network, r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.name(), err)
+	}
+
+	return verifySignature(r.name(), r.Record.BtcPk, data, sig)
+}
+
+func checkRecordScheme(scheme SignatureScheme) error {
+	if scheme == RawScheme {
+		return fmt.Errorf("update records should be signed under the %s or %s scheme, %s signatures can be replayed on another network",
+			CanonicalV1Scheme, Bip322SimpleScheme, scheme)
+	}
+	return nil
+}
+
+// checkRecord checks that the record is valid and can follow the given state
+// of its entry
+func checkRecord(resolved *ResolvedEntry, r *RecordEntry) error {
+	if err := r.Record.Validate(); err != nil {
+		return err
+	}
+	if !strings.EqualFold(r.Record.BtcPk, resolved.State.BtcPk) {
+		return fmt.Errorf("btc_pk %s is not the btc_pk %s of the entry", r.Record.BtcPk, resolved.State.BtcPk)
+	}
+	if resolved.Retired {
+		return fmt.Errorf("the entry is retired")
+	}
+	if r.Record.Sequence <= resolved.Sequence {
+		return fmt.Errorf("sequence %d should be larger than %d", r.Record.Sequence, resolved.Sequence)
+	}
+	return nil
+}
+
+// verifyRecord checks the record against the state of its entry and its
+// signature
+func verifyRecord(fpDir, network string, resolved *ResolvedEntry, r *RecordEntry) error {
+	if err := checkRecord(resolved, r); err != nil {
+		return fmt.Errorf("%s: %w", r.name(), err)
+	}
+
+	sig, err := NewSignatureFromFile(UpdateSignaturePath(fpDir, r.Nickname, r.Record.Sequence))
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.name(), err)
+	}
+	return VerifyRecordSignature(r, sig, network)
+}
+
+// ResolveEntry applies the valid update records of the entry in sequence
+// order. Records which are not valid, not signed by the btc_pk of the entry,
+// not increasing or following a tombstone are rejected, the later records
+// still being applied.
+func ResolveEntry(fpDir string, e *Entry) (*ResolvedEntry, error) {
+	records, err := LoadUpdateRecords(fpDir, e.Nickname)
+	if err != nil {
+		return nil, err
+	}
+	// entries without records do not need the network
+	var network string
+	if len(records) > 0 {
+		if network, err = NetworkFromFpDir(fpDir); err != nil {
+			return nil, err
+		}
+	}
+
+	resolved := &ResolvedEntry{
+		Entry: e,
+		State: e.FinalityProvider,
+	}
+	for _, r := range records {
+		if err := verifyRecord(fpDir, network, resolved, r); err != nil {
+			resolved.Rejected = append(resolved.Rejected, &RejectedRecord{Record: r, Err: err})
+			continue
+		}
+
+		resolved.State = r.Record.Apply(resolved.State)
+		resolved.Sequence = r.Record.Sequence
+		resolved.Retired = r.Record.Type == TombstoneRecordType
+		resolved.History = append(resolved.History, r)
+	}
+
+	return resolved, nil
+}
+
+// LoadResolvedRegistry loads every entry of the registry with its update
+// records applied, sorted by nickname. Update records of unknown entries are
+// an error.
+func LoadResolvedRegistry(fpDir string) ([]*ResolvedEntry, error) {
+	entries, err := LoadRegistry(fpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(entries))
+	resolved := make([]*ResolvedEntry, 0, len(entries))
+	for _, e := range entries {
+		known[e.Nickname] = true
+		r, err := ResolveEntry(fpDir, e)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(fpDir, UpdatesDirName, "*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		if !known[filepath.Base(d)] {
+			return nil, fmt.Errorf("update records %s do not belong to a registry entry", d)
+		}
+	}
+
+	return resolved, nil
+}
+
+// LoadCurrentRegistry loads the entries of the registry which are not
+// retired, sorted by nickname, with the state of their last valid update as
+// their finality provider. Path and Raw are still those of the submitted
+// entry. This is what exports and snapshots publish.
+func LoadCurrentRegistry(fpDir string) ([]*Entry, error) {
+	resolved, err := LoadResolvedRegistry(fpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for _, r := range resolved {
+		if r.Retired {
+			continue
+		}
+		current := *r.Entry
+		current.FinalityProvider = r.State
+		entries = append(entries, &current)
+	}
+	return entries, nil
+}
+
+// MarshalUpdateRecord encodes the record as registry files are formatted
+func MarshalUpdateRecord(r *UpdateRecord) ([]byte, error) {
+	return marshalIndented(r)
+}
+
+// WriteSignedUpdateRecord writes the record of the entry with the given
+// nickname, signs it under the scheme, writes its signature, and checks that
+// the record is applied once read back from disk. Both files are removed if
+// the record is rejected.
+func WriteSignedUpdateRecord(
+	fpDir, nickname string,
+	r *UpdateRecord,
+	scheme SignatureScheme,
+	signer Signer,
+) (*ResolvedEntry, error) {
+	if scheme == Bip322SimpleScheme {
+		return nil, fmt.Errorf("%s signatures are made by a wallet, not by a signer", scheme)
+	}
+	if err := checkRecordScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	network, err := schemeNetwork(scheme, fpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := MarshalUpdateRecord(r)
+	if err != nil {
+		return nil, err
+	}
+	recordPath := UpdateRecordPath(fpDir, nickname, r.Sequence)
+	signedData, err := SignedRecordData(scheme, network, &RecordEntry{
+		Nickname: nickname,
+		Path:     recordPath,
+		Raw:      data,
+		Record:   r,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(signedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign '%s' %s %d: %w", nickname, r.Type, r.Sequence, err)
+	}
+
+	if err := writeFile(recordPath, data); err != nil {
+		return nil, err
+	}
+	sigPath := UpdateSignaturePath(fpDir, nickname, r.Sequence)
+	if err := writeFile(sigPath, FormatSignature(&EntrySignature{Scheme: scheme, Sig: sig})); err != nil {
+		return nil, err
+	}
+
+	entry, err := NewEntryFromFile(filepath.Join(fpDir, RegistryDirName, nickname+entryFileExt))
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := ResolveEntry(fpDir, entry)
+	if err != nil {
+		return nil, err
+	}
+	for _, rejected := range resolved.Rejected {
+		if rejected.Record.Record.Sequence == r.Sequence {
+			// an invalid record would only be rejected by later loads
+			_ = os.Remove(recordPath)
+			_ = os.Remove(sigPath)
+			return nil, fmt.Errorf("written record is not valid: %w", rejected.Err)
+		}
+	}
+
+	return resolved, nil
+}
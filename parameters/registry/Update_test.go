@@ -0,0 +1,285 @@
+package registry_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/snapshot"
+)
+
+func TestResolveEntryUpdates(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	signer := registry.NewKeySigner(privKey)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+	fpDir := newTestFpDir(t)
+	entry, err := registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.RawScheme, signer)
+	require.NoError(t, err)
+
+	// no update
+	resolved, err := registry.ResolveEntry(fpDir, entry)
+	require.NoError(t, err)
+	assert.Equal(t, fp, resolved.State)
+	assert.Equal(t, uint64(0), resolved.Sequence)
+	assert.False(t, resolved.Retired)
+	assert.Empty(t, resolved.History)
+
+	newDescription := fp.Description
+	newDescription.Website = "https://new.my-fp.com"
+	records := []*registry.UpdateRecord{
+		{Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 1, Commission: "0.1"},
+		// sequences do not need to be contiguous
+		{Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 5, Description: &newDescription},
+	}
+	for _, r := range records {
+		_, err := registry.WriteSignedUpdateRecord(fpDir, "my_fp", r, registry.CanonicalV1Scheme, signer)
+		require.NoError(t, err)
+	}
+
+	resolved, err = registry.ResolveEntry(fpDir, entry)
+	require.NoError(t, err)
+	assert.Empty(t, resolved.Rejected)
+	assert.Equal(t, uint64(5), resolved.Sequence)
+	assert.Equal(t, "0.1", resolved.State.Commission)
+	assert.Equal(t, newDescription, resolved.State.Description)
+	assert.Equal(t, fp.Deposit, resolved.State.Deposit)
+	// the submitted entry is kept
+	assert.Equal(t, "0.05", resolved.Entry.FinalityProvider.Commission)
+	require.Len(t, resolved.History, 2)
+	assert.Equal(t, uint64(1), resolved.History[0].Record.Sequence)
+	assert.Equal(t, registry.UpdateRecordPath(fpDir, "my_fp", 5), resolved.History[1].Path)
+
+	tombstone := &registry.UpdateRecord{Type: registry.TombstoneRecordType, BtcPk: fp.BtcPk, Sequence: 6}
+	resolved, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", tombstone, registry.CanonicalV1Scheme, signer)
+	require.NoError(t, err)
+	assert.True(t, resolved.Retired)
+	assert.Equal(t, uint64(6), resolved.Sequence)
+	assert.Len(t, resolved.History, 3)
+
+	// nothing can follow a tombstone
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 7, Commission: "0.2",
+	}, registry.CanonicalV1Scheme, signer)
+	assert.Equal(t, "written record is not valid: 'my_fp' update 7: the entry is retired", err.Error())
+	// the rejected record is not kept
+	_, err = os.Stat(registry.UpdateRecordPath(fpDir, "my_fp", 7))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(registry.UpdateSignaturePath(fpDir, "my_fp", 7))
+	assert.True(t, os.IsNotExist(err))
+
+	resolved, err = registry.ResolveEntry(fpDir, entry)
+	require.NoError(t, err)
+	assert.True(t, resolved.Retired)
+	assert.Equal(t, "0.1", resolved.State.Commission)
+	assert.Empty(t, resolved.Rejected)
+}
+
+func TestRejectedUpdateRecords(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	signer := registry.NewKeySigner(privKey)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+	fpDir := newTestFpDir(t)
+	entry, err := registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.RawScheme, signer)
+	require.NoError(t, err)
+
+	other, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	otherFp := newSignedTestEntry(t, other.PubKey())
+
+	// signed by another key for the key of the entry
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 1, Commission: "0.5",
+	}, registry.CanonicalV1Scheme, registry.NewKeySigner(other))
+	assert.Equal(t, "written record is not valid: canonical-v1 signature of 'my_fp' update 1 is not valid for btc_pk "+fp.BtcPk, err.Error())
+
+	// signed by another key, for this other key
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: otherFp.BtcPk, Sequence: 2, Commission: "0.5",
+	}, registry.CanonicalV1Scheme, registry.NewKeySigner(other))
+	assert.Equal(t, "written record is not valid: 'my_fp' update 2: btc_pk "+otherFp.BtcPk+
+		" is not the btc_pk "+fp.BtcPk+" of the entry", err.Error())
+
+	// raw signatures are not bound to a network
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 3, Commission: "0.07",
+	}, registry.RawScheme, signer)
+	assert.Equal(t, "update records should be signed under the canonical-v1 or bip322-simple scheme, "+
+		"raw signatures can be replayed on another network", err.Error())
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 3, Commission: "0.07",
+	}, registry.CanonicalV1Scheme, signer)
+	require.NoError(t, err)
+
+	// a record cannot be replayed on another network
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 4, Commission: "0.08",
+	}, registry.CanonicalV1Scheme, signer)
+	require.NoError(t, err)
+	record, err := registry.NewRecordEntryFromFile(registry.UpdateRecordPath(fpDir, "my_fp", 4))
+	require.NoError(t, err)
+	sig, err := registry.NewSignatureFromFile(registry.UpdateSignaturePath(fpDir, "my_fp", 4))
+	require.NoError(t, err)
+	require.NoError(t, registry.VerifyRecordSignature(record, sig, "bbn-test-4"))
+	assert.Error(t, registry.VerifyRecordSignature(record, sig, "bbn-test-5"))
+
+	// a raw signature of a record on disk
+	require.NoError(t, os.WriteFile(registry.UpdateSignaturePath(fpDir, "my_fp", 4),
+		registry.FormatSignature(&registry.EntrySignature{Scheme: registry.RawScheme, Sig: sig.Sig}), 0o644))
+
+	// rejected records do not prevent the later ones
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "my_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 5, Commission: "0.09",
+	}, registry.CanonicalV1Scheme, signer)
+	require.NoError(t, err)
+
+	resolved, err := registry.ResolveEntry(fpDir, entry)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), resolved.Sequence)
+	assert.Equal(t, "0.09", resolved.State.Commission)
+	require.Len(t, resolved.History, 2)
+	require.Len(t, resolved.Rejected, 1)
+	assert.Equal(t, "'my_fp' update 4: update records should be signed under the canonical-v1 or bip322-simple scheme, "+
+		"raw signatures can be replayed on another network", resolved.Rejected[0].Err.Error())
+}
+
+func TestFailUpdateRecordValidation(t *testing.T) {
+	btcPk := "a89e7caf57360bc8b791df72abc3fb6d2ddc0e06e171c9f17c4ea1299e677565"
+	invalidDescription := registry.Description{Moniker: "m"}
+
+	tests := []struct {
+		record *registry.UpdateRecord
+		err    string
+	}{
+		{
+			&registry.UpdateRecord{Type: registry.UpdateRecordType, BtcPk: "zz", Sequence: 1, Commission: "0.1"},
+			"invalid btc_pk zz: encoding/hex: invalid byte: U+007A 'z'",
+		},
+		{
+			&registry.UpdateRecord{Type: registry.UpdateRecordType, BtcPk: btcPk, Commission: "0.1"},
+			"sequence should be positive",
+		},
+		{
+			&registry.UpdateRecord{Type: registry.UpdateRecordType, BtcPk: btcPk, Sequence: 1},
+			"update record changes neither the description nor the commission",
+		},
+		{
+			&registry.UpdateRecord{Type: registry.UpdateRecordType, BtcPk: btcPk, Sequence: 1, Commission: "2"},
+			"invalid commission: commission 2 is larger than 1",
+		},
+		{
+			&registry.UpdateRecord{Type: registry.UpdateRecordType, BtcPk: btcPk, Sequence: 1, Description: &invalidDescription},
+			"invalid description: moniker has less than 3 characters, got 1",
+		},
+		{
+			&registry.UpdateRecord{Type: registry.TombstoneRecordType, BtcPk: btcPk, Sequence: 1, Commission: "0.1"},
+			"tombstone record should not change the entry",
+		},
+		{
+			&registry.UpdateRecord{Type: "delete", BtcPk: btcPk, Sequence: 1},
+			`unknown record type "delete"`,
+		},
+	}
+
+	for _, tc := range tests {
+		err := tc.record.Validate()
+		require.Error(t, err, tc.err)
+		assert.Equal(t, tc.err, err.Error())
+	}
+}
+
+func TestLoadResolvedRegistry(t *testing.T) {
+	resolved, err := registry.LoadResolvedRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+	require.Len(t, resolved, len(entries))
+	for i, r := range resolved {
+		assert.Equal(t, entries[i], r.Entry)
+		assert.Equal(t, entries[i].FinalityProvider, r.State)
+	}
+
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	signer := registry.NewKeySigner(privKey)
+	fp := newSignedTestEntry(t, privKey.PubKey())
+	fpDir := newTestFpDir(t)
+	_, err = registry.WriteSignedEntry(fpDir, "my_fp", fp, registry.RawScheme, signer)
+	require.NoError(t, err)
+
+	// record file names should be their sequence
+	record := &registry.UpdateRecord{Type: registry.UpdateRecordType, BtcPk: fp.BtcPk, Sequence: 1, Commission: "0.1"}
+	data, err := registry.MarshalUpdateRecord(record)
+	require.NoError(t, err)
+	misnamed := registry.UpdateRecordPath(fpDir, "my_fp", 2)
+	require.NoError(t, os.MkdirAll(filepath.Dir(misnamed), 0o755))
+	require.NoError(t, os.WriteFile(misnamed, data, 0o644))
+	_, err = registry.LoadResolvedRegistry(fpDir)
+	assert.Equal(t, "update record "+misnamed+" should be named after its sequence 1", err.Error())
+	require.NoError(t, os.Remove(misnamed))
+
+	// records of an unknown entry
+	orphan := registry.UpdateRecordPath(fpDir, "unknown", 1)
+	require.NoError(t, os.MkdirAll(filepath.Dir(orphan), 0o755))
+	require.NoError(t, os.WriteFile(orphan, data, 0o644))
+	_, err = registry.LoadResolvedRegistry(fpDir)
+	assert.Equal(t, "update records "+filepath.Dir(orphan)+" do not belong to a registry entry", err.Error())
+}
+
+func TestLoadCurrentRegistry(t *testing.T) {
+	fpDir := newTestFpDir(t)
+	fps := make(map[string]*registry.FinalityProvider)
+	signers := make(map[string]registry.Signer)
+	for _, nickname := range []string{"retired_fp", "updated_fp"} {
+		privKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+		signers[nickname] = registry.NewKeySigner(privKey)
+		fps[nickname] = newSignedTestEntry(t, privKey.PubKey())
+		_, err = registry.WriteSignedEntry(fpDir, nickname, fps[nickname], registry.RawScheme, signers[nickname])
+		require.NoError(t, err)
+	}
+	_, err := registry.WriteSignedUpdateRecord(fpDir, "updated_fp", &registry.UpdateRecord{
+		Type: registry.UpdateRecordType, BtcPk: fps["updated_fp"].BtcPk, Sequence: 1, Commission: "0.1",
+	}, registry.CanonicalV1Scheme, signers["updated_fp"])
+	require.NoError(t, err)
+	_, err = registry.WriteSignedUpdateRecord(fpDir, "retired_fp", &registry.UpdateRecord{
+		Type: registry.TombstoneRecordType, BtcPk: fps["retired_fp"].BtcPk, Sequence: 1,
+	}, registry.CanonicalV1Scheme, signers["retired_fp"])
+	require.NoError(t, err)
+
+	entries, err := registry.LoadCurrentRegistry(fpDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "updated_fp", entries[0].Nickname)
+	assert.Equal(t, "0.1", entries[0].FinalityProvider.Commission)
+
+	// the tombstone drops the entry from the export
+	var export bytes.Buffer
+	require.NoError(t, registry.ExportJSON(&export, entries, registry.ExportOptions{}))
+	assert.Contains(t, export.String(), fps["updated_fp"].BtcPk)
+	assert.NotContains(t, export.String(), fps["retired_fp"].BtcPk)
+	assert.Contains(t, export.String(), `"commission": "0.100000000000000000"`)
+
+	// and from the snapshot, which commits to the updated state
+	s, err := snapshot.NewSnapshot(entries)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), s.Info().LeafCount)
+	retiredPk, err := hex.DecodeString(fps["retired_fp"].BtcPk)
+	require.NoError(t, err)
+	_, err = s.Prove(retiredPk)
+	assert.Error(t, err)
+	updatedPk, err := hex.DecodeString(fps["updated_fp"].BtcPk)
+	require.NoError(t, err)
+	proof, err := s.Prove(updatedPk)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.VerifyProof(s.Root(), entries[0].FinalityProvider, proof))
+	assert.Error(t, snapshot.VerifyProof(s.Root(), fps["updated_fp"], proof))
+}
@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+// Proof is the inclusion proof of a single registry entry. Siblings are the
+// hashes needed to recompute the root from the leaf, bottom up. Levels on
+// which the node has no sibling, and is promoted as is, are skipped.
+type Proof struct {
+	BtcPk     string   `json:"btc_pk"`
+	LeafIndex uint64   `json:"leaf_index"`
+	LeafCount uint64   `json:"leaf_count"`
+	Siblings  []string `json:"siblings"`
+}
+
+// RootFromProof recomputes the root committing to the given entry
+func RootFromProof(fp *registry.FinalityProvider, proof *Proof) ([]byte, error) {
+	if proof.LeafCount == 0 || proof.LeafIndex >= proof.LeafCount {
+		return nil, fmt.Errorf("leaf index %d is out of range of %d leaves", proof.LeafIndex, proof.LeafCount)
+	}
+	if !strings.EqualFold(proof.BtcPk, fp.BtcPk) {
+		return nil, fmt.Errorf("proof is for btc_pk %s, but the entry has btc_pk %s", proof.BtcPk, fp.BtcPk)
+	}
+
+	leaf, err := NewLeaf("", fp)
+	if err != nil {
+		return nil, err
+	}
+
+	node := leaf.Hash
+	pos, count := proof.LeafIndex, proof.LeafCount
+	siblings := proof.Siblings
+	for count > 1 {
+		// the last node of a level with an odd number of nodes is promoted
+		if pos != count-1 || count%2 == 0 {
+			if len(siblings) == 0 {
+				return nil, fmt.Errorf("proof has too few siblings")
+			}
+			sibling, err := hex.DecodeString(siblings[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid sibling %s: %w", siblings[0], err)
+			}
+			siblings = siblings[1:]
+
+			if pos%2 == 0 {
+				node = innerHash(node, sibling)
+			} else {
+				node = innerHash(sibling, node)
+			}
+		}
+		pos /= 2
+		count = (count + 1) / 2
+	}
+
+	if len(siblings) != 0 {
+		return nil, fmt.Errorf("proof has %d unused siblings", len(siblings))
+	}
+
+	return node, nil
+}
+
+// VerifyProof checks that the given registry entry, e.g. the metadata of
+// a provider displayed by the staking web app, is committed by a known root
+func VerifyProof(root []byte, fp *registry.FinalityProvider, proof *Proof) error {
+	computed, err := RootFromProof(fp, proof)
+	if err != nil {
+		return fmt.Errorf("invalid proof: %w", err)
+	}
+
+	if !bytes.Equal(computed, root) {
+		return fmt.Errorf("entry of btc_pk %s is not committed by root %x", fp.BtcPk, root)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package snapshot_test
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/snapshot"
+)
+
+func TestFailProofVerification(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	entries := genRandomEntries(t, r, 7)
+	s, err := snapshot.NewSnapshot(entries)
+	require.NoError(t, err)
+
+	fp := entries[3].FinalityProvider
+	btcPk, err := hex.DecodeString(fp.BtcPk)
+	require.NoError(t, err)
+	validProof, err := s.Prove(btcPk)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.VerifyProof(s.Root(), fp, validProof))
+
+	// tampered metadata
+	tampered := *fp
+	tampered.Description.Moniker = "someone else"
+	err = snapshot.VerifyProof(s.Root(), &tampered, validProof)
+	assert.Contains(t, err.Error(), "is not committed by root")
+
+	// proof of another provider
+	otherPk, err := hex.DecodeString(entries[4].FinalityProvider.BtcPk)
+	require.NoError(t, err)
+	otherProof, err := s.Prove(otherPk)
+	require.NoError(t, err)
+	err = snapshot.VerifyProof(s.Root(), fp, otherProof)
+	assert.Contains(t, err.Error(), "invalid proof: proof is for btc_pk")
+
+	// wrong position
+	proof := *validProof
+	proof.LeafIndex = (proof.LeafIndex + 1) % proof.LeafCount
+	assert.Error(t, snapshot.VerifyProof(s.Root(), fp, &proof))
+
+	proof = *validProof
+	proof.LeafIndex = proof.LeafCount
+	err = snapshot.VerifyProof(s.Root(), fp, &proof)
+	assert.Equal(t, "invalid proof: leaf index 7 is out of range of 7 leaves", err.Error())
+
+	// missing and extra siblings
+	proof = *validProof
+	proof.Siblings = validProof.Siblings[1:]
+	err = snapshot.VerifyProof(s.Root(), fp, &proof)
+	assert.Equal(t, "invalid proof: proof has too few siblings", err.Error())
+
+	proof = *validProof
+	proof.Siblings = append(append([]string{}, validProof.Siblings...), validProof.Siblings[0])
+	err = snapshot.VerifyProof(s.Root(), fp, &proof)
+	assert.Equal(t, "invalid proof: proof has 1 unused siblings", err.Error())
+
+	// unknown root
+	err = snapshot.VerifyProof(make([]byte, 32), fp, validProof)
+	assert.Contains(t, err.Error(), "is not committed by root")
+}
+
+func TestSingleLeafSnapshot(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	entries := genRandomEntries(t, r, 1)
+	s, err := snapshot.NewSnapshot(entries)
+	require.NoError(t, err)
+
+	btcPk, err := hex.DecodeString(entries[0].FinalityProvider.BtcPk)
+	require.NoError(t, err)
+	proof, err := s.Prove(btcPk)
+	require.NoError(t, err)
+	assert.Empty(t, proof.Siblings)
+	assert.Equal(t, s.Leaves[0].Hash, s.Root())
+	require.NoError(t, snapshot.VerifyProof(s.Root(), entries[0].FinalityProvider, proof))
+}
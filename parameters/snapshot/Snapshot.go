@@ -0,0 +1,220 @@
+// Package snapshot commits to every entry of the finality provider registry
+// with a Merkle tree keyed by the finality provider BTC public key, so that
+// clients can verify the metadata of a single provider against a known root.
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+
+	"github.com/babylonchain/networks/parameters/registry"
+)
+
+const (
+	// SnapshotFileName is the file, relative to the finality providers
+	// directory of a network, where the snapshot of the registry is published
+	SnapshotFileName = "snapshot.json"
+
+	// domain separation of the leaf and inner node hashes, so that an inner
+	// node can never be presented as a leaf
+	leafPrefix  = byte(0x00)
+	innerPrefix = byte(0x01)
+)
+
+// Leaf is a committed registry entry
+type Leaf struct {
+	BtcPk    []byte
+	Nickname string
+	// Entry is the canonical JSON encoding of the registry entry
+	Entry []byte
+	Hash  []byte
+}
+
+// Snapshot is the Merkle tree over all registry entries, with leaves sorted
+// by BTC public key. Levels[0] are the leaf hashes and the last level holds
+// the root only.
+type Snapshot struct {
+	Leaves []*Leaf
+	Levels [][][]byte
+}
+
+// LeafInfo and Info are the published form of a snapshot
+type LeafInfo struct {
+	BtcPk    string `json:"btc_pk"`
+	Nickname string `json:"nickname"`
+	LeafHash string `json:"leaf_hash"`
+}
+
+type Info struct {
+	Root      string      `json:"root"`
+	LeafCount uint64      `json:"leaf_count"`
+	Leaves    []*LeafInfo `json:"leaves"`
+}
+
+// LeafHash computes sha256(0x00 || btc_pk || canonical_entry)
+func LeafHash(btcPk []byte, canonicalEntry []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(btcPk)
+	h.Write(canonicalEntry)
+	return h.Sum(nil)
+}
+
+// innerHash computes sha256(0x01 || left || right)
+func innerHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{innerPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// nextLevel hashes pairs of nodes together. A node without a sibling is
+// promoted to the next level as is, instead of being hashed with itself,
+// so that two different sets of leaves can never share the same root.
+func nextLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, innerHash(level[i], level[i+1]))
+	}
+	return next
+}
+
+// NewLeaf puts the registry entry into canonical form and computes its leaf
+func NewLeaf(nickname string, fp *registry.FinalityProvider) (*Leaf, error) {
+	btcPkBytes, err := hex.DecodeString(fp.BtcPk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid btc_pk of %s: %w", nickname, err)
+	}
+	// the key is validated, but committed with its 32 bytes x-only encoding
+	if _, err := schnorr.ParsePubKey(btcPkBytes); err != nil {
+		return nil, fmt.Errorf("invalid btc_pk of %s: %w", nickname, err)
+	}
+
+	canonical, err := fp.CanonicalBytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry %s: %w", nickname, err)
+	}
+
+	return &Leaf{
+		BtcPk:    btcPkBytes,
+		Nickname: nickname,
+		Entry:    canonical,
+		Hash:     LeafHash(btcPkBytes, canonical),
+	}, nil
+}
+
+// NewSnapshot builds the Merkle tree of the given registry entries. Every BTC
+// public key should be registered once.
+func NewSnapshot(entries []*registry.Entry) (*Snapshot, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("cannot build a snapshot of an empty registry")
+	}
+
+	leaves := make([]*Leaf, 0, len(entries))
+	for _, e := range entries {
+		leaf, err := NewLeaf(e.Nickname, e.FinalityProvider)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].BtcPk, leaves[j].BtcPk) < 0
+	})
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		if i > 0 && bytes.Equal(leaves[i-1].BtcPk, leaf.BtcPk) {
+			return nil, fmt.Errorf("btc_pk %x is registered by both %s and %s",
+				leaf.BtcPk, leaves[i-1].Nickname, leaf.Nickname)
+		}
+		level[i] = leaf.Hash
+	}
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		level = nextLevel(level)
+		levels = append(levels, level)
+	}
+
+	return &Snapshot{
+		Leaves: leaves,
+		Levels: levels,
+	}, nil
+}
+
+func (s *Snapshot) Root() []byte {
+	return s.Levels[len(s.Levels)-1][0]
+}
+
+// Info returns the published form of the snapshot
+func (s *Snapshot) Info() *Info {
+	info := &Info{
+		Root:      hex.EncodeToString(s.Root()),
+		LeafCount: uint64(len(s.Leaves)),
+		Leaves:    make([]*LeafInfo, len(s.Leaves)),
+	}
+	for i, leaf := range s.Leaves {
+		info.Leaves[i] = &LeafInfo{
+			BtcPk:    hex.EncodeToString(leaf.BtcPk),
+			Nickname: leaf.Nickname,
+			LeafHash: hex.EncodeToString(leaf.Hash),
+		}
+	}
+	return info
+}
+
+// Prove returns the inclusion proof of the finality provider with the given
+// BTC public key
+func (s *Snapshot) Prove(btcPk []byte) (*Proof, error) {
+	idx := sort.Search(len(s.Leaves), func(i int) bool {
+		return bytes.Compare(s.Leaves[i].BtcPk, btcPk) >= 0
+	})
+	if idx == len(s.Leaves) || !bytes.Equal(s.Leaves[idx].BtcPk, btcPk) {
+		return nil, fmt.Errorf("btc_pk %x is not part of the snapshot", btcPk)
+	}
+
+	proof := &Proof{
+		BtcPk:     hex.EncodeToString(btcPk),
+		LeafIndex: uint64(idx),
+		LeafCount: uint64(len(s.Leaves)),
+	}
+
+	pos := idx
+	for _, level := range s.Levels[:len(s.Levels)-1] {
+		sibling := pos ^ 1
+		if sibling < len(level) {
+			proof.Siblings = append(proof.Siblings, hex.EncodeToString(level[sibling]))
+		}
+		pos /= 2
+	}
+
+	return proof, nil
+}
+
+func NewInfoFromFile(filePath string) (*Info, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
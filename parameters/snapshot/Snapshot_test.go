@@ -0,0 +1,133 @@
+package snapshot_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/registry"
+	"github.com/babylonchain/networks/parameters/snapshot"
+)
+
+const bbnTest4FpDir = "../../bbn-test-4/finality-providers"
+
+func addRandomSeedsToFuzzer(f *testing.F, num uint) {
+	// Seed based on the current time
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	var idx uint
+	for idx = 0; idx < num; idx++ {
+		f.Add(r.Int63())
+	}
+}
+
+func genRandomEntries(t *testing.T, r *rand.Rand, num int) []*registry.Entry {
+	entries := make([]*registry.Entry, 0, num)
+	for i := 0; i < num; i++ {
+		privKey, err := btcec.NewPrivateKey()
+		require.NoError(t, err)
+
+		entries = append(entries, &registry.Entry{
+			Nickname: fmt.Sprintf("fp-%d", i),
+			FinalityProvider: &registry.FinalityProvider{
+				Description: registry.Description{
+					Moniker:         fmt.Sprintf("finality provider %d", r.Int63()),
+					SecurityContact: "security@fp.io",
+				},
+				BtcPk:      hex.EncodeToString(schnorr.SerializePubKey(privKey.PubKey())),
+				Commission: fmt.Sprintf("0.%02d", r.Intn(100)),
+			},
+		})
+	}
+	return entries
+}
+
+// PROPERTY: The proof of every leaf verifies against the root of the snapshot
+func FuzzProveEveryLeaf(f *testing.F) {
+	addRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		entries := genRandomEntries(t, r, r.Intn(40)+1)
+
+		s, err := snapshot.NewSnapshot(entries)
+		require.NoError(t, err)
+		require.Len(t, s.Leaves, len(entries))
+
+		for _, e := range entries {
+			btcPk, err := hex.DecodeString(e.FinalityProvider.BtcPk)
+			require.NoError(t, err)
+
+			proof, err := s.Prove(btcPk)
+			require.NoError(t, err)
+			require.NoError(t, snapshot.VerifyProof(s.Root(), e.FinalityProvider, proof))
+		}
+	})
+}
+
+func TestSnapshotIsIndependentOfOrderAndFormatting(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	entries := genRandomEntries(t, r, 10)
+
+	s1, err := snapshot.NewSnapshot(entries)
+	require.NoError(t, err)
+
+	// reverse the entries and change the formatting of the commission
+	reversed := make([]*registry.Entry, len(entries))
+	for i, e := range entries {
+		fp := *e.FinalityProvider
+		fp.Commission += "000"
+		reversed[len(entries)-1-i] = &registry.Entry{Nickname: e.Nickname, FinalityProvider: &fp}
+	}
+	s2, err := snapshot.NewSnapshot(reversed)
+	require.NoError(t, err)
+	assert.Equal(t, s1.Root(), s2.Root())
+
+	// any change of the content changes the root
+	reversed[0].FinalityProvider.Description.Website = "https://fp.io"
+	s3, err := snapshot.NewSnapshot(reversed)
+	require.NoError(t, err)
+	assert.NotEqual(t, s1.Root(), s3.Root())
+}
+
+func TestFailSnapshot(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+
+	_, err := snapshot.NewSnapshot(nil)
+	assert.Equal(t, "cannot build a snapshot of an empty registry", err.Error())
+
+	entries := genRandomEntries(t, r, 3)
+	entries[2].FinalityProvider.BtcPk = entries[0].FinalityProvider.BtcPk
+	_, err = snapshot.NewSnapshot(entries)
+	assert.Equal(t, fmt.Sprintf("btc_pk %s is registered by both fp-0 and fp-2", entries[0].FinalityProvider.BtcPk), err.Error())
+
+	entries = genRandomEntries(t, r, 3)
+	entries[1].FinalityProvider.BtcPk = "02" + entries[1].FinalityProvider.BtcPk
+	_, err = snapshot.NewSnapshot(entries)
+	assert.Contains(t, err.Error(), "invalid btc_pk of fp-1")
+
+	entries = genRandomEntries(t, r, 3)
+	s, err := snapshot.NewSnapshot(entries)
+	require.NoError(t, err)
+	_, err = s.Prove(make([]byte, 32))
+	assert.Equal(t, fmt.Sprintf("btc_pk %x is not part of the snapshot", make([]byte, 32)), err.Error())
+}
+
+func TestBbnTest4Snapshot(t *testing.T) {
+	// the published snapshot should commit to the current registry
+	entries, err := registry.LoadRegistry(bbnTest4FpDir)
+	require.NoError(t, err)
+
+	s, err := snapshot.NewSnapshot(entries)
+	require.NoError(t, err)
+
+	published, err := snapshot.NewInfoFromFile(filepath.Join(bbnTest4FpDir, snapshot.SnapshotFileName))
+	require.NoError(t, err)
+	require.Equal(t, published, s.Info())
+}
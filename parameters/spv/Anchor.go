@@ -0,0 +1,54 @@
+package spv
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Anchor is a trusted block of a Bitcoin network, pinned in the repository.
+// The headers of a proof have to build on it, which ties them to the chain
+// of the network and gives them their height. Bits are the compact target
+// of the block in hex, as in its header.
+type Anchor struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+	Bits   string `json:"bits"`
+}
+
+type ParsedAnchor struct {
+	Height uint64
+	Hash   chainhash.Hash
+	Bits   uint32
+}
+
+func ParseAnchor(a *Anchor, params *chaincfg.Params) (*ParsedAnchor, error) {
+	hash, err := chainhash.NewHashFromStr(a.Hash)
+	if err != nil || len(a.Hash) != hex.EncodedLen(chainhash.HashSize) {
+		return nil, fmt.Errorf("invalid anchor hash %q", a.Hash)
+	}
+
+	bits, err := strconv.ParseUint(a.Bits, 16, 32)
+	if err != nil || len(a.Bits) != 8 {
+		return nil, fmt.Errorf("invalid anchor bits %q, should be 4 bytes in hex", a.Bits)
+	}
+	target := blockchain.CompactToBig(uint32(bits))
+	if target.Sign() <= 0 || target.Cmp(params.PowLimit) > 0 {
+		return nil, fmt.Errorf("anchor bits %s are not a valid %s target", a.Bits, params.Name)
+	}
+
+	return &ParsedAnchor{Height: a.Height, Hash: *hash, Bits: uint32(bits)}, nil
+}
+
+// GenesisAnchor returns the genesis block of the network as an anchor
+func GenesisAnchor(params *chaincfg.Params) *ParsedAnchor {
+	return &ParsedAnchor{
+		Height: 0,
+		Hash:   *params.GenesisHash,
+		Bits:   params.GenesisBlock.Header.Bits,
+	}
+}
@@ -0,0 +1,329 @@
+// Package spv verifies that a transaction is confirmed on Bitcoin from a chain
+// of block headers and a merkle branch, without trusting a block explorer.
+package spv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Proof is the SPV proof of a confirmed transaction. Headers start with the
+// header following the trusted anchor of the network, and go up to the last
+// confirmation of the transaction. The block including the transaction is
+// the header whose merkle root the merkle branch leads to.
+// The merkle branch follows the format of the Esplora merkle-proof endpoint:
+// hashes are hex encoded in their displayed (reversed) byte order, from the
+// leaf level up, and Pos is the index of the transaction in its block.
+type Proof struct {
+	Headers      []string `json:"headers"`
+	MerkleBranch []string `json:"merkle"`
+	Pos          uint32   `json:"pos"`
+}
+
+// VerifiedProof is the result of a successful verification
+type VerifiedProof struct {
+	BlockHash chainhash.Hash
+	// BlockHeight is the height of the block, counted from the anchor
+	BlockHeight uint64
+	// Confirmations is the depth of the transaction, the block including it
+	// counts as the first confirmation
+	Confirmations uint64
+	// Work is the total work of the confirmations
+	Work *big.Int
+}
+
+// NetParamsByName returns the parameters of the networks supported for SPV
+// verification
+func NetParamsByName(name string) (*chaincfg.Params, error) {
+	switch name {
+	case chaincfg.MainNetParams.Name:
+		return &chaincfg.MainNetParams, nil
+	case chaincfg.SigNetParams.Name:
+		return &chaincfg.SigNetParams, nil
+	case chaincfg.TestNet3Params.Name:
+		return &chaincfg.TestNet3Params, nil
+	case chaincfg.RegressionNetParams.Name:
+		return &chaincfg.RegressionNetParams, nil
+	default:
+		return nil, fmt.Errorf("unsupported network %q", name)
+	}
+}
+
+func parseHeaders(headersHex []string) ([]*wire.BlockHeader, error) {
+	headers := make([]*wire.BlockHeader, len(headersHex))
+	for i, h := range headersHex {
+		headerBytes, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %d: %w", i, err)
+		}
+		if len(headerBytes) != wire.MaxBlockHeaderPayload {
+			return nil, fmt.Errorf("invalid header %d: expected %d bytes, got %d", i, wire.MaxBlockHeaderPayload, len(headerBytes))
+		}
+
+		var header wire.BlockHeader
+		if err := header.Deserialize(bytes.NewReader(headerBytes)); err != nil {
+			return nil, fmt.Errorf("invalid header %d: %w", i, err)
+		}
+		headers[i] = &header
+	}
+	return headers, nil
+}
+
+// checkProofOfWork checks that the header hash is below the target of the
+// header, and that the target is not easier than the network allows
+func checkProofOfWork(header *wire.BlockHeader, params *chaincfg.Params) error {
+	target := blockchain.CompactToBig(header.Bits)
+	if target.Sign() <= 0 {
+		return fmt.Errorf("target %064x is not positive", target)
+	}
+	if target.Cmp(params.PowLimit) > 0 {
+		return fmt.Errorf("target %064x is higher than the %s limit %064x", target, params.Name, params.PowLimit)
+	}
+
+	hash := header.BlockHash()
+	if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+		return fmt.Errorf("hash %s is higher than target %064x", hash, target)
+	}
+	return nil
+}
+
+func isRetargetHeight(height uint64, params *chaincfg.Params) bool {
+	blocksPerRetarget := uint64(params.TargetTimespan / params.TargetTimePerBlock)
+	return !params.PoWNoRetargeting && height%blocksPerRetarget == 0
+}
+
+// checkDifficultyTransition checks the difficulty rules which can be verified
+// from headers only: the target is constant within a retarget period, and
+// changes by at most a factor 4 on a retarget boundary
+func checkDifficultyTransition(prevBits uint32, header *wire.BlockHeader, height uint64, params *chaincfg.Params) error {
+	if !isRetargetHeight(height, params) {
+		if header.Bits != prevBits {
+			return fmt.Errorf("bits %08x changed from %08x outside of a retarget boundary", header.Bits, prevBits)
+		}
+		return nil
+	}
+
+	prevTarget := blockchain.CompactToBig(prevBits)
+	target := blockchain.CompactToBig(header.Bits)
+	factor := big.NewInt(params.RetargetAdjustmentFactor)
+	if target.Cmp(new(big.Int).Mul(prevTarget, factor)) > 0 ||
+		target.Cmp(new(big.Int).Div(prevTarget, factor)) < 0 {
+		return fmt.Errorf("bits %08x changed from %08x by more than a factor %d", header.Bits, prevBits, params.RetargetAdjustmentFactor)
+	}
+	return nil
+}
+
+// workOfTarget is the expected number of hashes to find a block below the
+// target, as blockchain.CalcWork
+func workOfTarget(target *big.Int) *big.Int {
+	denominator := new(big.Int).Add(target, big.NewInt(1))
+	return new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 256), denominator)
+}
+
+// minWork returns the least work the headers from the given height to the
+// height of the last header can have when they build on the anchor: their
+// target can only grow by the retarget factor on each retarget boundary
+// since the anchor, and never above the limit of the network
+func minWork(anchor *ParsedAnchor, fromHeight, toHeight uint64, params *chaincfg.Params) *big.Int {
+	maxTarget := blockchain.CompactToBig(anchor.Bits)
+	factor := big.NewInt(params.RetargetAdjustmentFactor)
+	work := new(big.Int)
+	for height := anchor.Height + 1; height <= toHeight; height++ {
+		if isRetargetHeight(height, params) {
+			maxTarget.Mul(maxTarget, factor)
+			if maxTarget.Cmp(params.PowLimit) > 0 {
+				maxTarget.Set(params.PowLimit)
+			}
+		}
+		if height >= fromHeight {
+			work.Add(work, workOfTarget(maxTarget))
+		}
+	}
+	return work
+}
+
+// MerkleRootFromBranch computes the merkle root of a block from the hash of
+// one of its transactions, its position and its merkle branch
+func MerkleRootFromBranch(txHash *chainhash.Hash, pos uint32, branch []chainhash.Hash) chainhash.Hash {
+	node := *txHash
+	for _, sibling := range branch {
+		var buf [chainhash.HashSize * 2]byte
+		if pos&1 == 0 {
+			copy(buf[:chainhash.HashSize], node[:])
+			copy(buf[chainhash.HashSize:], sibling[:])
+		} else {
+			copy(buf[:chainhash.HashSize], sibling[:])
+			copy(buf[chainhash.HashSize:], node[:])
+		}
+		node = chainhash.DoubleHashH(buf[:])
+		pos >>= 1
+	}
+	return node
+}
+
+// BuildMerkleBranch returns the merkle branch of the transaction at position
+// pos among the transaction hashes of a block
+func BuildMerkleBranch(txHashes []chainhash.Hash, pos uint32) ([]chainhash.Hash, error) {
+	if int(pos) >= len(txHashes) {
+		return nil, fmt.Errorf("position %d is out of range of %d transactions", pos, len(txHashes))
+	}
+
+	var branch []chainhash.Hash
+	level := append([]chainhash.Hash{}, txHashes...)
+	for len(level) > 1 {
+		// the last hash of a level with an odd number of hashes is paired
+		// with itself
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		branch = append(branch, level[pos^1])
+
+		next := make([]chainhash.Hash, len(level)/2)
+		for i := range next {
+			var buf [chainhash.HashSize * 2]byte
+			copy(buf[:chainhash.HashSize], level[2*i][:])
+			copy(buf[chainhash.HashSize:], level[2*i+1][:])
+			next[i] = chainhash.DoubleHashH(buf[:])
+		}
+		level = next
+		pos >>= 1
+	}
+
+	return branch, nil
+}
+
+// NewProof builds the proof of the transaction at position pos among the
+// transaction hashes of one of the headers, which follow the anchor
+func NewProof(headers []*wire.BlockHeader, txHashes []chainhash.Hash, pos uint32) (*Proof, error) {
+	branch, err := BuildMerkleBranch(txHashes, pos)
+	if err != nil {
+		return nil, err
+	}
+	root := MerkleRootFromBranch(&txHashes[pos], pos, branch)
+	if blockOf(headers, root) < 0 {
+		return nil, fmt.Errorf("no header has the merkle root %s of the transactions", root)
+	}
+
+	proof := &Proof{
+		Headers:      make([]string, len(headers)),
+		MerkleBranch: make([]string, len(branch)),
+		Pos:          pos,
+	}
+	for i, header := range headers {
+		var buf bytes.Buffer
+		if err := header.Serialize(&buf); err != nil {
+			return nil, err
+		}
+		proof.Headers[i] = hex.EncodeToString(buf.Bytes())
+	}
+	for i, hash := range branch {
+		proof.MerkleBranch[i] = hash.String()
+	}
+
+	return proof, nil
+}
+
+// blockOf returns the index of the header with the given merkle root, or -1
+func blockOf(headers []*wire.BlockHeader, merkleRoot chainhash.Hash) int {
+	for i, header := range headers {
+		if header.MerkleRoot == merkleRoot {
+			return i
+		}
+	}
+	return -1
+}
+
+// Verify checks that the headers of the proof form a chain building on the
+// trusted anchor, with valid proof of work and difficulty under the rules of
+// the given network, that the transaction with the given hash is included in
+// one of them, and returns its height and depth.
+//
+// Only the proof of work of the headers is verified. Blocks of a signet are
+// additionally signed by the signet challenge in their coinbase, which cannot
+// be checked from headers alone. Networks allowing blocks of minimum
+// difficulty at any time, such as testnet3, are not supported, as their
+// headers can be mined at no cost.
+func (p *Proof) Verify(txHash *chainhash.Hash, anchor *ParsedAnchor, params *chaincfg.Params) (*VerifiedProof, error) {
+	if params.ReduceMinDifficulty && !params.PoWNoRetargeting {
+		return nil, fmt.Errorf("%s allows blocks of minimum difficulty, its headers cannot be trusted", params.Name)
+	}
+	if len(p.Headers) == 0 {
+		return nil, fmt.Errorf("proof has no headers")
+	}
+	// a branch longer than 32 cannot address the position of the transaction
+	if len(p.MerkleBranch) > 32 {
+		return nil, fmt.Errorf("merkle branch is too long: %d", len(p.MerkleBranch))
+	}
+	if len(p.MerkleBranch) < 32 && p.Pos>>uint(len(p.MerkleBranch)) != 0 {
+		return nil, fmt.Errorf("position %d is out of range of the merkle branch of length %d", p.Pos, len(p.MerkleBranch))
+	}
+
+	headers, err := parseHeaders(p.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := make([]chainhash.Hash, len(p.MerkleBranch))
+	for i, h := range p.MerkleBranch {
+		hash, err := chainhash.NewHashFromStr(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merkle branch hash %d: %w", i, err)
+		}
+		branch[i] = *hash
+	}
+
+	root := MerkleRootFromBranch(txHash, p.Pos, branch)
+	block := blockOf(headers, root)
+	if block < 0 {
+		return nil, fmt.Errorf("tx %s is not included in any header of the proof", txHash)
+	}
+
+	if headers[0].PrevBlock != anchor.Hash {
+		return nil, fmt.Errorf("first header does not build on the anchor %s at height %d", anchor.Hash, anchor.Height)
+	}
+	prevHash, prevBits := anchor.Hash, anchor.Bits
+	work := new(big.Int)
+	for i, header := range headers {
+		height := anchor.Height + 1 + uint64(i)
+		if err := checkProofOfWork(header, params); err != nil {
+			return nil, fmt.Errorf("invalid proof of work of header %d: %w", height, err)
+		}
+		if header.PrevBlock != prevHash {
+			return nil, fmt.Errorf("header %d does not follow header %d", height, height-1)
+		}
+		if err := checkDifficultyTransition(prevBits, header, height, params); err != nil {
+			return nil, fmt.Errorf("invalid difficulty of header %d: %w", height, err)
+		}
+
+		for _, checkpoint := range params.Checkpoints {
+			if uint64(checkpoint.Height) == height && header.BlockHash() != *checkpoint.Hash {
+				return nil, fmt.Errorf("header %d does not match checkpoint %s", height, checkpoint.Hash)
+			}
+		}
+
+		if i >= block {
+			work.Add(work, blockchain.CalcWork(header.Bits))
+		}
+		prevHash, prevBits = header.BlockHash(), header.Bits
+	}
+
+	blockHeight := anchor.Height + 1 + uint64(block)
+	tipHeight := anchor.Height + uint64(len(headers))
+	if expected := minWork(anchor, blockHeight, tipHeight, params); work.Cmp(expected) < 0 {
+		return nil, fmt.Errorf("work %s of the confirmations is below the least work %s expected from the anchor", work, expected)
+	}
+
+	return &VerifiedProof{
+		BlockHash:     headers[block].BlockHash(),
+		BlockHeight:   blockHeight,
+		Confirmations: uint64(len(headers) - block),
+		Work:          work,
+	}, nil
+}
@@ -0,0 +1,367 @@
+package spv_test
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/networks/parameters/spv"
+)
+
+func addRandomSeedsToFuzzer(f *testing.F, num uint) {
+	// Seed based on the current time
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	var idx uint
+	for idx = 0; idx < num; idx++ {
+		f.Add(r.Int63())
+	}
+}
+
+func FuzzMerkleBranch(f *testing.F) {
+	addRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		txs := genRandomTxs(r, 1+r.Intn(50))
+		txHashes := txHashes(txs)
+		root := blockchain.CalcMerkleRoot(txs, false)
+
+		for pos := range txHashes {
+			branch, err := spv.BuildMerkleBranch(txHashes, uint32(pos))
+			require.NoError(t, err)
+			require.Equal(t, root, spv.MerkleRootFromBranch(&txHashes[pos], uint32(pos), branch))
+		}
+	})
+}
+
+func TestVerifyProof(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	params := &chaincfg.RegressionNetParams
+	anchor := testAnchor(params, 99)
+	txs := genRandomTxs(r, 7)
+	headers := mineHeaders(t, params, anchor, txs, 6)
+
+	for pos, tx := range txs {
+		proof, err := spv.NewProof(headers, txHashes(txs), uint32(pos))
+		require.NoError(t, err)
+
+		verified, err := proof.Verify(tx.Hash(), anchor, params)
+		require.NoError(t, err)
+		require.Equal(t, headers[0].BlockHash(), verified.BlockHash)
+		require.Equal(t, uint64(100), verified.BlockHeight)
+		require.Equal(t, uint64(6), verified.Confirmations)
+		require.Equal(t, int64(12), verified.Work.Int64())
+	}
+
+	// a proof of the block alone is a single confirmation
+	proof, err := spv.NewProof(headers[:1], txHashes(txs), 3)
+	require.NoError(t, err)
+	verified, err := proof.Verify(txs[3].Hash(), anchor, params)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), verified.Confirmations)
+
+	// the block may come after other headers following the anchor, its
+	// height is counted from the anchor
+	empty := genRandomTxs(r, 1)
+	headers = append(mineHeaders(t, params, anchor, empty, 2), headers...)
+	headers[2] = mineHeaders(t, params, &spv.ParsedAnchor{Hash: headers[1].BlockHash(), Bits: anchor.Bits}, txs, 1)[0]
+	proof, err = spv.NewProof(headers[:3], txHashes(txs), 3)
+	require.NoError(t, err)
+	verified, err = proof.Verify(txs[3].Hash(), anchor, params)
+	require.NoError(t, err)
+	require.Equal(t, headers[2].BlockHash(), verified.BlockHash)
+	require.Equal(t, uint64(102), verified.BlockHeight)
+	require.Equal(t, uint64(1), verified.Confirmations)
+
+	_, err = spv.NewProof(headers[:2], txHashes(txs), 3)
+	require.Contains(t, err.Error(), "no header has the merkle root")
+}
+
+func TestVerifyMainnetProof(t *testing.T) {
+	params := &chaincfg.MainNetParams
+
+	// block 1 of mainnet, whose only transaction is its coinbase
+	merkleRoot, err := chainhash.NewHashFromStr("0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098")
+	require.NoError(t, err)
+	header := wire.NewBlockHeader(1, params.GenesisHash, merkleRoot, 0x1d00ffff, 2573394689)
+	header.Timestamp = time.Unix(1231469665, 0)
+	require.Equal(t, "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048", header.BlockHash().String())
+
+	proof, err := spv.NewProof([]*wire.BlockHeader{header}, []chainhash.Hash{*merkleRoot}, 0)
+	require.NoError(t, err)
+	verified, err := proof.Verify(merkleRoot, spv.GenesisAnchor(params), params)
+	require.NoError(t, err)
+	require.Equal(t, header.BlockHash(), verified.BlockHash)
+	require.Equal(t, uint64(1), verified.BlockHeight)
+	require.Equal(t, uint64(1), verified.Confirmations)
+
+	// proof of work alone does not tell networks apart, the mainnet header
+	// is also valid under the lower signet limit, but it does not build on
+	// the signet anchor
+	_, err = proof.Verify(merkleRoot, spv.GenesisAnchor(&chaincfg.SigNetParams), &chaincfg.SigNetParams)
+	require.EqualError(t, err, "first header does not build on the anchor "+
+		chaincfg.SigNetParams.GenesisHash.String()+" at height 0")
+}
+
+func TestFailProofVerification(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	params := &chaincfg.RegressionNetParams
+	anchor := testAnchor(params, 9)
+	txs := genRandomTxs(r, 5)
+	headers := mineHeaders(t, params, anchor, txs, 3)
+
+	newProof := func() *spv.Proof {
+		proof, err := spv.NewProof(headers, txHashes(txs), 2)
+		require.NoError(t, err)
+		return proof
+	}
+
+	_, err := newProof().Verify(txs[1].Hash(), anchor, params)
+	require.EqualError(t, err, "tx "+txs[1].Hash().String()+" is not included in any header of the proof")
+
+	proof := newProof()
+	proof.Pos = 3
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.Contains(t, err.Error(), "is not included in any header of the proof")
+
+	proof = newProof()
+	proof.Pos = 8
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.EqualError(t, err, "position 8 is out of range of the merkle branch of length 3")
+
+	proof = newProof()
+	proof.Headers = nil
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.EqualError(t, err, "proof has no headers")
+
+	proof = newProof()
+	proof.Headers[1] = proof.Headers[1][:10]
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.EqualError(t, err, "invalid header 1: expected 80 bytes, got 5")
+
+	proof = newProof()
+	proof.MerkleBranch[0] = "zz"
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.Contains(t, err.Error(), "invalid merkle branch hash 0")
+
+	// headers out of order
+	proof = newProof()
+	proof.Headers[1], proof.Headers[2] = proof.Headers[2], proof.Headers[1]
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.EqualError(t, err, "header 11 does not follow header 10")
+
+	// headers mined on another block than the anchor, such as headers forged
+	// from a made up chain
+	other := *anchor
+	other.Hash = chainhash.Hash{0xbb}
+	_, err = newProof().Verify(txs[2].Hash(), &other, params)
+	require.EqualError(t, err, "first header does not build on the anchor "+other.Hash.String()+" at height 9")
+
+	// a header without proof of work
+	unmined := *headers[2]
+	for {
+		hash := unmined.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(blockchain.CompactToBig(unmined.Bits)) > 0 {
+			break
+		}
+		unmined.Nonce++
+	}
+	proof, err = spv.NewProof([]*wire.BlockHeader{headers[0], headers[1], &unmined}, txHashes(txs), 2)
+	require.NoError(t, err)
+	_, err = proof.Verify(txs[2].Hash(), anchor, params)
+	require.Contains(t, err.Error(), "invalid proof of work of header 12: hash "+unmined.BlockHash().String()+" is higher than target")
+
+	// regtest difficulty is too low for signet and mainnet
+	_, err = newProof().Verify(txs[2].Hash(), anchor, &chaincfg.SigNetParams)
+	require.Contains(t, err.Error(), "invalid proof of work of header 10: target")
+	require.Contains(t, err.Error(), "is higher than the signet limit")
+	_, err = newProof().Verify(txs[2].Hash(), anchor, &chaincfg.MainNetParams)
+	require.Contains(t, err.Error(), "is higher than the mainnet limit")
+
+	// testnet3 allows blocks of minimum difficulty at any time
+	_, err = newProof().Verify(txs[2].Hash(), anchor, &chaincfg.TestNet3Params)
+	require.EqualError(t, err, "testnet3 allows blocks of minimum difficulty, its headers cannot be trusted")
+}
+
+func TestFailDifficultyTransition(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	// regtest with the difficulty rules of mainnet and a retarget period of
+	// 4 blocks
+	params := chaincfg.RegressionNetParams
+	params.ReduceMinDifficulty = false
+	params.PoWNoRetargeting = false
+	params.TargetTimespan = 4 * params.TargetTimePerBlock
+	params.Checkpoints = nil
+
+	txs := genRandomTxs(r, 1)
+	anchor := testAnchor(&params, 5)
+	headers := mineHeaders(t, &params, anchor, txs, 3)
+	// the third header uses a target 2 times lower
+	harder := *headers[2]
+	harder.Bits = blockchain.BigToCompact(
+		blockchain.CompactToBig(harder.Bits).Rsh(blockchain.CompactToBig(harder.Bits), 1))
+	mine(&harder)
+	headers[2] = &harder
+
+	// the bits change on a retarget boundary
+	proof, err := spv.NewProof(headers, txHashes(txs), 0)
+	require.NoError(t, err)
+	_, err = proof.Verify(txs[0].Hash(), anchor, &params)
+	require.NoError(t, err)
+
+	// the bits change within a retarget period
+	anchor.Height = 4
+	_, err = proof.Verify(txs[0].Hash(), anchor, &params)
+	require.Contains(t, err.Error(), "invalid difficulty of header 7: bits")
+	require.Contains(t, err.Error(), "outside of a retarget boundary")
+
+	// the first header cannot be easier than the anchor outside of a
+	// retarget boundary
+	harderAnchor := *anchor
+	harderAnchor.Bits = harder.Bits
+	_, err = proof.Verify(txs[0].Hash(), &harderAnchor, &params)
+	require.Contains(t, err.Error(), "invalid difficulty of header 5: bits")
+
+	// the target cannot be more than 4 times lower on a retarget boundary
+	anchor.Height = 6
+	headers = mineHeaders(t, &params, anchor, txs, 2)
+	much := *headers[1]
+	much.Bits = blockchain.BigToCompact(
+		blockchain.CompactToBig(much.Bits).Rsh(blockchain.CompactToBig(much.Bits), 3))
+	mine(&much)
+	proof, err = spv.NewProof([]*wire.BlockHeader{headers[0], &much}, txHashes(txs), 0)
+	require.NoError(t, err)
+	_, err = proof.Verify(txs[0].Hash(), anchor, &params)
+	require.Contains(t, err.Error(), "by more than a factor 4")
+
+	// on a network without retargeting, the bits are the ones of the anchor
+	regtest := &chaincfg.RegressionNetParams
+	easier := testAnchor(regtest, 10)
+	easier.Bits = blockchain.BigToCompact(new(big.Int).Rsh(regtest.PowLimit, 1))
+	headers = mineHeaders(t, regtest, testAnchor(regtest, 10), txs, 1)
+	proof, err = spv.NewProof(headers, txHashes(txs), 0)
+	require.NoError(t, err)
+	_, err = proof.Verify(txs[0].Hash(), easier, regtest)
+	require.Contains(t, err.Error(), "invalid difficulty of header 11: bits")
+}
+
+func TestFailCheckpoint(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	params := chaincfg.RegressionNetParams
+	anchor := testAnchor(&params, 19)
+	txs := genRandomTxs(r, 2)
+	headers := mineHeaders(t, &params, anchor, txs, 2)
+
+	params.Checkpoints = []chaincfg.Checkpoint{{Height: 21, Hash: &chainhash.Hash{1}}}
+	proof, err := spv.NewProof(headers, txHashes(txs), 1)
+	require.NoError(t, err)
+	_, err = proof.Verify(txs[1].Hash(), anchor, &params)
+	require.EqualError(t, err, "header 21 does not match checkpoint "+chainhash.Hash{1}.String())
+}
+
+func TestParseAnchor(t *testing.T) {
+	params := &chaincfg.SigNetParams
+	anchor, err := spv.ParseAnchor(&spv.Anchor{
+		Height: 0,
+		Hash:   params.GenesisHash.String(),
+		Bits:   "1e0377ae",
+	}, params)
+	require.NoError(t, err)
+	require.Equal(t, spv.GenesisAnchor(params), anchor)
+
+	tests := []struct {
+		anchor spv.Anchor
+		err    string
+	}{
+		{spv.Anchor{Hash: "00", Bits: "1e0377ae"}, `invalid anchor hash "00"`},
+		{spv.Anchor{Hash: params.GenesisHash.String(), Bits: "1e0377"}, `invalid anchor bits "1e0377", should be 4 bytes in hex`},
+		{spv.Anchor{Hash: params.GenesisHash.String(), Bits: "207fffff"}, "anchor bits 207fffff are not a valid signet target"},
+	}
+	for _, tt := range tests {
+		_, err := spv.ParseAnchor(&tt.anchor, params)
+		require.EqualError(t, err, tt.err)
+	}
+}
+
+func TestNetParamsByName(t *testing.T) {
+	for _, params := range []*chaincfg.Params{&chaincfg.MainNetParams, &chaincfg.SigNetParams} {
+		got, err := spv.NetParamsByName(params.Name)
+		require.NoError(t, err)
+		require.Equal(t, params, got)
+	}
+
+	_, err := spv.NetParamsByName("simnet")
+	require.EqualError(t, err, `unsupported network "simnet"`)
+}
+
+func genRandomTxs(r *rand.Rand, num int) []*btcutil.Tx {
+	txs := make([]*btcutil.Tx, num)
+	for i := range txs {
+		tx := wire.NewMsgTx(2)
+		var prevHash chainhash.Hash
+		r.Read(prevHash[:])
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, r.Uint32()), nil, nil))
+		tx.AddTxOut(wire.NewTxOut(r.Int63n(btcutil.MaxSatoshi), []byte{0x51}))
+		txs[i] = btcutil.NewTx(tx)
+	}
+	return txs
+}
+
+func txHashes(txs []*btcutil.Tx) []chainhash.Hash {
+	hashes := make([]chainhash.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = *tx.Hash()
+	}
+	return hashes
+}
+
+// mine increments the nonce of the header until its hash is below its target
+func mine(header *wire.BlockHeader) {
+	target := blockchain.CompactToBig(header.Bits)
+	for {
+		hash := header.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			return
+		}
+		header.Nonce++
+	}
+}
+
+// testAnchor returns an anchor at the given height at the minimum difficulty
+// of the network
+func testAnchor(params *chaincfg.Params, height uint64) *spv.ParsedAnchor {
+	return &spv.ParsedAnchor{
+		Height: height,
+		Hash:   chainhash.Hash{0xaa},
+		Bits:   blockchain.BigToCompact(params.PowLimit),
+	}
+}
+
+// mineHeaders mines on the anchor a block including the given transactions
+// followed by num-1 empty blocks at the difficulty of the anchor
+func mineHeaders(t *testing.T, params *chaincfg.Params, anchor *spv.ParsedAnchor, txs []*btcutil.Tx, num int) []*wire.BlockHeader {
+	require.Greater(t, num, 0)
+	timestamp := time.Unix(1700000000, 0)
+
+	headers := make([]*wire.BlockHeader, num)
+	prevHash := anchor.Hash
+	for i := range headers {
+		merkleRoot := chainhash.Hash{byte(i)}
+		if i == 0 {
+			merkleRoot = blockchain.CalcMerkleRoot(txs, false)
+		}
+		header := wire.NewBlockHeader(4, &prevHash, &merkleRoot, anchor.Bits, 0)
+		header.Timestamp = timestamp.Add(time.Duration(i) * params.TargetTimePerBlock)
+		mine(header)
+		headers[i] = header
+		prevHash = header.BlockHash()
+	}
+	return headers
+}